@@ -0,0 +1,195 @@
+/*
+Package analysis implements static analyses of ARC programs. It operates on the
+AST of an ARC program and therefore relies on the parser.
+*/
+package analysis
+
+import (
+	"sort"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Liveness computes which registers are live at every instruction of the
+// program. A register is live at an instruction if it is read by the
+// instruction or by a later one before being overwritten. The registers are
+// given by name, like "%r1", and sorted. %r0 always reads as zero and is never
+// live.
+//
+// The analysis is a backward dataflow over an approximate control flow graph.
+// Branches continue at their target and, if conditional, at the next
+// instruction. Calls continue at the subroutine and at the next instruction,
+// the instruction a subroutine returns to. A jmpl ends the control flow.
+// Statements which aren't instructions, like comments, directives and data, are
+// not part of the result.
+func Liveness(prog *ast.Program) map[ast.Statement][]string {
+	// Collect the instructions and the index of every label referencing one.
+	var insts []ast.Statement
+	labels := make(map[string]int)
+	for _, stmt := range prog.Statements {
+		if instruction(stmt) == nil {
+			continue
+		}
+		if label, valid := stmt.(*ast.LabelStatement); valid {
+			labels[label.Ident.Name] = len(insts)
+		}
+		insts = append(insts, stmt)
+	}
+
+	// Build the control flow graph and the registers every instruction reads
+	// and writes.
+	succs := make([][]int, len(insts))
+	uses := make([]set, len(insts))
+	defs := make([]set, len(insts))
+	for i, stmt := range insts {
+		inst := instruction(stmt)
+		uses[i], defs[i] = useDef(inst)
+		target, next := successors(inst)
+		if target != nil {
+			if j, known := labels[target.Name]; known {
+				succs[i] = append(succs[i], j)
+			}
+		}
+		if next && i+1 < len(insts) {
+			succs[i] = append(succs[i], i+1)
+		}
+	}
+
+	// Iterate backwards until the live registers don't change anymore.
+	live := make([]set, len(insts))
+	for i := range live {
+		live[i] = make(set)
+	}
+	for changed := true; changed; {
+		changed = false
+		for i := len(insts) - 1; i >= 0; i-- {
+			in := make(set)
+			for _, j := range succs[i] {
+				for reg := range live[j] {
+					if !defs[i][reg] {
+						in[reg] = true
+					}
+				}
+			}
+			for reg := range uses[i] {
+				in[reg] = true
+			}
+			if len(in) != len(live[i]) {
+				live[i] = in
+				changed = true
+			}
+		}
+	}
+
+	res := make(map[ast.Statement][]string, len(insts))
+	for i, stmt := range insts {
+		res[stmt] = live[i].sorted()
+	}
+	return res
+}
+
+// set is a set of register names.
+type set map[string]bool
+
+// add adds the registers to the set. %r0 is skipped as it always reads as zero,
+// %pc as it always holds the address of the instruction.
+func (s set) add(regs ...*ast.Register) {
+	for _, reg := range regs {
+		if reg != nil && reg.Name != "%r0" && reg.Name != "%pc" {
+			s[reg.Name] = true
+		}
+	}
+}
+
+// sorted returns the registers of the set in sorted order.
+func (s set) sorted() []string {
+	res := make([]string, 0, len(s))
+	for reg := range s {
+		res = append(res, reg)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// instruction returns the instruction of a statement. Labels are resolved to
+// the statement they reference and pseudo instructions are lowered. Nil is
+// returned for statements which aren't instructions.
+func instruction(stmt ast.Statement) ast.Statement {
+	switch s := stmt.(type) {
+	case *ast.CommentStatement, *ast.BeginStatement, *ast.EndStatement, *ast.OrgStatement, *ast.GlobalStatement, *ast.ExternStatement, *ast.WordStatement, *ast.EquStatement, *ast.AssertStatement:
+		return nil
+	case *ast.LabelStatement:
+		if ref, valid := s.Reference.(ast.Statement); valid {
+			return instruction(ref)
+		}
+		return nil
+	}
+	return ast.LowerStatement(stmt)
+}
+
+// useDef returns the registers an instruction reads and writes.
+func useDef(stmt ast.Statement) (set, set) {
+	use, def := make(set), make(set)
+	switch s := stmt.(type) {
+	case *ast.LoadStatement:
+		use.add(base(s.Source))
+		def.add(s.Destination)
+	case *ast.StoreStatement:
+		use.add(s.Source, base(s.Destination))
+	case *ast.CallStatement:
+		def.add(&ast.Register{Name: "%r15"})
+	case *ast.JumpAndLinkStatement:
+		use.add(base(s.ReturnAddress))
+		def.add(s.FromAddress)
+	default:
+		// Arithmetic and logic instructions read their source register and
+		// operand and write their destination.
+		ops := ast.Operands(stmt)
+		if len(ops) != 3 {
+			break
+		}
+		for _, op := range ops[:2] {
+			if reg, valid := op.(*ast.Register); valid {
+				use.add(reg)
+			}
+		}
+		if reg, valid := ops[2].(*ast.Register); valid {
+			def.add(reg)
+		}
+	}
+	return use, def
+}
+
+// base returns the register a memory location is computed from, if any.
+func base(memLoc ast.MemoryLocation) *ast.Register {
+	switch loc := memLoc.(type) {
+	case *ast.Register:
+		return loc
+	case *ast.Expression:
+		reg, _ := loc.Base.(*ast.Register)
+		return reg
+	}
+	return nil
+}
+
+// successors returns the label an instruction transfers control to, if any,
+// and whether the control flow may continue at the next instruction.
+func successors(stmt ast.Statement) (*ast.Identifier, bool) {
+	switch s := stmt.(type) {
+	case *ast.BAStatement:
+		return s.Target, false
+	case *ast.BEStatement:
+		return s.Target, true
+	case *ast.BNEStatement:
+		return s.Target, true
+	case *ast.BNEGStatement:
+		return s.Target, true
+	case *ast.BPOSStatement:
+		return s.Target, true
+	case *ast.CallStatement:
+		return s.Target, true
+	case *ast.JumpAndLinkStatement:
+		return nil, false
+	}
+	return nil, true
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lukasmalkmus/arc/parser"
+)
+
+// TestLiveness validates the registers live at every instruction.
+func TestLiveness(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		live [][]string
+	}{
+		{
+			// %r1 becomes dead after its last use, %r3 is overwritten before
+			// it is read.
+			name: "straight",
+			src:  ".begin\nld [x], %r1\nadd %r1, %r2, %r3\nadd %r3, 1, %r3\nst %r3, [x]\nx: 0\n.end",
+			live: [][]string{{"%r2"}, {"%r1", "%r2"}, {"%r3"}, {"%r3"}},
+		},
+		{
+			// The counter and the sum stay live throughout the loop.
+			name: "loop",
+			src:  "loop: add %r2, %r1, %r2\nsubcc %r1, 1, %r1\nbne loop\nst %r2, [%r0+4]",
+			live: [][]string{{"%r1", "%r2"}, {"%r1", "%r2"}, {"%r1", "%r2"}, {"%r2"}},
+		},
+		{
+			// The subroutine reads %r1 and the return address in %r15, which
+			// is written by the call. The instruction after the call is
+			// approximated as successor of the call, so %r2 is live at the
+			// call although the subroutine overwrites it.
+			name: "call",
+			src:  "call fn\nst %r2, %r3\nba done\nfn: add %r1, 1, %r2\njmpl %r15+4, %r0\ndone: add %r0, %r0, %r0",
+			live: [][]string{{"%r1", "%r2", "%r3"}, {"%r2", "%r3"}, nil, {"%r1", "%r15"}, {"%r15"}, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := parser.Parse(tt.src)
+			ok(t, err)
+			res := Liveness(prog)
+
+			var live [][]string
+			for _, stmt := range prog.Statements {
+				if regs, prs := res[stmt]; prs {
+					if len(regs) == 0 {
+						regs = nil
+					}
+					live = append(live, regs)
+				}
+			}
+			equals(t, live, tt.live)
+		})
+	}
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/lukasmalkmus/arc/token"
 )
@@ -26,6 +27,11 @@ func (*CommentStatement) stmt()     {}
 func (*BeginStatement) stmt()       {}
 func (*EndStatement) stmt()         {}
 func (*OrgStatement) stmt()         {}
+func (*GlobalStatement) stmt()      {}
+func (*ExternStatement) stmt()      {}
+func (*WordStatement) stmt()        {}
+func (*EquStatement) stmt()         {}
+func (*AssertStatement) stmt()      {}
 func (*LabelStatement) stmt()       {}
 func (*LoadStatement) stmt()        {}
 func (*StoreStatement) stmt()       {}
@@ -50,6 +56,11 @@ func (*BPOSStatement) stmt()        {}
 func (*BAStatement) stmt()          {}
 func (*CallStatement) stmt()        {}
 func (*JumpAndLinkStatement) stmt() {}
+func (*NopStatement) stmt()         {}
+func (*CmpStatement) stmt()         {}
+func (*RetStatement) stmt()         {}
+func (*MovStatement) stmt()         {}
+func (*ClrStatement) stmt()         {}
 
 // Reference is implemented by types which can be referenced by a label. These
 // are statements and identifiers.
@@ -57,10 +68,13 @@ type Reference interface {
 	// ref is unexported to ensure implementations of Reference can only
 	// originate in this package.
 	ref()
+	Pos() token.Pos
 	String() string
 }
 
 func (*Integer) ref()        {}
+func (*OrgStatement) ref()   {}
+func (*WordStatement) ref()  {}
 func (*LoadStatement) ref()  {}
 func (*StoreStatement) ref() {}
 func (*AddStatement) ref()   {}
@@ -77,6 +91,15 @@ func (*XorStatement) ref()   {}
 func (*XorCCStatement) ref() {}
 func (*SLLStatement) ref()   {}
 func (*SRAStatement) ref()   {}
+func (*BEStatement) ref()    {}
+func (*BNEStatement) ref()   {}
+func (*BNEGStatement) ref()  {}
+func (*BPOSStatement) ref()  {}
+func (*BAStatement) ref()    {}
+func (*NopStatement) ref()   {}
+func (*CmpStatement) ref()   {}
+func (*MovStatement) ref()   {}
+func (*ClrStatement) ref()   {}
 
 // MemoryLocation is implemented by types which can be addressed as locations in
 // memory. Expressions can be addressed as well as registers.
@@ -84,6 +107,7 @@ type MemoryLocation interface {
 	// memLoc is unexported to ensure implementations of Memory can only
 	// originate in this package.
 	memLoc()
+	Pos() token.Pos
 	String() string
 }
 
@@ -96,6 +120,7 @@ type ExpressionBase interface {
 	// epb is unexported to ensure implementations of Reference can only
 	// originate in this package.
 	epb()
+	Pos() token.Pos
 	String() string
 }
 
@@ -103,16 +128,25 @@ func (*Identifier) epb() {}
 func (*Register) epb()   {}
 
 // Operand is implemented by types which can be used as operands in Arithmetic
-// operations.
+// operations. Identifiers used as operands refer to constants.
 type Operand interface {
 	// op is unexported to ensure implementations of Reference can only
 	// originate in this package.
 	op()
+	Pos() token.Pos
 	String() string
 }
 
-func (*Integer) op()  {}
-func (*Register) op() {}
+func (*Integer) op()    {}
+func (*Register) op()   {}
+func (*Identifier) op() {}
+
+// Node is implemented by the operands of statements. These are registers,
+// integers, expressions and identifiers.
+type Node interface {
+	Pos() token.Pos
+	String() string
+}
 
 // Statements is a list of statements.
 type Statements []Statement
@@ -144,6 +178,54 @@ func (p *Program) AddStatement(stmts ...Statement) {
 	}
 }
 
+// Comments returns all comments of the program in the order they appear in the
+// source. This includes comments trailing a statement on the same line.
+func (p Program) Comments() []*CommentStatement {
+	var comments []*CommentStatement
+	for _, stmt := range p.Statements {
+		if comment, valid := stmt.(*CommentStatement); valid {
+			comments = append(comments, comment)
+		}
+	}
+	return comments
+}
+
+// Constants returns the values of the constants defined by the program.
+func (p Program) Constants() map[string]int32 {
+	consts := make(map[string]int32)
+	for _, stmt := range p.Statements {
+		if equ, valid := stmt.(*EquStatement); valid {
+			consts[equ.Name.Name] = equ.Value.Value
+		}
+	}
+	return consts
+}
+
+// Doc returns the comment block documenting a statement of the program. These
+// are the comments on the lines directly above the statement, without any
+// blank line in between. Trailing comments don't document the statement
+// following them. Nil is returned if the statement isn't documented or isn't
+// part of the program.
+func (p Program) Doc(stmt Statement) []*CommentStatement {
+	var doc []*CommentStatement
+	for i, s := range p.Statements {
+		if s != stmt {
+			continue
+		}
+		line := stmt.Pos().Line
+		for j := i - 1; j >= 0; j-- {
+			comment, valid := p.Statements[j].(*CommentStatement)
+			if !valid || comment.Statement != nil || comment.Pos().Line != line-1 {
+				break
+			}
+			doc = append([]*CommentStatement{comment}, doc...)
+			line--
+		}
+		break
+	}
+	return doc
+}
+
 // Format describes the instruction format of a statement/instruction.
 type Format int
 
@@ -180,6 +262,19 @@ type CommentStatement struct {
 
 	// Text is the actual text of the comment.
 	Text string
+	// Statement is the statement the comment trails on the same line. It is
+	// nil if the comment is on a line of its own.
+	Statement Statement
+	// BlankLines is the number of blank lines between the comment and the
+	// statement before it or the start of the source. It is zero for trailing
+	// comments.
+	BlankLines int
+}
+
+// Standalone reports whether the comment is on a line of its own instead of
+// trailing a statement.
+func (stmt CommentStatement) Standalone() bool {
+	return stmt.Statement == nil
 }
 
 // Pos returns the statements position.
@@ -192,8 +287,15 @@ func (stmt CommentStatement) Tok() token.Token {
 	return stmt.Token
 }
 
+// String returns the comment with trailing whitespace removed. The text after
+// the leading "!" is preserved, so are alignment and banners like "! --- !". A
+// single space is only inserted if the text directly follows the "!".
 func (stmt CommentStatement) String() string {
-	return "! " + strings.TrimSpace(stmt.Text[1:])
+	text := strings.TrimRightFunc(strings.TrimPrefix(stmt.Text, "!"), unicode.IsSpace)
+	if text == "" || strings.HasPrefix(text, "!") || unicode.IsSpace(rune(text[0])) {
+		return "!" + text
+	}
+	return "! " + text
 }
 
 // BeginStatement marks the beginning of an ARC program.
@@ -202,6 +304,10 @@ type BeginStatement struct {
 	Token token.Token
 	// Position is the position in the source.
 	Position token.Pos
+
+	// EndPos is the position of the matching .end directive. It is set by
+	// the parser once the whole program is parsed.
+	EndPos token.Pos
 }
 
 // Tok returns the statements lexical token.
@@ -268,6 +374,170 @@ func (stmt OrgStatement) String() string {
 	return buf.String()
 }
 
+// GlobalStatement exports a label, making it visible to other programs.
+type GlobalStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Ident is the identifier of the exported label.
+	Ident *Identifier
+}
+
+// Pos returns the statements position.
+func (stmt GlobalStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt GlobalStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt GlobalStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(".global ")
+	buf.WriteString(stmt.Ident.String())
+	return buf.String()
+}
+
+// ExternStatement imports a label which is exported by another program.
+type ExternStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Ident is the identifier of the imported label.
+	Ident *Identifier
+}
+
+// Pos returns the statements position.
+func (stmt ExternStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt ExternStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt ExternStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(".extern ")
+	buf.WriteString(stmt.Ident.String())
+	return buf.String()
+}
+
+// WordStatement emits one or more words of data at the current location.
+type WordStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Values are the emitted words in the order they are placed in memory.
+	Values []*Integer
+}
+
+// Pos returns the statements position.
+func (stmt WordStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt WordStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt WordStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(".word ")
+	for i, val := range stmt.Values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(val.String())
+	}
+	return buf.String()
+}
+
+// EquStatement defines a constant. Constants don't occupy memory and can be used
+// as immediate operands by their name.
+type EquStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Name is the identifier of the constant.
+	Name *Identifier
+	// Value is the value of the constant.
+	Value *Integer
+}
+
+// Pos returns the statements position.
+func (stmt EquStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt EquStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt EquStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(".equ ")
+	buf.WriteString(stmt.Name.String())
+	buf.WriteString(", ")
+	buf.WriteString(stmt.Value.String())
+	return buf.String()
+}
+
+// AssertStatement checks a condition when the program is assembled. The
+// condition either compares two constant expressions or, without comparison,
+// holds if its expression isn't zero.
+type AssertStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Left is the left-hand side of the condition.
+	Left *ConstantExpression
+	// Comparison is the comparison operator, like ">=". It is empty if the
+	// condition is a single expression.
+	Comparison string
+	// Right is the right-hand side of the comparison. It is nil if the
+	// condition is a single expression.
+	Right *ConstantExpression
+}
+
+// Pos returns the statements position.
+func (stmt AssertStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt AssertStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt AssertStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(".assert ")
+	buf.WriteString(stmt.Left.String())
+	if stmt.Comparison != "" && stmt.Right != nil {
+		buf.WriteString(" ")
+		buf.WriteString(stmt.Comparison)
+		buf.WriteString(" ")
+		buf.WriteString(stmt.Right.String())
+	}
+	return buf.String()
+}
+
 // LabelStatement represents a label.
 type LabelStatement struct {
 	// Token is the statements lexical token.
@@ -278,6 +548,8 @@ type LabelStatement struct {
 	// Ident is the labels identifier.
 	Ident *Identifier
 	// Reference is an Identifier, Integer or the Statement the label addresses.
+	// A label referencing an .org directive names the start of a section and
+	// addresses the value of the directive.
 	Reference Reference
 }
 
@@ -891,7 +1163,7 @@ func (stmt SLLStatement) Tok() token.Token {
 
 func (stmt SLLStatement) String() string {
 	var buf bytes.Buffer
-	buf.WriteString("orn ")
+	buf.WriteString("sll ")
 	buf.WriteString(stmt.Source.String())
 	buf.WriteString(", ")
 	buf.WriteString(stmt.Operand.String())
@@ -932,7 +1204,7 @@ func (stmt SRAStatement) Tok() token.Token {
 
 func (stmt SRAStatement) String() string {
 	var buf bytes.Buffer
-	buf.WriteString("orn ")
+	buf.WriteString("sra ")
 	buf.WriteString(stmt.Source.String())
 	buf.WriteString(", ")
 	buf.WriteString(stmt.Operand.String())
@@ -1175,7 +1447,147 @@ func (stmt JumpAndLinkStatement) String() string {
 
 // InstructionFormat returns the instruction format of the statement. It
 // implements the InstructionFormat interface to enable assembling.
-func (JumpAndLinkStatement) InstructionFormat() Format { return Call }
+func (JumpAndLinkStatement) InstructionFormat() Format { return Arithmetic }
+
+// NopStatement represents a pseudo instruction which does nothing (nop). It is
+// lowered to an instruction without effect.
+type NopStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+}
+
+// Pos returns the statements position.
+func (stmt NopStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt NopStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt NopStatement) String() string {
+	return "nop"
+}
+
+// CmpStatement represents a pseudo instruction which compares a register with
+// an operand (cmp). It is lowered to a subcc discarding the result, so only the
+// condition codes are set.
+type CmpStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Source is a register acting as first operand.
+	Source *Register
+	// Operand is the second one of the two operands.
+	Operand Operand
+}
+
+// Pos returns the statements position.
+func (stmt CmpStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt CmpStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt CmpStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("cmp ")
+	buf.WriteString(stmt.Source.String())
+	buf.WriteString(", ")
+	buf.WriteString(stmt.Operand.String())
+	return buf.String()
+}
+
+// RetStatement represents a pseudo instruction which returns from a subroutine
+// (ret or retl). It is lowered to a jmpl to the instruction after the call,
+// whose address the call stored in %r15.
+type RetStatement struct {
+	// Token is the statements lexical token, either ret or retl.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+}
+
+// Pos returns the statements position.
+func (stmt RetStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt RetStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt RetStatement) String() string {
+	return stmt.Token.String()
+}
+
+// MovStatement represents a pseudo instruction which copies a register or an
+// immediate into a register (mov). It is lowered to an or with %r0.
+type MovStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Operand is the register or immediate which is copied.
+	Operand Operand
+	// Destination is the target register receiving the copy.
+	Destination *Register
+}
+
+// Pos returns the statements position.
+func (stmt MovStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt MovStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt MovStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("mov ")
+	buf.WriteString(stmt.Operand.String())
+	buf.WriteString(", ")
+	buf.WriteString(stmt.Destination.String())
+	return buf.String()
+}
+
+// ClrStatement represents a pseudo instruction which sets a register to zero
+// (clr). It is lowered to an or of %r0 with itself.
+type ClrStatement struct {
+	// Token is the statements lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Destination is the register which is cleared.
+	Destination *Register
+}
+
+// Pos returns the statements position.
+func (stmt ClrStatement) Pos() token.Pos {
+	return stmt.Position
+}
+
+// Tok returns the statements lexical token.
+func (stmt ClrStatement) Tok() token.Token {
+	return stmt.Token
+}
+
+func (stmt ClrStatement) String() string {
+	return "clr " + stmt.Destination.String()
+}
 
 // Expression is an expression which bundles an identifier with an offset. In
 // ARC an expression is delimited by an opening and a closing square bracket.
@@ -1200,7 +1612,8 @@ func (e Expression) String() string {
 	var buf bytes.Buffer
 	buf.WriteString("[")
 	buf.WriteString(e.Base.String())
-	if e.Operator != "" {
+	// Don't panic on malformed expressions built outside of the parser.
+	if e.Operator != "" && e.Offset != nil {
 		buf.WriteString(e.Operator)
 		buf.WriteString(strconv.FormatInt(int64(e.Offset.Value), 10))
 	}
@@ -1208,6 +1621,38 @@ func (e Expression) String() string {
 	return buf.String()
 }
 
+// ConstantExpression is a sum of integers, constants and labels. Its value is
+// computed when the program is assembled, labels evaluate to their address.
+type ConstantExpression struct {
+	// Position is the position in the source.
+	Position token.Pos
+
+	// Operands are the integers and the identifiers of constants and labels
+	// summed up.
+	Operands []Operand
+	// Operators are the operators, "+" or "-", which combine the operands.
+	// The first operator combines the first and the second operand.
+	Operators []string
+}
+
+// Pos returns the expressions position.
+func (e ConstantExpression) Pos() token.Pos {
+	return e.Position
+}
+
+func (e ConstantExpression) String() string {
+	var buf bytes.Buffer
+	for i, op := range e.Operands {
+		if i > 0 && i <= len(e.Operators) {
+			buf.WriteString(" ")
+			buf.WriteString(e.Operators[i-1])
+			buf.WriteString(" ")
+		}
+		buf.WriteString(op.String())
+	}
+	return buf.String()
+}
+
 // Identifier is a named identifier.
 type Identifier struct {
 	// Token is the identifiers lexical token.
@@ -1235,10 +1680,20 @@ func (i Identifier) String() string {
 
 // Register is an ARC Register.
 type Register struct {
+	// Token is the registers lexical token.
+	Token token.Token
+	// Position is the position in the source.
+	Position token.Pos
+
 	// Name is the name/identifier of the register.
 	Name string
 }
 
+// Pos returns the registers position.
+func (r Register) Pos() token.Pos {
+	return r.Position
+}
+
 func (r Register) String() string {
 	return r.Name
 }
@@ -1256,6 +1711,11 @@ type Integer struct {
 	Value int32
 }
 
+// Pos returns the integers position.
+func (i Integer) Pos() token.Pos {
+	return i.Position
+}
+
 func (i Integer) String() string {
 	// We return the literal representation to preserve the format.
 	return i.Literal
@@ -0,0 +1,419 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// TestExpression_Resolve validates the resolution of expressions.
+func TestExpression_Resolve(t *testing.T) {
+	symbols := SymbolTable{"x": 2048}
+	tests := []struct {
+		exp  Expression
+		addr ResolvedAddr
+		err  string
+	}{
+		// Register-relative.
+		{
+			exp:  Expression{Base: &Register{Name: "%r1"}},
+			addr: ResolvedAddr{Register: &Register{Name: "%r1"}},
+		},
+		{
+			exp:  Expression{Base: &Register{Name: "%r1"}, Operator: "+", Offset: &Integer{Value: 8}},
+			addr: ResolvedAddr{Register: &Register{Name: "%r1"}, Offset: 8},
+		},
+		{
+			exp:  Expression{Base: &Register{Name: "%r1"}, Operator: "-", Offset: &Integer{Value: 8}},
+			addr: ResolvedAddr{Register: &Register{Name: "%r1"}, Offset: -8},
+		},
+		// Symbol-absolute.
+		{
+			exp:  Expression{Base: &Identifier{Name: "x"}},
+			addr: ResolvedAddr{Offset: 2048},
+		},
+		{
+			exp:  Expression{Base: &Identifier{Name: "x"}, Operator: "+", Offset: &Integer{Value: 4}},
+			addr: ResolvedAddr{Offset: 2052},
+		},
+		// Unresolved.
+		{
+			exp: Expression{Base: &Identifier{Name: "y"}},
+			err: `undefined identifier "y"`,
+		},
+		{
+			exp: Expression{Base: &Register{Name: "%r1"}, Operator: "*", Offset: &Integer{Value: 4}},
+			err: `invalid operator "*" in expression [%r1*4]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exp.String(), func(t *testing.T) {
+			addr, err := tt.exp.Resolve(symbols)
+			if tt.err != "" {
+				assert(t, err != nil, "expected error but got nil")
+				equals(t, tt.err, err.Error())
+				return
+			}
+			ok(t, err)
+			equals(t, tt.addr, addr)
+			equals(t, tt.addr.Register == nil, addr.Absolute())
+		})
+	}
+}
+
+// TestCommentStatement_String validates the string representation of comments.
+func TestCommentStatement_String(t *testing.T) {
+	tests := []struct {
+		text string
+		str  string
+	}{
+		{text: "", str: "!"},
+		{text: "!", str: "!"},
+		{text: "!   ", str: "!"},
+		{text: "!comment", str: "! comment"},
+		{text: "! comment  ", str: "! comment"},
+		{text: "!   spaced", str: "!   spaced"},
+		{text: "!\tindented", str: "!\tindented"},
+		{text: "!!double", str: "!!double"},
+		{text: "! ----- !", str: "! ----- !"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			equals(t, tt.str, CommentStatement{Text: tt.text}.String())
+		})
+	}
+}
+
+// TestShiftStatement_String validates the string representation of the shift
+// statements.
+func TestShiftStatement_String(t *testing.T) {
+	src, dst := &Register{Name: "%r1"}, &Register{Name: "%r2"}
+	op := &Integer{Value: 4, Literal: "4"}
+	equals(t, "sll %r1, 4, %r2", SLLStatement{Source: src, Operand: op, Destination: dst}.String())
+	equals(t, "sra %r1, 4, %r2", SRAStatement{Source: src, Operand: op, Destination: dst}.String())
+}
+
+// TestOperands validates that the operands of a statement are returned in
+// source order.
+func TestOperands(t *testing.T) {
+	src := &Register{Token: token.REG, Position: token.Pos{Line: 1, Char: 5}, Name: "%r1"}
+	op := &Integer{Token: token.INT, Position: token.Pos{Line: 1, Char: 10}, Value: 4, Literal: "4"}
+	dst := &Register{Token: token.REG, Position: token.Pos{Line: 1, Char: 13}, Name: "%r2"}
+	target := &Identifier{Token: token.IDENT, Position: token.Pos{Line: 2, Char: 4}, Name: "x"}
+	exp := &Expression{Position: token.Pos{Line: 3, Char: 4}, Base: target}
+
+	tests := []struct {
+		stmt Statement
+		ops  []Node
+	}{
+		{stmt: &AddStatement{Source: src, Operand: op, Destination: dst}, ops: []Node{src, op, dst}},
+		{stmt: &BAStatement{Target: target}, ops: []Node{target}},
+		{stmt: &LoadStatement{Source: exp, Destination: dst}, ops: []Node{exp, dst}},
+		{stmt: &StoreStatement{Source: src, Destination: exp}, ops: []Node{src, exp}},
+		{stmt: &LabelStatement{Ident: target, Reference: &SLLStatement{Source: src, Operand: op, Destination: dst}}, ops: []Node{src, op, dst}},
+		{stmt: &LabelStatement{Ident: target, Reference: op}, ops: []Node{op}},
+		{stmt: &BeginStatement{}, ops: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt.String(), func(t *testing.T) {
+			equals(t, tt.ops, Operands(tt.stmt))
+		})
+	}
+
+	var positions []string
+	for _, n := range Operands(&AddStatement{Source: src, Operand: op, Destination: dst}) {
+		positions = append(positions, n.Pos().String())
+	}
+	equals(t, []string{"1:5", "1:10", "1:13"}, positions)
+}
+
+// TestEqual validates the structural comparison of statements.
+func TestEqual(t *testing.T) {
+	reg := func(name string, line int) *Register {
+		return &Register{Token: token.REG, Position: token.Pos{Line: line, Char: 5}, Name: name}
+	}
+	integer := func(lit string, val int32, line int) *Integer {
+		return &Integer{Token: token.INT, Position: token.Pos{Line: line, Char: 9}, Literal: lit, Value: val}
+	}
+	add := func(line int, lit string, val int32) Statement {
+		return &AddStatement{Token: token.ADD, Position: token.Pos{Line: line, Char: 1}, Source: reg("%r1", line), Operand: integer(lit, val, line), Destination: reg("%r2", line)}
+	}
+	label := func(line int, stmt Statement) Statement {
+		ref, _ := stmt.(Reference)
+		return &LabelStatement{Token: token.IDENT, Position: token.Pos{Line: line, Char: 1}, Ident: &Identifier{Token: token.IDENT, Position: token.Pos{Line: line, Char: 1}, Name: "x"}, Reference: ref}
+	}
+
+	tests := []struct {
+		name  string
+		a, b  Statement
+		equal bool
+	}{
+		{"position", add(1, "5", 5), add(7, "5", 5), true},
+		{"literal", add(1, "0x10", 16), add(2, "16", 16), true},
+		{"value", add(1, "5", 5), add(1, "6", 6), false},
+		{"type", add(1, "5", 5), &SubStatement{Token: token.SUB, Source: reg("%r1", 1), Operand: integer("5", 5, 1), Destination: reg("%r2", 1)}, false},
+		{"register", add(1, "5", 5), &AddStatement{Token: token.ADD, Source: reg("%r1", 1), Operand: reg("%r5", 1), Destination: reg("%r2", 1)}, false},
+		{"label", label(1, add(1, "5", 5)), label(3, add(3, "5", 5)), true},
+		{"label reference", label(1, add(1, "5", 5)), label(1, add(1, "6", 6)), false},
+		{"nil", nil, nil, true},
+		{"nil and statement", add(1, "5", 5), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.equal, Equal(tt.a, tt.b))
+			equals(t, tt.equal, Equal(tt.b, tt.a))
+		})
+	}
+}
+
+// TestDiff validates the description of the first difference between two
+// statements.
+func TestDiff(t *testing.T) {
+	reg := func(name string) *Register { return &Register{Token: token.REG, Name: name} }
+	integer := func(val int32) *Integer { return &Integer{Token: token.INT, Literal: "0", Value: val} }
+	word := func(vals ...int32) *WordStatement {
+		stmt := &WordStatement{Token: token.WORD}
+		for _, val := range vals {
+			stmt.Values = append(stmt.Values, integer(val))
+		}
+		return stmt
+	}
+
+	tests := []struct {
+		name string
+		a, b Statement
+		diff string
+	}{
+		{"equal", &AddStatement{Token: token.ADD, Source: reg("%r1"), Operand: integer(5), Destination: reg("%r2")}, &AddStatement{Token: token.ADD, Source: reg("%r1"), Operand: integer(5), Destination: reg("%r2"), Position: token.Pos{Line: 3}}, ""},
+		{"value", &AddStatement{Token: token.ADD, Operand: integer(5)}, &AddStatement{Token: token.ADD, Operand: integer(6)}, "Operand.Value: 5 != 6"},
+		{"operand", &AddStatement{Token: token.ADD, Operand: integer(5)}, &AddStatement{Token: token.ADD, Operand: reg("%r5")}, "Operand: *ast.Integer != *ast.Register"},
+		{"register", &AddStatement{Token: token.ADD, Source: reg("%r1")}, &AddStatement{Token: token.ADD, Source: reg("%r3")}, `Source.Name: "%r1" != "%r3"`},
+		{"missing", &AddStatement{Token: token.ADD, Source: reg("%r1")}, &AddStatement{Token: token.ADD}, "Source: *ast.Register != nil"},
+		{"type", &AddStatement{Token: token.ADD}, &SubStatement{Token: token.SUB}, "*ast.AddStatement != *ast.SubStatement"},
+		{"values", word(1, 2), word(1, 3), "Values[1].Value: 2 != 3"},
+		{"length", word(1, 2), word(1), "Values: 2 != 1 elements"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.diff, Diff(tt.a, tt.b))
+			equals(t, tt.diff == "", Equal(tt.a, tt.b))
+		})
+	}
+}
+
+// fakeT records the failure reported by AssertEqual.
+type fakeT struct {
+	msg string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.msg = fmt.Sprintf(format, args...)
+}
+
+// TestAssertEqual validates that AssertEqual names the differing field of
+// statements which aren't equal.
+func TestAssertEqual(t *testing.T) {
+	want := &LoadStatement{Token: token.LOAD, Source: &Register{Token: token.REG, Name: "%r1"}, Destination: &Register{Token: token.REG, Name: "%r2"}}
+	got := &LoadStatement{Token: token.LOAD, Source: &Register{Token: token.REG, Name: "%r1"}, Destination: &Register{Token: token.REG, Name: "%r3"}, Position: token.Pos{Line: 2, Char: 1}}
+
+	var ft fakeT
+	AssertEqual(&ft, want, want)
+	equals(t, "", ft.msg)
+	AssertEqual(&ft, want, got)
+	assert(t, strings.Contains(ft.msg, `statements differ at Destination.Name: "%r2" != "%r3"`), "unexpected message %q", ft.msg)
+	assert(t, strings.Contains(ft.msg, "want: ld %r1, %r2") && strings.Contains(ft.msg, "got: ld %r1, %r3"), "unexpected message %q", ft.msg)
+}
+
+// TestProgram_Validate validates that malformed expressions are reported.
+func TestProgram_Validate(t *testing.T) {
+	pos := token.Pos{Line: 1, Char: 4}
+	tests := []struct {
+		exp *Expression
+		err string
+	}{
+		{
+			exp: &Expression{Position: pos, Base: &Register{Name: "%r1"}},
+		},
+		{
+			exp: &Expression{Position: pos, Base: &Register{Name: "%r1"}, Operator: "+", Offset: &Integer{Value: 4, Literal: "4"}},
+		},
+		{
+			exp: &Expression{Position: pos, Base: &Register{Name: "%r1"}, Operator: "+"},
+			err: `1:4: expression with operator "+" but without offset`,
+		},
+		{
+			exp: &Expression{Position: pos, Base: &Register{Name: "%r1"}, Offset: &Integer{Value: 4, Literal: "4"}},
+			err: "1:4: expression with offset 4 but without operator",
+		},
+		{
+			exp: &Expression{Position: pos},
+			err: "1:4: expression without base",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			prog := Program{Statements: Statements{
+				&LabelStatement{Ident: &Identifier{Name: "x"}, Reference: &LoadStatement{Source: tt.exp, Destination: &Register{Name: "%r2"}}},
+			}}
+			err := prog.Validate()
+			if tt.err == "" {
+				ok(t, err)
+				return
+			}
+			assert(t, err != nil, "expected error but got nil")
+			equals(t, tt.err, err.Error())
+		})
+	}
+
+	// Malformed expressions must not panic when printed.
+	exp := Expression{Base: &Register{Name: "%r1"}, Operator: "+"}
+	equals(t, "[%r1]", exp.String())
+}
+
+// TestLayout validates the addresses of statements across multiple .org
+// sections.
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		stmts Statements
+		addrs []int32
+	}{
+		// Without .org, the location counter starts at zero.
+		{
+			stmts: Statements{nop(1), nop(2)},
+			addrs: []int32{0, 4},
+		},
+		// Code section followed by a data section.
+		{
+			stmts: Statements{&BeginStatement{}, org(2048), nop(3), nop(4), &CommentStatement{Text: " data"}, org(3000), data("x", 25), data("y", 10), &EndStatement{}},
+			addrs: []int32{2048, 2052, 3000, 3004},
+		},
+		// Data section placed before the code section at a higher address.
+		{
+			stmts: Statements{org(3000), data("x", 25), org(2048), nop(4), nop(5)},
+			addrs: []int32{3000, 2048, 2052},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			prog := &Program{Statements: tt.stmts}
+			layout, err := Layout(prog)
+			ok(t, err)
+
+			var got []int32
+			for _, stmt := range prog.Statements {
+				if addr, occupies := layout.Addrs[stmt]; occupies {
+					got = append(got, addr)
+				}
+			}
+			equals(t, tt.addrs, got)
+		})
+	}
+}
+
+// TestLayout_ArraySum validates the label addresses and the sections of a
+// program shaped like the array sum sample. Its code section at 2048 holds 16
+// instructions and 3 data words, the array at 3000 holds 4 data words.
+func TestLayout_ArraySum(t *testing.T) {
+	stmts := Statements{&BeginStatement{}, org(2048)}
+	labels := map[int]string{2: "init_r", 6: "loop", 12: "done"}
+	for i := 0; i < 16; i++ {
+		if name, labeled := labels[i]; labeled {
+			stmts = append(stmts, &LabelStatement{Ident: &Identifier{Name: name}, Reference: nop(i)})
+			continue
+		}
+		stmts = append(stmts, nop(i))
+	}
+	stmts = append(stmts, data("start", 3000), data("length", 4), data("zero", 0), org(3000))
+	stmts = append(stmts, &WordStatement{Values: []*Integer{{Value: 10}, {Value: 20}, {Value: -10}, {Value: 10}}}, &EndStatement{})
+
+	layout, err := Layout(&Program{Statements: stmts})
+	ok(t, err)
+	equals(t, SymbolTable{
+		"init_r": 2056,
+		"loop":   2072,
+		"done":   2096,
+		"start":  2112,
+		"length": 2116,
+		"zero":   2120,
+	}, layout.Symbols)
+	equals(t, []Section{
+		{Origin: 2048, Code: 64, Data: 12, High: 2123},
+		{Origin: 3000, Code: 0, Data: 16, High: 3015},
+	}, layout.Sections)
+}
+
+// TestLayout_Sections validates that statements before the first .org
+// directive start at zero and empty sections are omitted.
+func TestLayout_Sections(t *testing.T) {
+	prog := &Program{Statements: Statements{nop(1), org(2048), org(4000), &WordStatement{Values: []*Integer{{Value: 1}, {Value: 2}}}}}
+	layout, err := Layout(prog)
+	ok(t, err)
+	equals(t, []Section{
+		{Origin: 0, Code: 4, Data: 0, High: 3},
+		{Origin: 4000, Code: 0, Data: 8, High: 4007},
+	}, layout.Sections)
+}
+
+// TestLayout_Overlap validates that overlapping sections are reported while
+// the layout is still returned.
+func TestLayout_Overlap(t *testing.T) {
+	prog := &Program{Statements: Statements{org(2048), nop(2), nop(3), org(2052), nop(5)}}
+	layout, err := Layout(prog)
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, "5:1: address 0x00000804 already occupied by statement at 3:1", err.Error())
+	lerr, valid := err.(*LayoutError)
+	assert(t, valid, "expected a *LayoutError but got %T", err)
+	equals(t, prog.Statements[4], lerr.Overlaps[0].Statement)
+	equals(t, int32(2052), layout.Addrs[prog.Statements[4]])
+}
+
+// nop returns a nop instruction in the given line.
+func nop(line int) *NopStatement {
+	return &NopStatement{Token: token.NOP, Position: token.Pos{Line: line, Char: 1}}
+}
+
+// org returns an .org directive moving the location counter to addr.
+func org(addr int32) *OrgStatement {
+	return &OrgStatement{Token: token.ORG, Value: &Integer{Value: addr}}
+}
+
+// data returns a label referencing the given integer.
+func data(name string, val int32) *LabelStatement {
+	return &LabelStatement{Ident: &Identifier{Name: name}, Reference: &Integer{Value: val}}
+}
+
+// assert fails the test if the condition is false.
+func assert(tb testing.TB, condition bool, msg string, v ...interface{}) {
+	tb.Helper()
+	if !condition {
+		tb.Fatalf("\033[31m "+msg+"\033[39m\n\n", v...)
+	}
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/lukasmalkmus/arc/token"
+)
+
+var (
+	posType     = reflect.TypeOf(token.Pos{})
+	integerType = reflect.TypeOf(Integer{})
+)
+
+// Equal reports whether two statements are structurally equal. Statements are
+// equal if they are of the same type and their tokens and operands are equal.
+// Positions in the source are ignored. Integers are compared by their Value
+// only, so "0x10" equals "16" and a literal rewritten by fmt still compares
+// equal. Labels are compared including the statement they reference and
+// comments including the statement they trail.
+func Equal(a, b Statement) bool {
+	return Diff(a, b) == ""
+}
+
+// Diff describes the first difference between two statements as compared by
+// Equal. The description names the path of the differing field and both of its
+// values, like "Operand.Value: 5 != 6". The empty string is returned if the
+// statements are equal.
+func Diff(a, b Statement) string {
+	return diff(reflect.ValueOf(a), reflect.ValueOf(b), "")
+}
+
+// TestingT is the part of testing.TB used by AssertEqual.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// AssertEqual fails the test if the statements aren't equal as compared by
+// Equal. The failure names the first differing field, which is easier to spot
+// than the difference of two complete statements including their positions.
+func AssertEqual(tb TestingT, want, got Statement) {
+	tb.Helper()
+	if d := Diff(want, got); d != "" {
+		tb.Fatalf("\033[31m\n\n\tstatements differ at %s\n\n\twant: %v\n\n\tgot: %v\033[39m\n\n", d, want, got)
+	}
+}
+
+// diff compares two values of the AST field by field, skipping positions and
+// the literals of integers. It returns the description of the first difference
+// found below the given path.
+func diff(a, b reflect.Value, path string) string {
+	differs := func(format string, args ...interface{}) string {
+		if path == "" {
+			return fmt.Sprintf(format, args...)
+		}
+		return path + ": " + fmt.Sprintf(format, args...)
+	}
+
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() == b.IsValid() {
+			return ""
+		}
+		return differs("%s != %s", typeName(a), typeName(b))
+	}
+	if a.Type() != b.Type() {
+		return differs("%s != %s", typeName(a), typeName(b))
+	}
+
+	switch a.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() == b.IsNil() {
+				return ""
+			}
+			return differs("%s != %s", typeName(a), typeName(b))
+		}
+		return diff(a.Elem(), b.Elem(), path)
+	case reflect.Struct:
+		if a.Type() == posType {
+			return ""
+		}
+		for i := 0; i < a.NumField(); i++ {
+			name := a.Type().Field(i).Name
+			if a.Type() == integerType && name == "Literal" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			if d := diff(a.Field(i), b.Field(i), name); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return differs("%d != %d elements", a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if d := diff(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.String:
+		if a.String() != b.String() {
+			return differs("%q != %q", a.String(), b.String())
+		}
+		return ""
+	case reflect.Bool:
+		if a.Bool() != b.Bool() {
+			return differs("%t != %t", a.Bool(), b.Bool())
+		}
+		return ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if a.Int() != b.Int() {
+			return differs("%v != %v", a.Interface(), b.Interface())
+		}
+		return ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if a.Uint() != b.Uint() {
+			return differs("%v != %v", a.Interface(), b.Interface())
+		}
+		return ""
+	}
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return differs("%v != %v", a.Interface(), b.Interface())
+	}
+	return ""
+}
+
+// typeName returns the name of the dynamic type of a value, "nil" for nil
+// values.
+func typeName(v reflect.Value) string {
+	if !v.IsValid() || ((v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) && v.IsNil()) {
+		return "nil"
+	}
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v.Type().String()
+}
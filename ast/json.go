@@ -0,0 +1,235 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// nodeTypes are the concrete types of the nodes which can be decoded from
+// JSON, by their name.
+var nodeTypes = make(map[string]reflect.Type)
+
+func init() {
+	for _, node := range []Node{
+		&CommentStatement{}, &BeginStatement{}, &EndStatement{}, &OrgStatement{},
+		&GlobalStatement{}, &ExternStatement{}, &WordStatement{}, &EquStatement{},
+		&LabelStatement{}, &LoadStatement{}, &StoreStatement{},
+		&AddStatement{}, &AddCCStatement{}, &SubStatement{}, &SubCCStatement{},
+		&AndStatement{}, &AndCCStatement{}, &OrStatement{}, &OrCCStatement{},
+		&OrnStatement{}, &OrnCCStatement{}, &XorStatement{}, &XorCCStatement{},
+		&SLLStatement{}, &SRAStatement{},
+		&BEStatement{}, &BNEStatement{}, &BNEGStatement{}, &BPOSStatement{},
+		&BAStatement{}, &CallStatement{}, &JumpAndLinkStatement{},
+		&NopStatement{}, &CmpStatement{}, &RetStatement{}, &MovStatement{},
+		&ClrStatement{}, &AssertStatement{},
+		&Expression{}, &ConstantExpression{}, &Identifier{}, &Register{}, &Integer{},
+	} {
+		typ := reflect.TypeOf(node).Elem()
+		nodeTypes[typ.Name()] = typ
+	}
+}
+
+// jsonProgram is the JSON representation of a program.
+type jsonProgram struct {
+	Filename   token.Pos
+	Statements []json.RawMessage
+}
+
+// MarshalJSON implements json.Marshaler. Every node is encoded as object
+// carrying the name of its concrete type in the "type" field, so it can be
+// reconstructed by UnmarshalJSON. Tokens are encoded by their string
+// representation. Integer literals and positions are preserved. The statement a
+// comment trails is encoded as its index in the list of statements.
+func (p Program) MarshalJSON() ([]byte, error) {
+	index := make(map[Statement]int, len(p.Statements))
+	for i, stmt := range p.Statements {
+		index[stmt] = i
+	}
+
+	res := jsonProgram{Filename: p.Filename, Statements: make([]json.RawMessage, len(p.Statements))}
+	for i, stmt := range p.Statements {
+		data, err := marshalNode(stmt, index)
+		if err != nil {
+			return nil, err
+		}
+		res.Statements[i] = data
+	}
+	return json.Marshal(res)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It decodes programs encoded by
+// MarshalJSON.
+func (p *Program) UnmarshalJSON(data []byte) error {
+	var res jsonProgram
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+
+	trailing := make(map[*CommentStatement]int)
+	stmts := make(Statements, len(res.Statements))
+	for i, raw := range res.Statements {
+		node, err := unmarshalNode(raw, trailing)
+		if err != nil {
+			return fmt.Errorf("statement %d: %s", i, err)
+		}
+		stmt, valid := node.(Statement)
+		if !valid {
+			return fmt.Errorf("statement %d: %T is not a statement", i, node)
+		}
+		stmts[i] = stmt
+	}
+
+	// Comments reference the statement they trail, which is decoded
+	// separately.
+	for comment, i := range trailing {
+		if i < 0 || i >= len(stmts) {
+			return fmt.Errorf("comment trails unknown statement %d", i)
+		}
+		comment.Statement = stmts[i]
+	}
+
+	p.Filename = res.Filename
+	p.Statements = stmts
+	return nil
+}
+
+// marshalNode encodes a node with its type. Fields of interface type are
+// encoded the same way.
+func marshalNode(node interface{}, index map[Statement]int) ([]byte, error) {
+	v := reflect.ValueOf(node)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return []byte("null"), nil
+	}
+	v = reflect.Indirect(v)
+	if _, known := nodeTypes[v.Type().Name()]; !known {
+		return nil, fmt.Errorf("can't encode node of type %T", node)
+	}
+
+	obj := map[string]interface{}{"type": v.Type().Name()}
+	for i := 0; i < v.NumField(); i++ {
+		field, val := v.Type().Field(i), v.Field(i)
+		switch {
+		case field.Type == reflect.TypeOf((*Statement)(nil)).Elem() && v.Type() == reflect.TypeOf(CommentStatement{}):
+			if !val.IsNil() {
+				trails, known := index[val.Interface().(Statement)]
+				if !known {
+					return nil, fmt.Errorf("comment trails statement %q which isn't part of the program", val.Interface())
+				}
+				obj[field.Name] = trails
+			}
+		case isNodeType(field.Type):
+			data, err := marshalNode(val.Interface(), index)
+			if err != nil {
+				return nil, err
+			}
+			obj[field.Name] = json.RawMessage(data)
+		case field.Type.Kind() == reflect.Slice && isNodeType(field.Type.Elem()) && !val.IsNil():
+			elems := make([]json.RawMessage, val.Len())
+			for j := range elems {
+				data, err := marshalNode(val.Index(j).Interface(), index)
+				if err != nil {
+					return nil, err
+				}
+				elems[j] = data
+			}
+			obj[field.Name] = elems
+		default:
+			obj[field.Name] = val.Interface()
+		}
+	}
+	return json.Marshal(obj)
+}
+
+// isNodeType reports whether values of a type are encoded by marshalNode.
+// These are interfaces and pointers to nodes.
+func isNodeType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Interface {
+		return true
+	}
+	if typ.Kind() != reflect.Ptr {
+		return false
+	}
+	_, known := nodeTypes[typ.Elem().Name()]
+	return known && nodeTypes[typ.Elem().Name()] == typ.Elem()
+}
+
+// unmarshalNode decodes a node encoded by marshalNode. The index of the
+// statement a comment trails is recorded in trailing.
+func unmarshalNode(data []byte, trailing map[*CommentStatement]int) (interface{}, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	var name string
+	if err := json.Unmarshal(obj["type"], &name); err != nil {
+		return nil, fmt.Errorf("invalid node type: %s", err)
+	}
+	typ, known := nodeTypes[name]
+	if !known {
+		return nil, fmt.Errorf("unknown node type %q", name)
+	}
+
+	node := reflect.New(typ)
+	v := node.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field, val := typ.Field(i), v.Field(i)
+		raw, present := obj[field.Name]
+		if !present {
+			continue
+		}
+		switch {
+		case field.Type == reflect.TypeOf((*Statement)(nil)).Elem() && typ == reflect.TypeOf(CommentStatement{}):
+			var trails int
+			if err := json.Unmarshal(raw, &trails); err != nil {
+				return nil, fmt.Errorf("%s.%s: %s", name, field.Name, err)
+			}
+			trailing[node.Interface().(*CommentStatement)] = trails
+		case isNodeType(field.Type):
+			if err := unmarshalField(raw, val, trailing); err != nil {
+				return nil, fmt.Errorf("%s.%s: %s", name, field.Name, err)
+			}
+		case field.Type.Kind() == reflect.Slice && isNodeType(field.Type.Elem()):
+			var elems []json.RawMessage
+			if err := json.Unmarshal(raw, &elems); err != nil {
+				return nil, fmt.Errorf("%s.%s: %s", name, field.Name, err)
+			}
+			if elems == nil {
+				continue
+			}
+			val.Set(reflect.MakeSlice(field.Type, len(elems), len(elems)))
+			for j, elem := range elems {
+				if err := unmarshalField(elem, val.Index(j), trailing); err != nil {
+					return nil, fmt.Errorf("%s.%s: %s", name, field.Name, err)
+				}
+			}
+		default:
+			if err := json.Unmarshal(raw, val.Addr().Interface()); err != nil {
+				return nil, fmt.Errorf("%s.%s: %s", name, field.Name, err)
+			}
+		}
+	}
+	return node.Interface(), nil
+}
+
+// unmarshalField decodes a node encoded by marshalNode into a field holding
+// an interface or a pointer to a node. The field is left untouched for null.
+func unmarshalField(data []byte, field reflect.Value, trailing map[*CommentStatement]int) error {
+	node, err := unmarshalNode(data, trailing)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return nil
+	}
+	if !reflect.TypeOf(node).AssignableTo(field.Type()) {
+		return fmt.Errorf("%T is not a %s", node, field.Type())
+	}
+	field.Set(reflect.ValueOf(node))
+	return nil
+}
@@ -0,0 +1,193 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LayoutResult is the memory layout of a program. It is computed once by
+// Layout and shared by the assembler and the checks which need to know where
+// statements and labels are placed in memory.
+type LayoutResult struct {
+	// Addrs are the addresses of the statements which occupy memory, these
+	// are instructions and data words.
+	Addrs map[Statement]int32
+	// Symbols are the addresses of the labels. A label naming an .org
+	// section addresses the start of the section.
+	Symbols SymbolTable
+	// Sections are the .org sections which occupy memory, in source order.
+	Sections []Section
+}
+
+// Section summarizes the memory occupied by an .org section of a program.
+// Statements before the first .org directive form a section at origin zero.
+type Section struct {
+	// Origin is the address the section starts at.
+	Origin int32
+	// Code is the number of bytes occupied by instructions.
+	Code int32
+	// Data is the number of bytes occupied by data words.
+	Data int32
+	// High is the highest address used by the section, the address of its
+	// last byte.
+	High int32
+}
+
+// Overlap is a statement placed at an address which is already occupied by
+// another statement.
+type Overlap struct {
+	// Addr is the first address occupied by both statements.
+	Addr int32
+	// Statement is the statement placed at the occupied address.
+	Statement Statement
+	// Prev is the statement occupying the address before.
+	Prev Statement
+}
+
+// String returns the description of the overlap, like "address 0x00000804
+// already occupied by statement at 3:1".
+func (o Overlap) String() string {
+	return fmt.Sprintf("address 0x%08x already occupied by statement at %s", uint32(o.Addr), o.Prev.Pos())
+}
+
+// LayoutError reports the overlapping statements of a program.
+type LayoutError struct {
+	Overlaps []Overlap
+}
+
+// Error returns the string representation of the error. It implements the error
+// interface.
+func (e LayoutError) Error() string {
+	msgs := make([]string, len(e.Overlaps))
+	for i, o := range e.Overlaps {
+		msgs[i] = fmt.Sprintf("%s: %s", o.Statement.Pos(), o)
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Layout computes the memory address of every statement which occupies memory,
+// these are instructions and data words, and the address of every label. The
+// location counter starts at zero and every occupying statement advances it by
+// its size. An .org directive, labeled or not, resets the location counter to
+// its value instead of continuing the running counter. This way, every section
+// counts from its own origin, no matter where it is placed in the source.
+// Comments, constants, assertions and directives don't occupy memory and
+// therefore have no address.
+//
+// A *LayoutError is returned if an .org directive moves the location counter
+// into a section which is already occupied. The layout is returned anyway.
+func Layout(prog *Program) (*LayoutResult, error) {
+	res := &LayoutResult{
+		Addrs:   make(map[Statement]int32),
+		Symbols: make(SymbolTable),
+	}
+
+	var (
+		lc  int32
+		sec Section
+	)
+	flush := func() {
+		if size := sec.Code + sec.Data; size > 0 {
+			sec.High = sec.Origin + size - 1
+			res.Sections = append(res.Sections, sec)
+		}
+	}
+	for _, stmt := range prog.Statements {
+		if org, isOrg := origin(stmt); isOrg {
+			flush()
+			lc = org.Value.Value
+			sec = Section{Origin: lc}
+			if label, valid := stmt.(*LabelStatement); valid {
+				res.Symbols[label.Ident.Name] = lc
+			}
+			continue
+		}
+		if label, valid := stmt.(*LabelStatement); valid {
+			res.Symbols[label.Ident.Name] = lc
+		}
+		size := SizeOf(stmt)
+		if size == 0 {
+			continue
+		}
+		res.Addrs[stmt] = lc
+		lc += size
+		if isData(stmt) {
+			sec.Data += size
+		} else {
+			sec.Code += size
+		}
+	}
+	flush()
+
+	if overlaps := overlaps(prog, res.Addrs); len(overlaps) > 0 {
+		return res, &LayoutError{overlaps}
+	}
+	return res, nil
+}
+
+// SizeOf returns the number of bytes a statement occupies in memory. Every
+// instruction occupies one word, a .word directive one word per value and a
+// label as much as the statement it references. Comments, constants,
+// assertions and directives don't occupy memory.
+func SizeOf(stmt Statement) int32 {
+	switch s := stmt.(type) {
+	case *CommentStatement, *BeginStatement, *EndStatement, *OrgStatement, *GlobalStatement, *ExternStatement, *EquStatement, *AssertStatement:
+		return 0
+	case *LabelStatement:
+		if ref, valid := s.Reference.(Statement); valid {
+			return SizeOf(ref)
+		}
+	case *WordStatement:
+		return 4 * int32(len(s.Values))
+	}
+	return 4
+}
+
+// origin returns the .org directive of a statement, which is either the
+// directive itself or a label naming the section it starts.
+func origin(stmt Statement) (*OrgStatement, bool) {
+	if label, valid := stmt.(*LabelStatement); valid {
+		org, isOrg := label.Reference.(*OrgStatement)
+		return org, isOrg
+	}
+	org, isOrg := stmt.(*OrgStatement)
+	return org, isOrg
+}
+
+// isData reports whether a statement is data. Data is either a label
+// referencing an integer or a .word directive, labeled or not.
+func isData(stmt Statement) bool {
+	switch s := stmt.(type) {
+	case *LabelStatement:
+		switch s.Reference.(type) {
+		case *Integer, *WordStatement:
+			return true
+		}
+	case *WordStatement:
+		return true
+	}
+	return false
+}
+
+// overlaps checks that no two statements share the address of a word. This
+// happens if an .org directive moves the location counter into a section which
+// is already occupied. An overlap is returned for every statement placed at an
+// occupied address.
+func overlaps(prog *Program, addrs map[Statement]int32) []Overlap {
+	var res []Overlap
+	occupied := make(map[int32]Statement)
+	for _, stmt := range prog.Statements {
+		addr, occupies := addrs[stmt]
+		if !occupies {
+			continue
+		}
+		for off := int32(0); off < SizeOf(stmt); off += 4 {
+			if prev, exists := occupied[addr+off]; exists {
+				res = append(res, Overlap{Addr: addr + off, Statement: stmt, Prev: prev})
+				break
+			}
+			occupied[addr+off] = stmt
+		}
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package ast
+
+// Visibility describes the visibility of a label across programs.
+type Visibility int
+
+const (
+	// Local labels are only visible inside the program declaring them.
+	Local Visibility = iota
+
+	// Global labels are declared by the program and exported by the .global
+	// directive.
+	Global
+
+	// Extern labels are imported by the .extern directive and declared by
+	// another program.
+	Extern
+)
+
+func (v Visibility) String() string {
+	switch v {
+	case Global:
+		return "global"
+	case Extern:
+		return "extern"
+	}
+	return "local"
+}
+
+// Linkage returns the visibility of every label declared, exported or
+// imported by the program.
+func (p Program) Linkage() map[string]Visibility {
+	linkage := make(map[string]Visibility)
+	for _, stmt := range p.Statements {
+		switch s := stmt.(type) {
+		case *LabelStatement:
+			if _, prs := linkage[s.Ident.Name]; !prs {
+				linkage[s.Ident.Name] = Local
+			}
+		case *GlobalStatement:
+			linkage[s.Ident.Name] = Global
+		case *ExternStatement:
+			linkage[s.Ident.Name] = Extern
+		}
+	}
+	return linkage
+}
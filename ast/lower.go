@@ -0,0 +1,66 @@
+package ast
+
+import "github.com/lukasmalkmus/arc/token"
+
+// Lower returns a copy of the program with every pseudo instruction replaced by
+// the real instruction it stands for. Labels referencing a pseudo instruction
+// are copied to reference the real instruction instead. The other statements
+// are shared with the original program. Every pseudo instruction is lowered to
+// exactly one instruction, so the layout of the program doesn't change.
+func Lower(prog *Program) *Program {
+	res := &Program{Filename: prog.Filename, Statements: make(Statements, len(prog.Statements))}
+	for i, stmt := range prog.Statements {
+		res.Statements[i] = LowerStatement(stmt)
+	}
+	return res
+}
+
+// LowerStatement returns the real instruction a pseudo instruction stands for.
+// It keeps the position of the pseudo instruction. Other statements are
+// returned unchanged.
+//
+//	nop           -> add %r0, %r0, %r0
+//	cmp %rs, op   -> subcc %rs, op, %r0
+//	ret, retl     -> jmpl %r15+4, %r0
+//	mov op, %rd   -> or %r0, op, %rd
+//	clr %rd       -> or %r0, %r0, %rd
+func LowerStatement(stmt Statement) Statement {
+	switch s := stmt.(type) {
+	case *LabelStatement:
+		ref, valid := s.Reference.(Statement)
+		if !valid {
+			return stmt
+		}
+		lowered := LowerStatement(ref)
+		if lowered == ref {
+			return stmt
+		}
+		// Pseudo instructions are lowered to instructions which can be
+		// referenced by labels as well.
+		label := *s
+		label.Reference = lowered.(Reference)
+		return &label
+	case *NopStatement:
+		return &AddStatement{Token: token.ADD, Position: s.Position, Source: register("%r0", s.Position), Operand: register("%r0", s.Position), Destination: register("%r0", s.Position)}
+	case *CmpStatement:
+		return &SubCCStatement{Token: token.SUBCC, Position: s.Position, Source: s.Source, Operand: s.Operand, Destination: register("%r0", s.Position)}
+	case *RetStatement:
+		ret := &Expression{
+			Position: s.Position,
+			Base:     register("%r15", s.Position),
+			Operator: "+",
+			Offset:   &Integer{Token: token.INT, Position: s.Position, Value: 4, Literal: "4"},
+		}
+		return &JumpAndLinkStatement{Token: token.JMPL, Position: s.Position, ReturnAddress: ret, FromAddress: register("%r0", s.Position)}
+	case *MovStatement:
+		return &OrStatement{Token: token.OR, Position: s.Position, Source: register("%r0", s.Position), Operand: s.Operand, Destination: s.Destination}
+	case *ClrStatement:
+		return &OrStatement{Token: token.OR, Position: s.Position, Source: register("%r0", s.Position), Operand: register("%r0", s.Position), Destination: s.Destination}
+	}
+	return stmt
+}
+
+// register creates a register located at the given position.
+func register(name string, pos token.Pos) *Register {
+	return &Register{Token: token.REG, Position: pos, Name: name}
+}
@@ -0,0 +1,105 @@
+package ast
+
+// Operands returns the operands of a statement in the order they appear in the
+// source. These are the registers, integers, expressions and identifiers the
+// statement references. Expressions are returned as a whole. The operands of a
+// labeled statement are the ones of the referenced statement or the integer of
+// labeled data. Statements without operands return nil.
+func Operands(stmt Statement) []Node {
+	switch s := stmt.(type) {
+	case *OrgStatement:
+		return nodes(s.Value)
+	case *GlobalStatement:
+		return nodes(s.Ident)
+	case *ExternStatement:
+		return nodes(s.Ident)
+	case *EquStatement:
+		return nodes(s.Name, s.Value)
+	case *AssertStatement:
+		var ops []Node
+		for _, exp := range []*ConstantExpression{s.Left, s.Right} {
+			if exp == nil {
+				continue
+			}
+			for _, op := range exp.Operands {
+				ops = append(ops, op)
+			}
+		}
+		return nodes(ops...)
+	case *WordStatement:
+		ops := make([]Node, len(s.Values))
+		for i, val := range s.Values {
+			ops[i] = val
+		}
+		return nodes(ops...)
+	case *LabelStatement:
+		if ref, valid := s.Reference.(Statement); valid {
+			return Operands(ref)
+		}
+		return nodes(s.Reference)
+	case *LoadStatement:
+		return nodes(s.Source, s.Destination)
+	case *StoreStatement:
+		return nodes(s.Source, s.Destination)
+	case *AddStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *AddCCStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *SubStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *SubCCStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *AndStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *AndCCStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *OrStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *OrCCStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *OrnStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *OrnCCStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *XorStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *XorCCStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *SLLStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *SRAStatement:
+		return nodes(s.Source, s.Operand, s.Destination)
+	case *BEStatement:
+		return nodes(s.Target)
+	case *BNEStatement:
+		return nodes(s.Target)
+	case *BNEGStatement:
+		return nodes(s.Target)
+	case *BPOSStatement:
+		return nodes(s.Target)
+	case *BAStatement:
+		return nodes(s.Target)
+	case *CallStatement:
+		return nodes(s.Target)
+	case *JumpAndLinkStatement:
+		return nodes(s.ReturnAddress, s.FromAddress)
+	case *CmpStatement:
+		return nodes(s.Source, s.Operand)
+	case *MovStatement:
+		return nodes(s.Operand, s.Destination)
+	case *ClrStatement:
+		return nodes(s.Destination)
+	}
+	return nil
+}
+
+// nodes returns the given operands as list, skipping the ones which are nil.
+func nodes(operands ...Node) []Node {
+	var res []Node
+	for _, op := range operands {
+		if op != nil {
+			res = append(res, op)
+		}
+	}
+	return res
+}
@@ -0,0 +1,52 @@
+package ast
+
+import "fmt"
+
+// SymbolTable maps label names to the addresses they refer to.
+type SymbolTable map[string]int32
+
+// ResolvedAddr is the address an expression refers to. The address is either
+// relative to the content of a register or absolute.
+type ResolvedAddr struct {
+	// Register is the base register of a register-relative address. It is nil
+	// if the address is absolute.
+	Register *Register
+	// Offset is the signed offset which is added to the content of the base
+	// register. For absolute addresses it is the address itself.
+	Offset int32
+}
+
+// Absolute returns true if the address doesn't depend on the content of a
+// register.
+func (a ResolvedAddr) Absolute() bool {
+	return a.Register == nil
+}
+
+// Resolve resolves the expression to a register-relative or absolute address.
+// Identifiers used as base are looked up in the given symbol table. An error is
+// returned if the identifier isn't defined or the operator is invalid.
+func (e Expression) Resolve(symbols SymbolTable) (ResolvedAddr, error) {
+	var offset int32
+	if e.Offset != nil {
+		switch e.Operator {
+		case "+":
+			offset = e.Offset.Value
+		case "-":
+			offset = -e.Offset.Value
+		default:
+			return ResolvedAddr{}, fmt.Errorf("invalid operator %q in expression %s", e.Operator, e)
+		}
+	}
+
+	switch base := e.Base.(type) {
+	case *Register:
+		return ResolvedAddr{Register: base, Offset: offset}, nil
+	case *Identifier:
+		addr, ok := symbols[base.Name]
+		if !ok {
+			return ResolvedAddr{}, fmt.Errorf("undefined identifier %q", base.Name)
+		}
+		return ResolvedAddr{Offset: addr + offset}, nil
+	}
+	return ResolvedAddr{}, fmt.Errorf("invalid base in expression %s", e)
+}
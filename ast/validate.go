@@ -0,0 +1,51 @@
+package ast
+
+import "fmt"
+
+// Validate checks the invariants of the programs AST objects which the parser
+// guarantees but which aren't enforced for programmatically built programs. An
+// error is returned for the first violation found.
+func (p Program) Validate() error {
+	for _, stmt := range p.Statements {
+		if err := validateStatement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that the expression has a base and that an operator is given
+// if and only if an offset is given.
+func (e Expression) Validate() error {
+	if e.Base == nil {
+		return fmt.Errorf("%s: expression without base", e.Position)
+	}
+	if e.Operator != "" && e.Offset == nil {
+		return fmt.Errorf("%s: expression with operator %q but without offset", e.Position, e.Operator)
+	}
+	if e.Operator == "" && e.Offset != nil {
+		return fmt.Errorf("%s: expression with offset %s but without operator", e.Position, e.Offset)
+	}
+	return nil
+}
+
+// validateStatement validates the expressions of a statement.
+func validateStatement(stmt Statement) error {
+	var exp *Expression
+	switch s := stmt.(type) {
+	case *LabelStatement:
+		if ref, valid := s.Reference.(Statement); valid {
+			return validateStatement(ref)
+		}
+	case *LoadStatement:
+		exp, _ = s.Source.(*Expression)
+	case *StoreStatement:
+		exp, _ = s.Destination.(*Expression)
+	case *JumpAndLinkStatement:
+		exp = s.ReturnAddress
+	}
+	if exp != nil {
+		return exp.Validate()
+	}
+	return nil
+}
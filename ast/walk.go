@@ -0,0 +1,40 @@
+package ast
+
+// Walk traverses the statements of a program in source order. See Inspect for
+// how every statement is traversed.
+func Walk(prog *Program, fn func(Node) bool) {
+	for _, stmt := range prog.Statements {
+		Inspect(stmt, fn)
+	}
+}
+
+// Inspect traverses a node in depth-first order. It calls fn for the node and,
+// if fn returns true, for each of its children. The children of a label are
+// its identifier and its reference, the children of other statements are their
+// operands and the children of an expression are its base and offset.
+// Registers, integers and identifiers have no children. The statement a
+// trailing comment belongs to isn't a child of the comment.
+func Inspect(node Node, fn func(Node) bool) {
+	if !fn(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Inspect(child, fn)
+	}
+}
+
+// children returns the child nodes of a node in source order.
+func children(node Node) []Node {
+	switch n := node.(type) {
+	case *LabelStatement:
+		return nodes(n.Ident, n.Reference)
+	case *Expression:
+		if n.Offset == nil {
+			return nodes(n.Base)
+		}
+		return nodes(n.Base, n.Offset)
+	case Statement:
+		return Operands(n)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+Package bench measures the performance of the ARC toolchain on ARC source files.
+It times parsing, formatting and assembling of a program and reports the
+durations and the throughput in lines per second.
+*/
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/lukasmalkmus/arc/build"
+	arcfmt "github.com/lukasmalkmus/arc/fmt"
+	"github.com/lukasmalkmus/arc/parser"
+)
+
+// DefaultIterations is the default number of times every stage is run.
+const DefaultIterations = 100
+
+// Options are configuration values for the benchmark.
+type Options struct {
+	// Iterations is the number of times every stage is run. The reported
+	// durations are the average of all runs. If unset, DefaultIterations is
+	// used.
+	Iterations int
+}
+
+// Result is the result of benchmarking a single file.
+type Result struct {
+	// Filename is the name of the benchmarked file.
+	Filename string
+	// Lines is the number of lines of the file.
+	Lines int
+
+	// Parse, Format and Assemble are the average durations of the respective
+	// stage.
+	Parse    time.Duration
+	Format   time.Duration
+	Assemble time.Duration
+
+	// AssembleErr is the error returned by the assembler. Not every valid
+	// program can be assembled yet, so it doesn't fail the benchmark. The
+	// Assemble duration is zero if it is set.
+	AssembleErr error
+}
+
+// String returns the string representation of the result, one stage per line.
+func (r Result) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s: %d lines\n", r.Filename, r.Lines)
+	fmt.Fprintf(&buf, "\tparse:\t\t%s\n", r.stage(r.Parse))
+	fmt.Fprintf(&buf, "\tformat:\t\t%s\n", r.stage(r.Format))
+	if r.AssembleErr != nil {
+		fmt.Fprintf(&buf, "\tassemble:\tunavailable (%s)", r.AssembleErr)
+	} else {
+		fmt.Fprintf(&buf, "\tassemble:\t%s", r.stage(r.Assemble))
+	}
+	return buf.String()
+}
+
+// stage returns the duration of a stage and the resulting throughput.
+func (r Result) stage(d time.Duration) string {
+	return fmt.Sprintf("%s (%.0f lines/s)", d, Throughput(r.Lines, d))
+}
+
+// Throughput returns the number of lines processed per second.
+func Throughput(lines int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(lines) / d.Seconds()
+}
+
+// File benchmarks the ARC source file. An error is returned if the file can't
+// be read or parsed.
+func File(filename string, options *Options) (Result, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return Result{}, err
+	}
+	res, err := Source(src, options)
+	res.Filename = filename
+	return res, err
+}
+
+// Source benchmarks the ARC source code. An error is returned if the source
+// can't be parsed or formatted.
+func Source(src []byte, options *Options) (Result, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	n := options.Iterations
+	if n <= 0 {
+		n = DefaultIterations
+	}
+
+	res := Result{Lines: bytes.Count(src, []byte("\n"))}
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		res.Lines++
+	}
+
+	// Parse.
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if _, err := parser.New(bytes.NewReader(src)).Parse(); err != nil {
+			return res, err
+		}
+	}
+	res.Parse = time.Since(start) / time.Duration(n)
+
+	// Format. Every run needs a fresh program, so parsing is excluded from
+	// the measurement.
+	for i := 0; i < n; i++ {
+		prog, _ := parser.New(bytes.NewReader(src)).Parse()
+		start = time.Now()
+		if _, err := arcfmt.New(prog, nil).Format(); err != nil {
+			return res, err
+		}
+		res.Format += time.Since(start)
+	}
+	res.Format /= time.Duration(n)
+
+	// Assemble.
+	prog, _ := parser.New(bytes.NewReader(src)).Parse()
+	start = time.Now()
+	for i := 0; i < n; i++ {
+		if _, err := build.New(prog, nil).Assemble(); err != nil {
+			res.AssembleErr = err
+			return res, nil
+		}
+	}
+	res.Assemble = time.Since(start) / time.Duration(n)
+
+	return res, nil
+}
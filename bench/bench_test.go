@@ -0,0 +1,57 @@
+package bench
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestFile validates that benchmarking a valid program reports non-zero
+// timings.
+func TestFile(t *testing.T) {
+	res, err := File("../testdata/valid.arc", &Options{Iterations: 10})
+	ok(t, err)
+	equals(t, "../testdata/valid.arc", res.Filename)
+	equals(t, 12, res.Lines)
+	assert(t, res.Parse > 0, "expected parse timing > 0, got %s", res.Parse)
+	assert(t, res.Format > 0, "expected format timing > 0, got %s", res.Format)
+	assert(t, res.AssembleErr != nil || res.Assemble > 0, "expected assemble timing > 0, got %s", res.Assemble)
+	assert(t, strings.HasPrefix(res.String(), "../testdata/valid.arc: 12 lines\n"), "unexpected output %q", res.String())
+}
+
+// TestSource validates that benchmarking an invalid program fails and that
+// programs which can be assembled report an assemble timing.
+func TestSource(t *testing.T) {
+	_, err := Source([]byte("ld %r1"), nil)
+	assert(t, err != nil, "expected error but got nil")
+
+	res, err := Source([]byte("ld %r1, %r2\nadd %r1, 5, %r3\n"), &Options{Iterations: 1})
+	ok(t, err)
+	equals(t, 2, res.Lines)
+	ok(t, res.AssembleErr)
+	assert(t, res.Assemble > 0, "expected assemble timing > 0, got %s", res.Assemble)
+}
+
+// assert fails the test if the condition is false.
+func assert(tb testing.TB, condition bool, msg string, v ...interface{}) {
+	tb.Helper()
+	if !condition {
+		tb.Fatalf("\033[31m "+msg+"\033[39m\n\n", v...)
+	}
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}
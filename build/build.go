@@ -5,11 +5,17 @@ ARC program and therefore relies on the parser.
 package build
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/lukasmalkmus/arc/ast"
 	"github.com/lukasmalkmus/arc/internal"
@@ -23,6 +29,18 @@ type Options struct {
 	Log io.Writer
 	// Verbose enables more verbose output.
 	Verbose bool
+	// Binary enables packed machine code output. Every instruction is written
+	// as a big-endian 32 bit word instead of a line of ASCII bits, which is
+	// easier to read while debugging.
+	Binary bool
+	// MaxFileSize is the size in bytes source files may not exceed. Larger
+	// files are rejected before parsing them. Zero doesn't limit the size.
+	MaxFileSize int64
+	// Checksum enables writing the SHA-256 checksum of the source file next
+	// to the machine code, to a file named like the machine code with the
+	// extension ".sha256" appended. It is written in the format of sha256sum,
+	// so the source can be verified with "sha256sum -c".
+	Checksum bool
 }
 
 // Assembler assembles ARC source code into machine code. It operates on the AST
@@ -30,11 +48,27 @@ type Options struct {
 type Assembler struct {
 	opts *Options
 	prog *ast.Program
+
+	addrs    map[ast.Statement]int32
+	symbols  ast.SymbolTable
+	overlaps []ast.Overlap
+	consts   map[string]int32
+	externs  map[string]bool
+	relocs   []Relocation
+
+	// relocatable is set while assembling an object. Absolute addresses of
+	// labels are recorded as relocations then.
+	relocatable bool
 }
 
 // New returns a new ARC assembler. It takes the source code as io.Reader as
-// first parameter.
+// first parameter. Pseudo instructions of the program are lowered to real
+// instructions, so the statements of assembled instructions are always real
+// instructions.
 func New(prog *ast.Program, options *Options) *Assembler {
+	if prog != nil {
+		prog = ast.Lower(prog)
+	}
 	a := &Assembler{
 		opts: options,
 		prog: prog,
@@ -48,6 +82,26 @@ func New(prog *ast.Program, options *Options) *Assembler {
 		a.opts.Log = os.Stdout
 	}
 
+	// Lay out the program and collect the addresses of its labels. Overlapping
+	// sections are reported when the program is assembled.
+	a.symbols = make(ast.SymbolTable)
+	a.consts = make(map[string]int32)
+	a.externs = make(map[string]bool)
+	if prog != nil {
+		layout, err := ast.Layout(prog)
+		if lerr, isLayoutErr := err.(*ast.LayoutError); isLayoutErr {
+			a.overlaps = lerr.Overlaps
+		}
+		a.addrs = layout.Addrs
+		a.symbols = layout.Symbols
+		a.consts = prog.Constants()
+		for _, stmt := range prog.Statements {
+			if s, isExtern := stmt.(*ast.ExternStatement); isExtern {
+				a.externs[s.Ident.Name] = true
+			}
+		}
+	}
+
 	return a
 }
 
@@ -67,78 +121,530 @@ func Assemble(src io.Reader, options *Options) ([]byte, error) {
 
 // AssembleFile will transform an ARC source file into machine code. The
 // function takes a filename and an switch for increased verbosity as
-// parameters. It returns an error if assembling fails.
+// parameters. The machine code is written next to the source file, with the
+// extension ".bin" if the binary option is enabled or ".txt" otherwise. It
+// returns an error if assembling fails.
 func AssembleFile(filename string, options *Options) error {
-	// Parse source file.
-	prog, err := parser.ParseFile(filename)
+	ext := filepath.Ext(filename)
+	dest := filename[0 : len(filename)-len(ext)]
+	if options != nil && options.Binary {
+		dest += ".bin"
+	} else {
+		dest += ".txt"
+	}
+	return AssembleFileTo(filename, dest, options)
+}
+
+// AssembleFileTo will transform an ARC source file into machine code and write
+// it to the destination file. The representation of the machine code is
+// inferred from the extension of the destination: ".bin" selects packed words
+// and ".txt" lines of ASCII bits. Other extensions keep the representation
+// selected by the binary option. It returns an error if assembling fails.
+func AssembleFileTo(filename, dest string, options *Options) error {
+	// Select the representation without altering the given options.
+	opts := Options{}
+	if options != nil {
+		opts = *options
+	}
+	switch filepath.Ext(dest) {
+	case ".bin":
+		opts.Binary = true
+	case ".txt":
+		opts.Binary = false
+	}
+
+	// Read and parse source file. The checksum is computed from the same
+	// bytes, so it describes exactly the source which was assembled.
+	src, err := internal.ReadFile(filename, opts.MaxFileSize)
+	if err != nil {
+		return err
+	}
+	prog, err := parser.NewNamed(bytes.NewReader(src), filename).Parse()
 	if err != nil {
 		return err
 	}
 
 	// Assemble source file.
-	asm, err := New(prog, options).Assemble()
+	asm, err := New(prog, &opts).Assemble()
 	if err != nil {
 		return err
 	}
 
-	// Evaluate destination file and write program to file.
-	ext := filepath.Ext(filename)
-	dest := filename[0 : len(filename)-len(ext)]
-	return ioutil.WriteFile(dest, asm, 0644)
+	if err := ioutil.WriteFile(dest, asm, 0644); err != nil {
+		return err
+	}
+	if !opts.Checksum {
+		return nil
+	}
+	line := fmt.Sprintf("%s  %s\n", Checksum(src), filepath.Base(filename))
+	return ioutil.WriteFile(dest+".sha256", []byte(line), 0644)
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of source code. It
+// identifies the exact source the machine code was assembled from.
+func Checksum(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
+
+// Instruction is an assembled instruction. Besides the raw machine word, it
+// carries the decoded fields of the word, its memory address and the statement
+// it was assembled from.
+type Instruction struct {
+	Statement ast.Statement
+	Address   int32
+	Word      uint32
+	Decoded   DecodedInstruction
 }
 
 // Assemble will transform ARC source code into machine code. The function
 // returns the assembled program as a slice of bytes. An error is returned if
-// assembling fails.
+// assembling fails. References to extern symbols are left for the linker to
+// patch and can be retrieved by calling Relocations afterwards.
 func (a *Assembler) Assemble() ([]byte, error) {
-	// Reserve 33 bytes of memory per statement (32bit instruction where one bit
-	// is represented by an ASCII char + 1 byte newline char).
-	prog := make([]byte, 0, len(a.prog.Statements)*33)
+	insts, err := a.AssembleProgram()
+
+	// Binary output packs every instruction into 4 bytes.
+	if a.opts.Binary {
+		prog := make([]byte, len(insts)*4)
+		for i, inst := range insts {
+			binary.BigEndian.PutUint32(prog[i*4:], inst.Word)
+		}
+		return prog, err
+	}
+
+	// Reserve 33 bytes of memory per instruction (32bit instruction where one
+	// bit is represented by an ASCII char + 1 byte newline char).
+	prog := make([]byte, 0, len(insts)*33)
+	for _, inst := range insts {
+		prog = append(prog, fmt.Sprintf("%032b\n", inst.Word)...)
+	}
+
+	return prog, err
+}
+
+// AssembleTo is like Assemble but writes the machine code to w instruction by
+// instruction instead of holding the whole program in memory. Errors of
+// statements which can't be assembled are collected and returned after the
+// remaining statements have been written. A failing write stops assembling
+// immediately and its error is returned.
+func (a *Assembler) AssembleTo(w io.Writer) error {
+	buf := make([]byte, 0, 33)
+	return a.assemble(func(inst Instruction) error {
+		if a.opts.Binary {
+			buf = buf[:4]
+			binary.BigEndian.PutUint32(buf, inst.Word)
+		} else {
+			buf = append(buf[:0], fmt.Sprintf("%032b\n", inst.Word)...)
+		}
+		_, err := w.Write(buf)
+		return err
+	})
+}
+
+// AssembleProgram assembles the program into a sequence of instructions.
+// Statements which don't occupy memory, like comments and directives, are
+// skipped. An error is returned if assembling fails.
+func (a *Assembler) AssembleProgram() ([]Instruction, error) {
+	insts := make([]Instruction, 0, len(a.prog.Statements))
+	err := a.assemble(func(inst Instruction) error {
+		insts = append(insts, inst)
+		return nil
+	})
+	return insts, err
+}
+
+// assemble assembles the program line by line and passes every instruction to
+// emit in the order of the program. Errors of statements which can't be
+// assembled are collected. An error returned by emit is returned immediately.
+func (a *Assembler) assemble(emit func(Instruction) error) error {
 	errs := internal.MultiError{}
+	a.relocs = nil
+
+	// Sections placed on top of each other would overwrite each other.
+	for _, o := range a.overlaps {
+		errs.Add(&AssemblerError{o.String(), o.Statement.Pos()})
+	}
 
-	// Assemble the program line by line.
+	// Instructions referencing undefined labels can't be encoded.
+	unresolved := a.unresolved()
+
+	// Assemble the program line by line. Assertions don't occupy memory but
+	// are checked in place.
 	for _, stmt := range a.prog.Statements {
-		asm, err := a.AssembleStatement(stmt)
+		if s, isAssert := stmt.(*ast.AssertStatement); isAssert {
+			if err := a.assert(s); err != nil {
+				errs.Add(err)
+			}
+			continue
+		}
+		addr, occupies := a.addrs[stmt]
+		if !occupies {
+			continue
+		}
+		if words, isData := dataWords(stmt); isData {
+			for i, word := range words {
+				if err := emit(Instruction{Statement: stmt, Address: addr + int32(i)*4, Word: word, Decoded: Decode(word)}); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err, isUnresolved := unresolved[stmt]; isUnresolved {
+			errs.Add(err)
+			continue
+		}
+		d, err := a.encodeStatement(stmt, addr)
 		if err != nil {
 			errs.Add(err)
 			continue
 		}
-		prog = append(prog, asm...)
-		prog = append(prog, '\n')
+		if err := emit(Instruction{Statement: stmt, Address: addr, Word: d.Encode(), Decoded: d}); err != nil {
+			return err
+		}
 	}
 
-	return prog, errs.Return()
+	return errs.Return()
+}
+
+// Symbols returns the addresses of the labels of the program. They are
+// computed when the assembler is created, so that branches and memory
+// references can be encoded no matter where their target is declared.
+func (a *Assembler) Symbols() ast.SymbolTable {
+	return a.symbols
+}
+
+// SizeOf returns the number of bytes a statement contributes to the assembled
+// program. Instructions contribute 4 bytes, data words 4 bytes per value and
+// labels as much as the statement they reference. Comments, constants,
+// assertions and directives contribute nothing.
+func (a *Assembler) SizeOf(stmt ast.Statement) int {
+	return int(ast.SizeOf(stmt))
+}
+
+// Relocations returns the relocations collected while assembling the program.
+// There is one relocation for every instruction referencing an extern symbol.
+// The relocations are ordered by their position in the source.
+func (a *Assembler) Relocations() []Relocation {
+	return a.relocs
 }
 
 // AssembleStatement will assemble a Statement AST object into ARC assembly.
 func (a *Assembler) AssembleStatement(stmt ast.Statement) ([]byte, error) {
-	// Evaluate which statement to parse.
-	switch stmt.(type) {
+	if words, isData := dataWords(stmt); isData {
+		lines := make([]string, len(words))
+		for i, word := range words {
+			lines[i] = fmt.Sprintf("%032b", word)
+		}
+		return []byte(strings.Join(lines, "\n")), nil
+	}
+	d, err := a.EncodeStatement(stmt)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%032b", d.Encode())), nil
+}
+
+// EncodeStatement will encode a Statement AST object into the fields of an ARC
+// instruction. A pseudo instruction is lowered before it is encoded.
+func (a *Assembler) EncodeStatement(stmt ast.Statement) (DecodedInstruction, error) {
+	if lowered := ast.LowerStatement(stmt); lowered != stmt {
+		return a.encodeStatement(lowered, 0)
+	}
+	return a.encodeStatement(stmt, a.addrs[stmt])
+}
+
+// encodeStatement encodes a statement located at the given address.
+func (a *Assembler) encodeStatement(stmt ast.Statement, addr int32) (DecodedInstruction, error) {
+	// Evaluate which statement to encode.
+	switch s := stmt.(type) {
+	case *ast.LabelStatement:
+		// A label referencing an instruction is assembled as the instruction
+		// itself.
+		if ref, valid := s.Reference.(ast.Statement); valid {
+			return a.encodeStatement(ref, addr)
+		}
 	case *ast.LoadStatement:
-		return a.AssembleLoadStatement(stmt.(*ast.LoadStatement))
+		return a.encodeMemory(stmt, addr, s.Destination, s.Source)
+	case *ast.StoreStatement:
+		return a.encodeMemory(stmt, addr, s.Source, s.Destination)
+	case *ast.BEStatement:
+		return a.encodeBranch(s, s.Target, addr)
+	case *ast.BNEStatement:
+		return a.encodeBranch(s, s.Target, addr)
+	case *ast.BNEGStatement:
+		return a.encodeBranch(s, s.Target, addr)
+	case *ast.BPOSStatement:
+		return a.encodeBranch(s, s.Target, addr)
+	case *ast.BAStatement:
+		return a.encodeBranch(s, s.Target, addr)
+	case *ast.CallStatement:
+		return a.encodeCall(s, addr)
+	case *ast.JumpAndLinkStatement:
+		// The return address is register-indirect, so it is encoded like the
+		// memory location of a memory instruction.
+		return a.encodeMemory(stmt, addr, s.FromAddress, s.ReturnAddress)
+	case ast.InstructionFormat:
+		if s.InstructionFormat() == ast.Arithmetic {
+			return a.encodeArithmetic(stmt)
+		}
 	}
 
-	return nil, &AssemblerError{fmt.Sprintf("no assemble instructions defined for %q", stmt.Tok()), stmt.Pos()}
+	return DecodedInstruction{}, &AssemblerError{fmt.Sprintf("no assemble instructions defined for %q", stmt.Tok()), stmt.Pos()}
 }
 
-// AssembleLoadStatement will assemble a LoadStatement AST object into ARC
-// assembly.
-func (a *Assembler) AssembleLoadStatement(stmt *ast.LoadStatement) ([]byte, error) {
-	asm := make([]byte, 0, 32)
+// assert checks the condition of an assertion. An error is returned if the
+// condition doesn't hold or can't be evaluated.
+func (a *Assembler) assert(stmt *ast.AssertStatement) error {
+	cond := strings.TrimPrefix(stmt.String(), ".assert ")
+	left, err := a.evalConstant(stmt.Left)
+	if err != nil {
+		return err
+	}
+	if stmt.Comparison == "" || stmt.Right == nil {
+		if left == 0 {
+			return &AssemblerError{fmt.Sprintf("assertion %q failed: evaluates to 0", cond), stmt.Pos()}
+		}
+		return nil
+	}
+	right, err := a.evalConstant(stmt.Right)
+	if err != nil {
+		return err
+	}
+
+	var holds bool
+	switch stmt.Comparison {
+	case "==":
+		holds = left == right
+	case "<":
+		holds = left < right
+	case "<=":
+		holds = left <= right
+	case ">":
+		holds = left > right
+	case ">=":
+		holds = left >= right
+	default:
+		return &AssemblerError{fmt.Sprintf("unknown comparison %q", stmt.Comparison), stmt.Pos()}
+	}
+	if !holds {
+		msg := fmt.Sprintf("assertion %q failed: %d %s %d is false", cond, left, stmt.Comparison, right)
+		return &AssemblerError{msg, stmt.Pos()}
+	}
+	return nil
+}
 
-	op, ok := LookupOpCode(stmt)
+// evalConstant computes the value of a constant expression. Identifiers are
+// looked up as constants first and as labels, which evaluate to their address,
+// second. Extern labels have no address until the program is linked and can't
+// be evaluated.
+func (a *Assembler) evalConstant(exp *ast.ConstantExpression) (int64, error) {
+	var res int64
+	for i, op := range exp.Operands {
+		var val int64
+		switch o := op.(type) {
+		case *ast.Integer:
+			val = int64(o.Value)
+		case *ast.Identifier:
+			if c, isConst := a.consts[o.Name]; isConst {
+				val = int64(c)
+			} else if addr, isLabel := a.symbols[o.Name]; isLabel {
+				val = int64(addr)
+			} else if a.externs[o.Name] {
+				return 0, &AssemblerError{fmt.Sprintf("extern label %q can't be evaluated before linking", o.Name), o.Pos()}
+			} else {
+				return 0, &AssemblerError{fmt.Sprintf("undefined label or constant %q", o.Name), o.Pos()}
+			}
+		default:
+			return 0, &AssemblerError{fmt.Sprintf("%q can't be evaluated", op), op.Pos()}
+		}
+		if i > 0 && i <= len(exp.Operators) && exp.Operators[i-1] == "-" {
+			val = -val
+		}
+		res += val
+	}
+	return res, nil
+}
+
+// dataWords returns the machine words of data. Data is either a label
+// referencing an integer or a .word directive, labeled or not. Every word is
+// the 32 bit two's complement representation of its integer.
+func dataWords(stmt ast.Statement) ([]uint32, bool) {
+	switch s := stmt.(type) {
+	case *ast.LabelStatement:
+		if i, valid := s.Reference.(*ast.Integer); valid {
+			return []uint32{uint32(i.Value)}, true
+		}
+		if w, valid := s.Reference.(*ast.WordStatement); valid {
+			return dataWords(w)
+		}
+	case *ast.WordStatement:
+		words := make([]uint32, len(s.Values))
+		for i, val := range s.Values {
+			words[i] = uint32(val.Value)
+		}
+		return words, true
+	}
+	return nil, false
+}
+
+// encodeMemory encodes statements of the memory instruction format and jmpl.
+// The memory location is resolved into the base register rs1 and the offset
+// simm13.
+func (a *Assembler) encodeMemory(stmt ast.Statement, addr int32, reg *ast.Register, memLoc ast.MemoryLocation) (DecodedInstruction, error) {
+	switch loc := memLoc.(type) {
+	case *ast.Register:
+		return a.encodeFormat3(stmt, reg, loc, &ast.Register{Name: "%r0"})
+	case *ast.Expression:
+		// The address of an extern symbol is unknown, so it is resolved to
+		// zero and only the offset is encoded. The linker adds the address of
+		// the symbol. The same applies to any label of an object.
+		symbols := a.symbols
+		if ident, valid := loc.Base.(*ast.Identifier); valid && (a.externs[ident.Name] || a.relocatable) {
+			a.relocs = append(a.relocs, Relocation{Offset: addr, Symbol: ident.Name, Kind: Simm13})
+			symbols = ast.SymbolTable{ident.Name: 0}
+		}
+		res, err := loc.Resolve(symbols)
+		if err != nil {
+			return DecodedInstruction{}, &AssemblerError{err.Error(), stmt.Pos()}
+		}
+		if res.Absolute() && (res.Offset < -4096 || res.Offset > 4095) {
+			return DecodedInstruction{}, &AssemblerError{fmt.Sprintf("address 0x%08x of %s can't be encoded as 13 bit immediate", uint32(res.Offset), loc), stmt.Pos()}
+		}
+		return a.encodeFormat3(stmt, reg, res.Register, &ast.Integer{Value: res.Offset})
+	}
+	return DecodedInstruction{}, &AssemblerError{fmt.Sprintf("invalid memory location %q", memLoc), stmt.Pos()}
+}
+
+// encodeArithmetic encodes statements of the arithmetic instruction format,
+// which operate on a source register and a register or immediate operand and
+// write the result to a destination register. Constants used as operand are
+// substituted by their value.
+func (a *Assembler) encodeArithmetic(stmt ast.Statement) (DecodedInstruction, error) {
+	ops := ast.Operands(stmt)
+	if len(ops) == 3 {
+		rs1, isReg := ops[0].(*ast.Register)
+		rd, isDst := ops[2].(*ast.Register)
+		operand, isOp := ops[1].(ast.Operand)
+		if ident, valid := operand.(*ast.Identifier); valid {
+			val, ok := a.consts[ident.Name]
+			if !ok {
+				return DecodedInstruction{}, &AssemblerError{fmt.Sprintf("undefined constant %q", ident.Name), stmt.Pos()}
+			}
+			operand = &ast.Integer{Value: val}
+		}
+		if isReg && isDst && isOp {
+			return a.encodeFormat3(stmt, rd, rs1, operand)
+		}
+	}
+	return DecodedInstruction{}, &AssemblerError{fmt.Sprintf("invalid operands for %q", stmt.Tok()), stmt.Pos()}
+}
+
+// encodeBranch encodes a branch statement located at the given address. The
+// target is encoded as displacement in words relative to the branch itself,
+// which must fit into the 22 bits of disp22.
+func (a *Assembler) encodeBranch(stmt ast.Statement, target *ast.Identifier, addr int32) (DecodedInstruction, error) {
+	d := DecodedInstruction{Op2: branchOp2}
+
+	format, ok := stmt.(ast.InstructionFormat)
+	if !ok {
+		return d, &AssemblerError{fmt.Sprintf("missing instruction format for %q", stmt.Tok()), stmt.Pos()}
+	}
+	if d.Op, ok = LookupInstructionFormat(format); !ok {
+		return d, &AssemblerError{fmt.Sprintf("missing instruction format in lookup table for %q", stmt.Tok()), stmt.Pos()}
+	}
+	if d.Cond, ok = LookupCondition(stmt); !ok {
+		return d, &AssemblerError{fmt.Sprintf("missing condition in lookup table for %q", stmt.Tok()), stmt.Pos()}
+	}
+
+	dest, ok := a.symbols[target.Name]
+	if !ok {
+		return d, &AssemblerError{fmt.Sprintf("undefined branch target %q", target.Name), stmt.Pos()}
+	}
+	d.Disp22 = (dest - addr) >> 2
+	if d.Disp22 < -1<<21 || d.Disp22 >= 1<<21 {
+		return d, &AssemblerError{fmt.Sprintf("branch target %q is out of range of disp22", target.Name), stmt.Pos()}
+	}
+
+	return d, nil
+}
+
+// encodeCall encodes a call statement located at the given address. The target
+// is encoded as displacement in words relative to the call itself. Calls to
+// extern symbols are encoded with a zero displacement and recorded as
+// relocation.
+func (a *Assembler) encodeCall(stmt *ast.CallStatement, addr int32) (DecodedInstruction, error) {
+	var d DecodedInstruction
+
+	var ok bool
+	if d.Op, ok = LookupInstructionFormat(stmt); !ok {
+		return d, &AssemblerError{fmt.Sprintf("missing instruction format in lookup table for %q", stmt.Tok()), stmt.Pos()}
+	}
+
+	name := stmt.Target.Name
+	if a.externs[name] {
+		a.relocs = append(a.relocs, Relocation{Offset: addr, Symbol: name, Kind: Disp30})
+		return d, nil
+	}
+	target, ok := a.symbols[name]
 	if !ok {
-		return nil, &AssemblerError{fmt.Sprintf("missing operation code in lookup table for %q", stmt.Tok()), stmt.Pos()}
+		return d, &AssemblerError{fmt.Sprintf("undefined identifier %q", name), stmt.Pos()}
 	}
-	asm = append(asm, op...)
+	d.Disp30 = (target - addr) / 4
+
+	return d, nil
+}
+
+// encodeFormat3 encodes statements of the arithmetic and memory instruction
+// formats. The second operand is either encoded as register rs2 or as immediate
+// simm13.
+func (a *Assembler) encodeFormat3(stmt ast.Statement, rd, rs1 *ast.Register, operand ast.Operand) (DecodedInstruction, error) {
+	var d DecodedInstruction
 
-	format, ok := LookupInstructionFormat(stmt)
+	format, ok := stmt.(ast.InstructionFormat)
 	if !ok {
-		return nil, &AssemblerError{fmt.Sprintf("missing instruction format in lookup table for %q", stmt.Tok()), stmt.Pos()}
+		return d, &AssemblerError{fmt.Sprintf("missing instruction format for %q", stmt.Tok()), stmt.Pos()}
+	}
+	if d.Op, ok = LookupInstructionFormat(format); !ok {
+		return d, &AssemblerError{fmt.Sprintf("missing instruction format in lookup table for %q", stmt.Tok()), stmt.Pos()}
+	}
+	if d.Op3, ok = LookupOpCode(stmt); !ok {
+		return d, &AssemblerError{fmt.Sprintf("missing operation code in lookup table for %q", stmt.Tok()), stmt.Pos()}
 	}
-	asm = append(asm, format...)
 
-	return asm, nil
+	var err error
+	if d.Rd, err = registerNumber(stmt, rd); err != nil {
+		return d, err
+	}
+	// Absolute addresses have no base register, so rs1 stays %r0.
+	if rs1 != nil {
+		if d.Rs1, err = registerNumber(stmt, rs1); err != nil {
+			return d, err
+		}
+	}
+	switch op := operand.(type) {
+	case *ast.Register:
+		if d.Rs2, err = registerNumber(stmt, op); err != nil {
+			return d, err
+		}
+	case *ast.Integer:
+		if op.Value < -4096 || op.Value > 4095 {
+			return d, &AssemblerError{fmt.Sprintf("immediate %d doesn't fit into SIMM13", op.Value), stmt.Pos()}
+		}
+		d.I = 1
+		d.Simm13 = op.Value
+	}
+
+	return d, nil
+}
+
+// registerNumber returns the number of a general purpose register.
+func registerNumber(stmt ast.Statement, reg *ast.Register) (uint32, error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(reg.Name, "%r"), 10, 5)
+	if err != nil || !strings.HasPrefix(reg.Name, "%r") {
+		return 0, &AssemblerError{fmt.Sprintf("invalid register %q", reg.Name), stmt.Pos()}
+	}
+	return uint32(n), nil
 }
 
 // log is a helper function providing shorter and faster logging. It only logs
@@ -0,0 +1,585 @@
+package build
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/parser"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// TestAssembleProgram validates the fields of assembled instructions.
+func TestAssembleProgram(t *testing.T) {
+	tests := []struct {
+		src     string
+		decoded DecodedInstruction
+	}{
+		{"add %r1, 5, %r3", DecodedInstruction{Op: 0x2, Rd: 3, Op3: 0x00, Rs1: 1, I: 1, Simm13: 5}},
+		{"add %r1, %r2, %r3", DecodedInstruction{Op: 0x2, Rd: 3, Op3: 0x00, Rs1: 1, Rs2: 2}},
+		{"x: add %r4, 8, %r5", DecodedInstruction{Op: 0x2, Rd: 5, Op3: 0x00, Rs1: 4, I: 1, Simm13: 8}},
+		{"ld [%r1+4], %r2", DecodedInstruction{Op: 0x3, Rd: 2, Op3: 0x00, Rs1: 1, I: 1, Simm13: 4}},
+		{"ld %r1, %r2", DecodedInstruction{Op: 0x3, Rd: 2, Op3: 0x00, Rs1: 1}},
+		{"st %r2, [%r1-4]", DecodedInstruction{Op: 0x3, Rd: 2, Op3: 0x04, Rs1: 1, I: 1, Simm13: -4}},
+		{"ld [%r1-4096], %r2", DecodedInstruction{Op: 0x3, Rd: 2, Op3: 0x00, Rs1: 1, I: 1, Simm13: -4096}},
+		{"jmpl %r15+4, %r0", DecodedInstruction{Op: 0x2, Rd: 0, Op3: 0x38, Rs1: 15, I: 1, Simm13: 4}},
+		{"jmpl [%r15], %r2", DecodedInstruction{Op: 0x2, Rd: 2, Op3: 0x38, Rs1: 15, I: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := parser.New(strings.NewReader(tt.src)).Parse()
+			ok(t, err)
+			insts, err := New(prog, nil).AssembleProgram()
+			ok(t, err)
+			equals(t, 1, len(insts))
+			equals(t, tt.decoded, insts[0].Decoded)
+			equals(t, tt.decoded, Decode(insts[0].Word))
+		})
+	}
+}
+
+// TestAssembleProgram_Data validates that data labels are stored as 32 bit two's
+// complement words.
+func TestAssembleProgram_Data(t *testing.T) {
+	prog, err := parser.New(strings.NewReader(".org 3000\nx: 10\ny: -0xa\nz: -2147483648")).Parse()
+	ok(t, err)
+	insts, err := New(prog, nil).AssembleProgram()
+	ok(t, err)
+	equals(t, 3, len(insts))
+	equals(t, uint32(0x0000000a), insts[0].Word)
+	equals(t, uint32(0xfffffff6), insts[1].Word)
+	equals(t, uint32(0x80000000), insts[2].Word)
+	equals(t, int32(3004), insts[1].Address)
+}
+
+// TestAssembleProgram_Equ validates that constants used as operands are
+// substituted by their value and don't occupy memory.
+func TestAssembleProgram_Equ(t *testing.T) {
+	prog, err := parser.New(strings.NewReader(".equ SIZE, 16\nadd %r1, SIZE, %r2\n.equ NEG, -4\nsubcc %r2, NEG, %r2")).Parse()
+	ok(t, err)
+	insts, err := New(prog, nil).AssembleProgram()
+	ok(t, err)
+	equals(t, 2, len(insts))
+	equals(t, int32(0), insts[0].Address)
+	equals(t, DecodedInstruction{Op: 0x2, Rd: 2, Op3: 0x00, Rs1: 1, I: 1, Simm13: 16}, insts[0].Decoded)
+	equals(t, DecodedInstruction{Op: 0x2, Rd: 2, Op3: 0x14, Rs1: 2, I: 1, Simm13: -4}, insts[1].Decoded)
+}
+
+// TestAssembleProgram_Assert validates that passing assertions don't emit
+// anything and failing ones are reported.
+func TestAssembleProgram_Assert(t *testing.T) {
+	src := ".equ CODE, 2048\n.org 2048\nld [x], %r1\n.org 3000\nx: 5\n"
+	want, err := Assemble(strings.NewReader(src), nil)
+	ok(t, err)
+
+	out, err := Assemble(strings.NewReader(src+".assert x - CODE >= 0x100\n.assert x\n.assert CODE + 4 == 2052"), nil)
+	ok(t, err)
+	equals(t, want, out)
+
+	_, err = Assemble(strings.NewReader(src+".assert x - CODE < 0x100\n.assert x - 3000"), nil)
+	equals(t, "6:1: assertion \"x - CODE < 0x100\" failed: 952 < 256 is false\n7:1: assertion \"x - 3000\" failed: evaluates to 0", err.Error())
+
+	_, err = Assemble(strings.NewReader(".extern y\n.assert y > 0"), nil)
+	equals(t, `2:9: extern label "y" can't be evaluated before linking`, err.Error())
+}
+
+// TestAssembleProgram_Word validates that the word directive emits one word per
+// value, equivalent to labeled integers.
+func TestAssembleProgram_Word(t *testing.T) {
+	prog, err := parser.New(strings.NewReader(".org 3000\nx: .word 1, 2, 3\n.word -1\ny: 10\nld [y], %r1")).Parse()
+	ok(t, err)
+	a := New(prog, nil)
+	insts, err := a.AssembleProgram()
+	ok(t, err)
+
+	var words []uint32
+	var addrs []int32
+	for _, inst := range insts[:5] {
+		words = append(words, inst.Word)
+		addrs = append(addrs, inst.Address)
+	}
+	equals(t, []uint32{1, 2, 3, 0xffffffff, 10}, words)
+	equals(t, []int32{3000, 3004, 3008, 3012, 3016}, addrs)
+	equals(t, ast.SymbolTable{"x": 3000, "y": 3016}, a.Symbols())
+	equals(t, DecodedInstruction{Op: 0x3, Rd: 1, Op3: 0x00, I: 1, Simm13: 3016}, insts[5].Decoded)
+
+	out, err := New(nil, nil).AssembleStatement(prog.Statements[1])
+	ok(t, err)
+	equals(t, "00000000000000000000000000000001\n00000000000000000000000000000010\n00000000000000000000000000000011", string(out))
+}
+
+// TestDecode validates that decoding an encoded instruction yields the original
+// fields for every instruction format.
+func TestDecode(t *testing.T) {
+	tests := []DecodedInstruction{
+		{Op: 0x0, Cond: 0x8, Op2: 0x2, Disp22: -3},
+		{Op: 0x1, Disp30: 1024},
+		{Op: 0x1, Disp30: -1},
+		{Op: 0x2, Rd: 31, Op3: 0x14, Rs1: 17, I: 1, Simm13: -4096},
+		{Op: 0x3, Rd: 1, Op3: 0x04, Rs1: 2, Rs2: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			equals(t, tt, Decode(tt.Encode()))
+		})
+	}
+
+	// add %r1, 5, %r3: 10 00011 000000 00001 1 0000000000101
+	equals(t, uint32(0x86006005), DecodedInstruction{Op: 0x2, Rd: 3, Rs1: 1, I: 1, Simm13: 5}.Encode())
+}
+
+// TestAssemble_Binary validates the packed big-endian output of the binary
+// option.
+func TestAssemble_Binary(t *testing.T) {
+	out, err := Assemble(strings.NewReader("ld [%r1+4], %r2\nst %r2, [%r1-4]"), &Options{Binary: true})
+	ok(t, err)
+	// ld [%r1+4], %r2 is 11 00010 000000 00001 1 0000000000100.
+	equals(t, []byte{0xc4, 0x00, 0x60, 0x04, 0xc4, 0x20, 0x7f, 0xfc}, out)
+}
+
+// TestAssembler_AssembleTo validates that the streamed machine code is
+// identical to the one returned by Assemble.
+func TestAssembler_AssembleTo(t *testing.T) {
+	src := ".begin\n.org 2048\nld [x], %r1\nld [y], %r2\naddcc %r1, %r2, %r3\nbe done\nst %r3, [z]\ndone: st %r0, [y]\njmpl %r15+4, %r0\nx: 15\ny: 9\nz: 0\n.end"
+	for _, binary := range []bool{false, true} {
+		opts := &Options{Binary: binary}
+		prog, err := parser.Parse(src)
+		ok(t, err)
+		want, err := New(prog, opts).Assemble()
+		ok(t, err)
+
+		var buf bytes.Buffer
+		err = New(prog, opts).AssembleTo(&buf)
+		ok(t, err)
+		equals(t, want, buf.Bytes())
+	}
+}
+
+// TestAssembleFile validates the extension of the written machine code.
+func TestAssembleFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arc")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "prog.arc")
+	ok(t, ioutil.WriteFile(src, []byte("ld [%r1+4], %r2"), 0644))
+
+	ok(t, AssembleFile(src, nil))
+	out, err := ioutil.ReadFile(filepath.Join(dir, "prog.txt"))
+	ok(t, err)
+	equals(t, "11000100000000000110000000000100\n", string(out))
+
+	ok(t, AssembleFile(src, &Options{Binary: true}))
+	out, err = ioutil.ReadFile(filepath.Join(dir, "prog.bin"))
+	ok(t, err)
+	equals(t, []byte{0xc4, 0x00, 0x60, 0x04}, out)
+}
+
+// TestAssembleFile_Checksum validates that the checksum written next to the
+// machine code only changes with the source.
+func TestAssembleFile_Checksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arc")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "prog.arc")
+	ok(t, ioutil.WriteFile(src, []byte("ld [%r1+4], %r2"), 0644))
+
+	// Without the option, no checksum is written.
+	ok(t, AssembleFile(src, nil))
+	_, err = os.Stat(filepath.Join(dir, "prog.txt.sha256"))
+	equals(t, true, os.IsNotExist(err))
+
+	checksum := func() string {
+		ok(t, AssembleFile(src, &Options{Checksum: true}))
+		out, err := ioutil.ReadFile(filepath.Join(dir, "prog.txt.sha256"))
+		ok(t, err)
+		return string(out)
+	}
+	first := checksum()
+	equals(t, "8086c7029cdf3ef308c88dd91ef9d45a47c2923b6f59b297b90149d793b93b2c  prog.arc\n", first)
+	equals(t, first, checksum())
+
+	// Changing a single byte changes the checksum.
+	ok(t, ioutil.WriteFile(src, []byte("ld [%r1+8], %r2"), 0644))
+	equals(t, false, checksum() == first)
+	equals(t, "8086c7029cdf3ef308c88dd91ef9d45a47c2923b6f59b297b90149d793b93b2c", Checksum([]byte("ld [%r1+4], %r2")))
+}
+
+// TestAssembleFile_MaxFileSize validates that source files exceeding the size
+// limit are rejected.
+func TestAssembleFile_MaxFileSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arc")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "prog.arc")
+	ok(t, ioutil.WriteFile(src, []byte("ld [%r1+4], %r2"), 0644))
+
+	ok(t, AssembleFile(src, &Options{MaxFileSize: 15}))
+	err = AssembleFile(src, &Options{MaxFileSize: 14})
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	equals(t, src+": file exceeds the size limit of 14 bytes", err.Error())
+}
+
+// TestAssembleFileTo validates that the representation of the machine code is
+// inferred from the extension of the destination file.
+func TestAssembleFileTo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arc")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "prog.arc")
+	ok(t, ioutil.WriteFile(src, []byte("ld [%r1+4], %r2\nst %r2, [%r1+8]\nadd %r1, %r2, %r3"), 0644))
+
+	tests := []struct {
+		dest    string
+		options *Options
+		size    int
+	}{
+		{dest: "out.bin", size: 3 * 4},
+		{dest: "out.txt", options: &Options{Binary: true}, size: 3 * 33},
+		{dest: "out", options: &Options{Binary: true}, size: 3 * 4},
+		{dest: "out.hex", size: 3 * 33},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dest, func(t *testing.T) {
+			dest := filepath.Join(dir, tt.dest)
+			ok(t, AssembleFileTo(src, dest, tt.options))
+			out, err := ioutil.ReadFile(dest)
+			ok(t, err)
+			equals(t, tt.size, len(out))
+		})
+	}
+}
+
+// TestDisassemble validates that disassembling an assembled program yields
+// statements which assemble to the same words again.
+func TestDisassemble(t *testing.T) {
+	src := ".org 2048\nld [x], %r1\nld [%r1+4], %r2\nld %r3, %r4\nst %r2, [%r1-4]\nst %r31, [%r2]\nx: st %r0, %r1"
+	prog, err := parser.New(strings.NewReader(src)).Parse()
+	ok(t, err)
+	code, err := New(prog, nil).Assemble()
+	ok(t, err)
+
+	dis, err := Disassemble(code)
+	ok(t, err)
+	equals(t, "ld [%r0+2068], %r1\nld [%r1+4], %r2\nld %r3, %r4\nst %r2, [%r1-4]\nst %r31, [%r2]\nst %r0, %r1", dis.Statements.String())
+	equals(t, 3, dis.Statements[2].Pos().Line)
+
+	again, err := New(dis, nil).Assemble()
+	ok(t, err)
+	equals(t, string(code), string(again))
+}
+
+// TestDisassemble_Errors validates that words which can't be disassembled are
+// reported with their index.
+func TestDisassemble_Errors(t *testing.T) {
+	tests := []struct {
+		code string
+		err  string
+	}{
+		{"10000110000000000100000000000101\n", "word 0: unsupported instruction format with op 10"},
+		{"11000100000000000100000000000000\n11000100000010000100000000000000\n", "word 1: unknown operation code 000001 of memory instruction"},
+		{"11000100000000000100000000000000\n1100\n", "word 1: expected 32 bits, found 4"},
+		{"11000100000000000000000000000010\n", "word 0: address %r0+%r2 can't be expressed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			_, err := Disassemble([]byte(tt.code))
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			equals(t, tt.err, err.Error())
+		})
+	}
+}
+
+// TestAssembleStatement validates the machine words of assembled statements.
+func TestAssembleStatement(t *testing.T) {
+	tests := []struct {
+		src string
+		out string
+		err string
+	}{
+		// 10 00000 111000 01111 1 0000000000100
+		{src: "jmpl %r15+4, %r0", out: "10000001110000111110000000000100"},
+		{src: "jmpl [%r15+4], %r0", out: "10000001110000111110000000000100"},
+		{src: "jmpl %r15-4096, %r0", out: "10000001110000111111000000000000"},
+		// Arithmetic and logic statements take a register or an immediate.
+		{src: "sub %r1, %r2, %r3", out: "10000110001000000100000000000010"},
+		{src: "addcc %r1, 4095, %r1", out: "10000010100000000110111111111111"},
+		{src: "addcc %r1, -1, %r1", out: "10000010100000000111111111111111"},
+		{src: "xorcc %r4, 0xff, %r5", out: "10001010100110010010000011111111"},
+		{src: "sll %r1, 2, %r2", out: "10000101001010000110000000000010"},
+		{src: "orncc %r0, %r31, %r31", out: "10111110101100000000000000011111"},
+		{src: "and %r1, 4096, %r2", err: "1:1: immediate 4096 doesn't fit into SIMM13"},
+		{src: "or %r1, %r2, %r32", err: `1:1: invalid register "%r32"`},
+		{src: "add %pc, 4, %r1", err: `1:1: invalid register "%pc"`},
+		// Pseudo instructions are assembled as the instruction they stand for.
+		{src: "cmp %r1, 5", out: "10000000101000000110000000000101"},
+		{src: "nop", out: "10000000000000000000000000000000"},
+		{src: "ret", out: "10000001110000111110000000000100"},
+		{src: "mov %r1, %r2", out: "10000100000100000000000000000001"},
+		// Data is stored as two's complement.
+		{src: "x: 25", out: "00000000000000000000000000011001"},
+		{src: "x: -1", out: "11111111111111111111111111111111"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			stmt, err := parser.ParseStatement(tt.src)
+			ok(t, err)
+			out, err := New(nil, nil).AssembleStatement(stmt)
+			if tt.err != "" {
+				equals(t, tt.err, err.Error())
+				return
+			}
+			ok(t, err)
+			equals(t, tt.out, string(out))
+		})
+	}
+}
+
+// TestAssembleProgram_Branch validates the displacements of branches, which
+// are relative to the branch itself.
+func TestAssembleProgram_Branch(t *testing.T) {
+	src := ".org 2048\nloop: subcc %r1, 1, %r1\nbne loop\nba done\nadd %r1, %r2, %r2\ndone: st %r2, [%r0+4]"
+	prog, err := parser.New(strings.NewReader(src)).Parse()
+	ok(t, err)
+	insts, err := New(prog, nil).AssembleProgram()
+	ok(t, err)
+	equals(t, 5, len(insts))
+	equals(t, DecodedInstruction{Op: 0x0, Cond: 0x9, Op2: 0x2, Disp22: -1}, insts[1].Decoded)
+	equals(t, DecodedInstruction{Op: 0x0, Cond: 0x8, Op2: 0x2, Disp22: 2}, insts[2].Decoded)
+	equals(t, uint32(0x10800002), insts[2].Word)
+
+	tests := []struct {
+		src string
+		err string
+	}{
+		{src: ".extern fn\nbe fn", err: `2:1: undefined branch target "fn"`},
+		{src: "bpos far\n.org 0x800000\nfar: add %r1, %r2, %r3", err: `1:1: branch target "far" is out of range of disp22`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := parser.New(strings.NewReader(tt.src)).Parse()
+			ok(t, err)
+			_, err = New(prog, nil).AssembleProgram()
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			equals(t, tt.err, err.Error())
+		})
+	}
+}
+
+// TestAssembleProgram_Unresolved validates that hand-built programs
+// referencing undefined labels are rejected before encoding. The parser would
+// report these programs already.
+func TestAssembleProgram_Unresolved(t *testing.T) {
+	pos := func(line int) token.Pos { return token.Pos{Line: line, Char: 1} }
+	prog := &ast.Program{Statements: []ast.Statement{
+		&ast.LabelStatement{Token: token.IDENT, Position: pos(1), Ident: &ast.Identifier{Name: "loop"}, Reference: &ast.LoadStatement{Token: token.LOAD, Position: pos(1), Source: &ast.Expression{Base: &ast.Identifier{Name: "x"}}, Destination: &ast.Register{Name: "%r1"}}},
+		&ast.BNEStatement{Token: token.BNE, Position: pos(2), Target: &ast.Identifier{Name: "done"}},
+		&ast.CallStatement{Token: token.CALL, Position: pos(3)},
+		&ast.BAStatement{Token: token.BA, Position: pos(4), Target: &ast.Identifier{Name: "loop"}},
+	}}
+
+	_, err := New(prog, nil).AssembleProgram()
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	equals(t, "1:1: undefined label \"x\"\n2:1: undefined label \"done\"\n3:1: missing target of \"call\"", err.Error())
+}
+
+// TestAssembler_Relocations validates that references to extern symbols are
+// recorded as relocations instead of being resolved.
+func TestAssembler_Relocations(t *testing.T) {
+	tests := []struct {
+		src     string
+		relocs  []Relocation
+		decoded []DecodedInstruction
+	}{
+		// Calls to local subroutines are resolved.
+		{
+			src:     "call fn\nld %r1, %r2\nfn: add %r1, 1, %r1",
+			decoded: []DecodedInstruction{{Op: 0x1, Disp30: 2}, {Op: 0x3, Rd: 2, Rs1: 1}, {Op: 0x2, Rd: 1, Rs1: 1, I: 1, Simm13: 1}},
+		},
+		// Calls to extern subroutines are relocated.
+		{
+			src:     ".extern fn\n.org 2048\nld %r1, %r2\ncall fn",
+			relocs:  []Relocation{{Offset: 2052, Symbol: "fn", Kind: Disp30}},
+			decoded: []DecodedInstruction{{Op: 0x3, Rd: 2, Rs1: 1}, {Op: 0x1}},
+		},
+		// Extern data keeps its offset as addend.
+		{
+			src:     ".extern x\nld [x+8], %r1\nst %r1, [x]",
+			relocs:  []Relocation{{Offset: 0, Symbol: "x", Kind: Simm13}, {Offset: 4, Symbol: "x", Kind: Simm13}},
+			decoded: []DecodedInstruction{{Op: 0x3, Rd: 1, I: 1, Simm13: 8}, {Op: 0x3, Rd: 1, Op3: 0x04, I: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := parser.New(strings.NewReader(tt.src)).Parse()
+			ok(t, err)
+			a := New(prog, nil)
+			insts, err := a.AssembleProgram()
+			ok(t, err)
+			equals(t, tt.relocs, a.Relocations())
+			var decoded []DecodedInstruction
+			for _, inst := range insts {
+				decoded = append(decoded, inst.Decoded)
+			}
+			equals(t, tt.decoded, decoded)
+		})
+	}
+}
+
+// TestLink validates linking of objects where one object calls a subroutine
+// exported by another one.
+func TestLink(t *testing.T) {
+	main := ".begin\n.extern sum\n.org 2048\nld %r1, %r2\ncall sum\n.end"
+	lib := ".begin\n.global sum\n.org 2048\nld [sum], %r4\nsum: add %r1, %r2, %r3\njmpl %r15+4, %r0\n.end"
+
+	objs := make([]Object, 0, 2)
+	for _, src := range []string{main, lib} {
+		prog, err := parser.New(strings.NewReader(src)).Parse()
+		ok(t, err)
+		obj, err := New(prog, nil).Object("")
+		ok(t, err)
+		objs = append(objs, obj)
+	}
+	objs[0].Name, objs[1].Name = "main.arc", "lib.arc"
+
+	out, err := Link(objs)
+	ok(t, err)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	equals(t, 5, len(lines))
+
+	// main.arc is placed at 0, lib.arc at 8. The call at 4 jumps two words
+	// ahead to sum at 12.
+	words := make([]DecodedInstruction, len(lines))
+	for i, line := range lines {
+		word, err := strconv.ParseUint(line, 2, 32)
+		ok(t, err)
+		words[i] = Decode(uint32(word))
+	}
+	equals(t, DecodedInstruction{Op: 0x1, Disp30: 2}, words[1])
+	equals(t, DecodedInstruction{Op: 0x3, Rd: 4, I: 1, Simm13: 12}, words[2])
+
+	// Unresolved externs and duplicate globals are reported.
+	objs[0].Relocations = append(objs[0].Relocations, Relocation{Offset: 0, Symbol: "mul", Kind: Disp30})
+	objs = append(objs, objs[1])
+	objs[2].Name = "dup.arc"
+	_, err = Link(objs)
+	equals(t, "dup.arc: duplicate global symbol \"sum\", already exported by lib.arc\nmain.arc: unresolved extern symbol \"mul\"", err.Error())
+}
+
+// TestAssembler_SizeOf validates the number of bytes statements contribute to
+// the assembled program.
+func TestAssembler_SizeOf(t *testing.T) {
+	src := "! code\n.begin\n.org 2048\n.equ SIZE, 3\nld [x], %r1\nmain: add %r1, SIZE, %r2\nnop\nx: 25\n.word 1, 2, 3\ny: .word -1, 1\n.assert y - x == 16\n.end"
+	prog, err := parser.Parse(src)
+	ok(t, err)
+	a := New(prog, nil)
+
+	var sizes []int
+	for _, stmt := range prog.Statements {
+		sizes = append(sizes, a.SizeOf(stmt))
+	}
+	equals(t, []int{0, 0, 0, 0, 4, 4, 4, 4, 12, 8, 0, 0}, sizes)
+}
+
+// TestAssembler_Layout validates that the assembler encodes the array sum
+// sample against the addresses computed by ast.Layout.
+func TestAssembler_Layout(t *testing.T) {
+	prog, err := parser.ParseFile(filepath.Join("..", "testdata", "array_sum.arc"))
+	ok(t, err)
+	layout, err := ast.Layout(prog)
+	ok(t, err)
+	equals(t, layout.Symbols, New(prog, nil).Symbols())
+}
+
+// TestAssembler_SectionLabel validates that a label naming an .org section is
+// bound to the start address of the section.
+func TestAssembler_SectionLabel(t *testing.T) {
+	src := ".begin\n.org 2048\nld [data+4], %r1\ndata: .org 3000\n.word 10, 20\n.end"
+	prog, err := parser.Parse(src)
+	ok(t, err)
+	a := New(prog, nil)
+	equals(t, ast.SymbolTable{"data": 3000}, a.Symbols())
+
+	insts, err := a.AssembleProgram()
+	ok(t, err)
+	equals(t, 3, len(insts))
+	equals(t, int32(3004), insts[0].Decoded.Simm13)
+	equals(t, int32(3000), insts[1].Address)
+	layout, err := ast.Layout(prog)
+	ok(t, err)
+	equals(t, []ast.Section{
+		{Origin: 2048, Code: 4, Data: 0, High: 2051},
+		{Origin: 3000, Code: 0, Data: 8, High: 3007},
+	}, layout.Sections)
+}
+
+// TestAssembler_Symbols validates the addresses of the statements and labels
+// of the sample program, which places its code and data in two .org sections.
+func TestAssembler_Symbols(t *testing.T) {
+	prog, err := parser.ParseFile(filepath.Join("..", "testdata", "valid.arc"))
+	ok(t, err)
+	a := New(prog, nil)
+
+	var addrs []int32
+	for _, stmt := range prog.Statements {
+		if addr, occupies := a.addrs[stmt]; occupies {
+			addrs = append(addrs, addr)
+		}
+	}
+	equals(t, []int32{0x800, 0x804, 0x808, 0x80c, 0x810, 0x1000, 0x1004, 0x1008}, addrs)
+	equals(t, ast.SymbolTable{"main": 0x800, "x": 0x1000, "y": 0x1004, "z": 0x1008}, a.Symbols())
+
+	// An .org section placed into another one is reported.
+	prog, err = parser.New(strings.NewReader(".org 2048\nld %r1, %r2\nld %r1, %r2\n.org 2052\nx: 25")).Parse()
+	ok(t, err)
+	_, err = New(prog, nil).AssembleProgram()
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	equals(t, "5:1: address 0x00000804 already occupied by statement at 3:1", err.Error())
+
+	// Labels beyond the 13 bit immediate range can't be addressed directly.
+	prog, err = parser.New(strings.NewReader(".org 4096\nx: ld [x], %r1")).Parse()
+	ok(t, err)
+	_, err = New(prog, nil).AssembleProgram()
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	equals(t, "2:4: address 0x00001000 of [x] can't be encoded as 13 bit immediate", err.Error())
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}
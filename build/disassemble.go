@@ -0,0 +1,105 @@
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/internal"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// memoryTokens are the statements of the memory instruction format which can
+// be disassembled.
+var memoryTokens = []token.Token{token.LOAD, token.STORE}
+
+// Disassemble reconstructs the statements of a program assembled by Assemble.
+// Every line of the program holds one instruction word in binary. The position
+// of a statement is the line of its word. Only instructions of the memory
+// format are supported so far. An error naming the index of the word is
+// returned for every word which can't be disassembled.
+func Disassemble(prog []byte) (*ast.Program, error) {
+	res := &ast.Program{}
+	errs := internal.MultiError{}
+
+	s := bufio.NewScanner(bytes.NewReader(prog))
+	for i := 0; s.Scan(); i++ {
+		line := s.Text()
+		if len(line) != 32 {
+			errs.Add(fmt.Errorf("word %d: expected 32 bits, found %d", i, len(line)))
+			continue
+		}
+		word, err := strconv.ParseUint(line, 2, 32)
+		if err != nil {
+			errs.Add(fmt.Errorf("word %d: invalid binary word %q", i, line))
+			continue
+		}
+		stmt, err := disassembleWord(uint32(word), token.Pos{Line: i + 1, Char: 1})
+		if err != nil {
+			errs.Add(fmt.Errorf("word %d: %s", i, err))
+			continue
+		}
+		res.Statements = append(res.Statements, stmt)
+	}
+
+	return res, errs.Return()
+}
+
+// disassembleWord reconstructs the statement of a single instruction word. The
+// instruction format is looked up by the op field.
+func disassembleWord(word uint32, pos token.Pos) (ast.Statement, error) {
+	d := Decode(word)
+	if d.Op != InstructionFormats[ast.Memory] {
+		return nil, fmt.Errorf("unsupported instruction format with op %02b", d.Op)
+	}
+
+	var tok token.Token
+	for _, t := range memoryTokens {
+		if OpCodes[t] == d.Op3 {
+			tok = t
+			break
+		}
+	}
+
+	reg := &ast.Register{Token: token.REG, Position: pos, Name: "%r" + strconv.Itoa(int(d.Rd))}
+	memLoc, err := memoryLocation(d, pos)
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case token.LOAD:
+		return &ast.LoadStatement{Token: tok, Position: pos, Source: memLoc, Destination: reg}, nil
+	case token.STORE:
+		return &ast.StoreStatement{Token: tok, Position: pos, Source: reg, Destination: memLoc}, nil
+	}
+	return nil, fmt.Errorf("unknown operation code %06b of memory instruction", d.Op3)
+}
+
+// memoryLocation reconstructs the memory location of a memory instruction. An
+// immediate offset becomes an expression, a register without offset becomes an
+// indirect memory location. Addresses computed from two registers can't be
+// expressed in ARC assembly.
+func memoryLocation(d DecodedInstruction, pos token.Pos) (ast.MemoryLocation, error) {
+	base := &ast.Register{Token: token.REG, Position: pos, Name: "%r" + strconv.Itoa(int(d.Rs1))}
+	if d.I == 0 {
+		if d.Rs2 != 0 {
+			return nil, fmt.Errorf("address %%r%d+%%r%d can't be expressed", d.Rs1, d.Rs2)
+		}
+		return base, nil
+	}
+
+	exp := &ast.Expression{Position: pos, Base: base}
+	if d.Simm13 != 0 {
+		exp.Operator = "+"
+		offset := d.Simm13
+		if offset < 0 {
+			exp.Operator = "-"
+			offset = -offset
+		}
+		lit := strconv.Itoa(int(offset))
+		exp.Offset = &ast.Integer{Token: token.INT, Position: pos, Literal: lit, Value: offset}
+	}
+	return exp, nil
+}
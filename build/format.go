@@ -2,21 +2,22 @@ package build
 
 import "github.com/lukasmalkmus/arc/ast"
 
-// InstructionFormats maps InstructionFormats to their respective operation code.
-var InstructionFormats map[ast.Format][]byte
+// InstructionFormats maps InstructionFormats to their respective operation code
+// (the op field of an instruction).
+var InstructionFormats map[ast.Format]uint32
 
 func init() {
-	InstructionFormats = map[ast.Format][]byte{
-		ast.Branch:     []byte("00"),
-		ast.Sethi:      []byte("00"),
-		ast.Call:       []byte("01"),
-		ast.Arithmetic: []byte("10"),
-		ast.Memory:     []byte("11"),
+	InstructionFormats = map[ast.Format]uint32{
+		ast.Branch:     0x0, // 00
+		ast.Sethi:      0x0, // 00
+		ast.Call:       0x1, // 01
+		ast.Arithmetic: 0x2, // 10
+		ast.Memory:     0x3, // 11
 	}
 }
 
 // LookupInstructionFormat returns the instruction format for a given statement.
-func LookupInstructionFormat(stmt ast.InstructionFormat) ([]byte, bool) {
+func LookupInstructionFormat(stmt ast.InstructionFormat) (uint32, bool) {
 	op, ok := InstructionFormats[stmt.InstructionFormat()]
 	return op, ok
 }
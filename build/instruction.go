@@ -0,0 +1,67 @@
+package build
+
+// DecodedInstruction is the structured representation of an assembled ARC
+// instruction. Every field holds the value of the equally named bit field of
+// the instruction. Which fields are used depends on the instruction format:
+//
+//	Branch:     op | cond | op2 | disp22
+//	Call:       op | disp30
+//	Arithmetic: op | rd | op3 | rs1 | i | simm13 or rs2
+//	Memory:     op | rd | op3 | rs1 | i | simm13 or rs2
+type DecodedInstruction struct {
+	Op     uint32
+	Rd     uint32
+	Op2    uint32
+	Op3    uint32
+	Rs1    uint32
+	I      uint32
+	Simm13 int32
+	Rs2    uint32
+	Disp22 int32
+	Disp30 int32
+	Cond   uint32
+}
+
+// Encode packs the fields into a 32 bit instruction word.
+func (d DecodedInstruction) Encode() uint32 {
+	word := d.Op << 30
+	switch d.Op {
+	case 0x0:
+		word |= d.Cond<<25 | d.Op2<<22 | uint32(d.Disp22)&0x3fffff
+	case 0x1:
+		word |= uint32(d.Disp30) & 0x3fffffff
+	default:
+		word |= d.Rd<<25 | d.Op3<<19 | d.Rs1<<14 | d.I<<13
+		if d.I == 1 {
+			word |= uint32(d.Simm13) & 0x1fff
+		} else {
+			word |= d.Rs2
+		}
+	}
+	return word
+}
+
+// Decode unpacks a 32 bit instruction word into its fields. Signed fields are
+// sign extended.
+func Decode(word uint32) DecodedInstruction {
+	d := DecodedInstruction{Op: word >> 30}
+	switch d.Op {
+	case 0x0:
+		d.Cond = word >> 25 & 0xf
+		d.Op2 = word >> 22 & 0x7
+		d.Disp22 = int32(word<<10) >> 10
+	case 0x1:
+		d.Disp30 = int32(word<<2) >> 2
+	default:
+		d.Rd = word >> 25 & 0x1f
+		d.Op3 = word >> 19 & 0x3f
+		d.Rs1 = word >> 14 & 0x1f
+		d.I = word >> 13 & 0x1
+		if d.I == 1 {
+			d.Simm13 = int32(word<<19) >> 19
+		} else {
+			d.Rs2 = word & 0x1f
+		}
+	}
+	return d
+}
@@ -0,0 +1,174 @@
+package build
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/internal"
+)
+
+// Object is an assembled program which is linked with other objects into a
+// single program. All addresses of an object are relative to its first word.
+type Object struct {
+	// Name identifies the object in error messages, usually it is the
+	// filename of the program.
+	Name string
+	// Words are the machine words of the object. Gaps between sections are
+	// filled with zeros.
+	Words []uint32
+	// Symbols are the addresses of all labels declared by the object.
+	Symbols ast.SymbolTable
+	// Globals are the names of the labels exported by the object.
+	Globals map[string]bool
+	// Relocations are the instructions to patch once the addresses of the
+	// symbols they reference are known.
+	Relocations []Relocation
+}
+
+// Object assembles the program into an object which can be linked with other
+// objects. Unlike assembling the program on its own, memory instructions
+// referencing the absolute address of a label are recorded as relocations,
+// since the address of the label is only known after linking.
+func (a *Assembler) Object(name string) (Object, error) {
+	a.relocatable = true
+	insts, err := a.AssembleProgram()
+	a.relocatable = false
+	if err != nil {
+		return Object{}, err
+	}
+
+	obj := Object{
+		Name:        name,
+		Symbols:     make(ast.SymbolTable),
+		Globals:     make(map[string]bool),
+		Relocations: make([]Relocation, 0, len(a.relocs)),
+	}
+	if len(insts) == 0 {
+		return obj, nil
+	}
+
+	// The object starts at the lowest address of the program.
+	origin := insts[0].Address
+	for _, inst := range insts {
+		if inst.Address < origin {
+			origin = inst.Address
+		}
+	}
+	for _, inst := range insts {
+		i := int((inst.Address - origin) / 4)
+		for len(obj.Words) <= i {
+			obj.Words = append(obj.Words, 0)
+		}
+		obj.Words[i] = inst.Word
+	}
+	for name, addr := range a.symbols {
+		obj.Symbols[name] = addr - origin
+	}
+	for _, reloc := range a.relocs {
+		reloc.Offset -= origin
+		obj.Relocations = append(obj.Relocations, reloc)
+	}
+	for _, stmt := range a.prog.Statements {
+		if s, valid := stmt.(*ast.GlobalStatement); valid {
+			obj.Globals[s.Ident.Name] = true
+		}
+	}
+
+	return obj, nil
+}
+
+// Link links the objects into a single program. The objects are laid out at
+// successive addresses in the given order, starting at address zero. Every
+// relocation is patched with the address of the referenced symbol, which is
+// either declared by the object itself or exported by another object. The
+// program is returned in the same format Assemble produces. An error is
+// returned if an extern symbol isn't exported by any object or if a symbol is
+// exported more than once.
+func Link(objects []Object) ([]byte, error) {
+	errs := internal.MultiError{}
+
+	// Lay out the objects and build the global symbol table.
+	bases := make([]int32, len(objects))
+	globals := make(ast.SymbolTable)
+	owners := make(map[string]string)
+	var size int32
+	for i, obj := range objects {
+		bases[i] = size
+		size += int32(len(obj.Words)) * 4
+		for name := range obj.Globals {
+			if owner, prs := owners[name]; prs {
+				errs.Add(&LinkError{fmt.Sprintf("duplicate global symbol %q, already exported by %s", name, owner), obj.Name})
+				continue
+			}
+			addr, declared := obj.Symbols[name]
+			if !declared {
+				errs.Add(&LinkError{fmt.Sprintf("global symbol %q is not declared", name), obj.Name})
+				continue
+			}
+			globals[name] = bases[i] + addr
+			owners[name] = obj.Name
+		}
+	}
+
+	// Copy the words and patch the relocations.
+	words := make([]uint32, 0, size/4)
+	for i, obj := range objects {
+		start := len(words)
+		words = append(words, obj.Words...)
+		for _, reloc := range obj.Relocations {
+			target, prs := obj.Symbols[reloc.Symbol]
+			if prs {
+				target += bases[i]
+			} else if target, prs = globals[reloc.Symbol]; !prs {
+				errs.Add(&LinkError{fmt.Sprintf("unresolved extern symbol %q", reloc.Symbol), obj.Name})
+				continue
+			}
+			idx := start + int(reloc.Offset/4)
+			word, err := patch(words[idx], reloc, bases[i]+reloc.Offset, target)
+			if err != nil {
+				errs.Add(&LinkError{err.Error(), obj.Name})
+				continue
+			}
+			words[idx] = word
+		}
+	}
+	if err := errs.Return(); err != nil {
+		return nil, err
+	}
+
+	prog := make([]byte, 0, len(words)*33)
+	for _, word := range words {
+		prog = append(prog, fmt.Sprintf("%032b\n", word)...)
+	}
+	return prog, nil
+}
+
+// patch adds the address of the target to the field of the word given by the
+// kind of the relocation. The word is located at the address addr.
+func patch(word uint32, reloc Relocation, addr, target int32) (uint32, error) {
+	d := Decode(word)
+	switch reloc.Kind {
+	case Disp30:
+		d.Disp30 += (target - addr) / 4
+	case Simm13:
+		d.Simm13 += target
+		if d.Simm13 < -4096 || d.Simm13 > 4095 {
+			return 0, fmt.Errorf("address %d of symbol %q doesn't fit into SIMM13", d.Simm13, reloc.Symbol)
+		}
+	default:
+		return 0, fmt.Errorf("invalid relocation kind %s for symbol %q", reloc.Kind, reloc.Symbol)
+	}
+	return d.Encode(), nil
+}
+
+// LinkError represents an error that occurred during linking.
+type LinkError struct {
+	Message string
+	Object  string
+}
+
+// Error returns the string representation of the error. It implements the error
+// interface.
+func (e LinkError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Object, e.Message)
+}
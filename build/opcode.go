@@ -6,17 +6,57 @@ import (
 	"github.com/lukasmalkmus/arc/token"
 )
 
-// OpCodes maps lexical tokens to their respective operation code.
-var OpCodes map[token.Token][]byte
+// OpCodes maps lexical tokens to their respective operation code (the op3 field
+// of memory and arithmetic instructions).
+var OpCodes map[token.Token]uint32
 
 func init() {
-	OpCodes = map[token.Token][]byte{
-		token.LOAD: []byte("000000"),
+	OpCodes = map[token.Token]uint32{
+		token.LOAD:  0x00, // 000000
+		token.STORE: 0x04, // 000100
+		token.ADD:   0x00, // 000000
+		token.ADDCC: 0x10, // 010000
+		token.SUB:   0x04, // 000100
+		token.SUBCC: 0x14, // 010100
+		token.AND:   0x01, // 000001
+		token.ANDCC: 0x11, // 010001
+		token.OR:    0x02, // 000010
+		token.ORCC:  0x12, // 010010
+		token.ORN:   0x06, // 000110
+		token.ORNCC: 0x16, // 010110
+		token.XOR:   0x03, // 000011
+		token.XORCC: 0x13, // 010011
+		token.SLL:   0x25, // 100101
+		token.SRA:   0x27, // 100111
+		token.JMPL:  0x38, // 111000
+	}
+}
+
+// Conditions maps lexical tokens of branch statements to their respective
+// condition (the cond field of branch instructions).
+var Conditions map[token.Token]uint32
+
+// branchOp2 is the op2 field of branch instructions.
+const branchOp2 = 0x2 // 010
+
+func init() {
+	Conditions = map[token.Token]uint32{
+		token.BE:   0x1, // 0001
+		token.BNEG: 0x6, // 0110
+		token.BA:   0x8, // 1000
+		token.BNE:  0x9, // 1001
+		token.BPOS: 0xe, // 1110
 	}
 }
 
 // LookupOpCode returns the operation code for a given statement.
-func LookupOpCode(stmt ast.Statement) ([]byte, bool) {
+func LookupOpCode(stmt ast.Statement) (uint32, bool) {
 	op, ok := OpCodes[stmt.Tok()]
 	return op, ok
 }
+
+// LookupCondition returns the condition for a given branch statement.
+func LookupCondition(stmt ast.Statement) (uint32, bool) {
+	cond, ok := Conditions[stmt.Tok()]
+	return cond, ok
+}
@@ -0,0 +1,37 @@
+package build
+
+// RelocationKind is the kind of instruction field a relocation patches.
+type RelocationKind int
+
+// All kinds of relocations.
+const (
+	// Disp30 relocations patch the word displacement of a call instruction
+	// with the distance from the instruction to the symbol.
+	Disp30 RelocationKind = iota + 1
+	// Simm13 relocations patch the immediate of a memory instruction with the
+	// absolute address of the symbol.
+	Simm13
+)
+
+func (k RelocationKind) String() string {
+	switch k {
+	case Disp30:
+		return "disp30"
+	case Simm13:
+		return "simm13"
+	}
+	return "unknown"
+}
+
+// Relocation marks an instruction referencing a symbol whose address is unknown
+// at assemble time because it is declared extern. The field of the instruction
+// given by the kind holds the addend which is added to the resolved address of
+// the symbol.
+type Relocation struct {
+	// Offset is the address of the instruction to patch.
+	Offset int32
+	// Symbol is the name of the referenced symbol.
+	Symbol string
+	// Kind is the kind of the patched instruction field.
+	Kind RelocationKind
+}
@@ -0,0 +1,76 @@
+package build
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// unresolved checks that every label referenced by an instruction is defined
+// by the program or declared extern. The parser reports undefined labels
+// already, but programs built by hand bypass it. An error is returned for
+// every instruction referencing an undefined label or missing its target, so
+// it isn't encoded.
+func (a *Assembler) unresolved() map[ast.Statement]error {
+	errs := make(map[ast.Statement]error)
+	for _, stmt := range a.prog.Statements {
+		ident, references := reference(stmt)
+		if !references {
+			continue
+		}
+		if ident == nil {
+			errs[stmt] = &AssemblerError{fmt.Sprintf("missing target of %q", instruction(stmt).Tok()), stmt.Pos()}
+			continue
+		}
+		if _, defined := a.symbols[ident.Name]; !defined && !a.externs[ident.Name] {
+			errs[stmt] = &AssemblerError{fmt.Sprintf("undefined label %q", ident.Name), stmt.Pos()}
+		}
+	}
+	return errs
+}
+
+// reference returns the label an instruction references and whether the
+// instruction references a label at all. The label is the target of branches
+// and calls and the base of memory locations. Nil is returned for an
+// instruction which should reference a label but doesn't.
+func reference(stmt ast.Statement) (*ast.Identifier, bool) {
+	switch s := instruction(stmt).(type) {
+	case *ast.BEStatement:
+		return s.Target, true
+	case *ast.BNEStatement:
+		return s.Target, true
+	case *ast.BNEGStatement:
+		return s.Target, true
+	case *ast.BPOSStatement:
+		return s.Target, true
+	case *ast.BAStatement:
+		return s.Target, true
+	case *ast.CallStatement:
+		return s.Target, true
+	case *ast.LoadStatement:
+		return base(s.Source)
+	case *ast.StoreStatement:
+		return base(s.Destination)
+	}
+	return nil, false
+}
+
+// base returns the label a memory location is computed from, if any.
+func base(memLoc ast.MemoryLocation) (*ast.Identifier, bool) {
+	if exp, valid := memLoc.(*ast.Expression); valid {
+		ident, isIdent := exp.Base.(*ast.Identifier)
+		return ident, isIdent
+	}
+	return nil, false
+}
+
+// instruction returns the statement a label references or the statement
+// itself.
+func instruction(stmt ast.Statement) ast.Statement {
+	if label, valid := stmt.(*ast.LabelStatement); valid {
+		if ref, valid := label.Reference.(ast.Statement); valid {
+			return ref
+		}
+	}
+	return stmt
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/bench"
+	"github.com/lukasmalkmus/arc/internal"
+	"github.com/spf13/cobra"
+)
+
+var benchOpts bench.Options
+
+// benchCmd represents the bench command.
+var benchCmd = &cobra.Command{
+	Use:   "bench [files...]",
+	Short: "Time parsing, formatting and assembling of ARC source code",
+	Long: `Bench times parsing, formatting and assembling of ARC
+source files and reports the durations and the throughput
+in lines per second. Every stage is run multiple times and
+the average is reported. Programs which can't be assembled
+yet are reported without an assemble timing.
+
+Every argument to this command is expected to be a valid
+ARC source file. Passing no argument will benchmark every
+single file in the current directory having the .arc file
+extension.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		files := args
+		if len(files) == 0 {
+			var err error
+			if files, err = internal.ReadCurDir(); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+
+		for _, file := range files {
+			// If an argument is a directory, ignore it.
+			if is, _ := internal.IsDirectory(file); is {
+				continue
+			}
+
+			res, err := bench.File(file, &benchOpts)
+			if err != nil {
+				printError(err)
+				continue
+			}
+			fmt.Println(res)
+		}
+	},
+	SuggestFor: []string{"benchmark", "time"},
+}
+
+func init() {
+	RootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().IntVarP(&benchOpts.Iterations, "iterations", "n", bench.DefaultIterations, "number of runs per stage")
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var buildOpts build.Options
+var (
+	buildOpts   build.Options
+	buildOutput string
+)
 
 // buildCmd represents the build command.
 var buildCmd = &cobra.Command{
@@ -22,8 +25,33 @@ location 2048. Consider using the .org directive for this.
 Every argument to this command is expected to be a valid
 ARC source file. Passing no argument will assemble every
 single file having the .arc file extension in the current
-directory.`,
+directory.
+
+The machine code is written next to every source file. It
+is written as lines of ASCII bits to a .txt file or, with
+the --binary flag, as packed 32 bit words to a .bin file.
+The --output flag writes the machine code of a single
+source file to the given file instead. Its extension
+selects the representation: .bin for packed words and .txt
+for ASCII bits.
+
+The --checksum flag writes the SHA-256 checksum of every
+source file next to its machine code, to a file with the
+.sha256 extension appended. It can be verified with
+"sha256sum -c".`,
 	Run: func(cmd *cobra.Command, args []string) {
+		// Assemble a single file to the requested destination.
+		if buildOutput != "" {
+			if len(args) != 1 {
+				fmt.Println("\033[31mThe output flag requires exactly one source file\033[39m")
+				return
+			}
+			if err := build.AssembleFileTo(args[0], buildOutput, &buildOpts); err != nil {
+				fmt.Printf("\033[31m%s\033[39m\n", err)
+			}
+			return
+		}
+
 		// Assemble every file given.
 		if len(args) > 0 {
 			for _, file := range args {
@@ -58,4 +86,7 @@ func init() {
 	RootCmd.AddCommand(buildCmd)
 
 	buildCmd.Flags().BoolVarP(&buildOpts.Verbose, "verbose", "v", false, "print more build details")
+	buildCmd.Flags().BoolVarP(&buildOpts.Binary, "binary", "b", false, "write packed machine code instead of ASCII bits")
+	buildCmd.Flags().BoolVar(&buildOpts.Checksum, "checksum", false, "write the SHA-256 checksum of the source next to the machine code")
+	buildCmd.Flags().StringVarP(&buildOutput, "output", "o", "", "write the machine code of a single source file to this file")
 }
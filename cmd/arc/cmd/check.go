@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/internal"
+	"github.com/lukasmalkmus/arc/vet"
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the check command.
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report parse errors and suspicious constructs of ARC source code",
+	Long: `Check parses ARC source code and examines it for suspicious
+language constructs. Parse errors and the results of vet
+are reported together, sorted by the source code position
+they apply to. It answers the question whether a program is
+OK in a single run.
+
+Every argument to this command is expected to be a valid
+ARC source file. Passing no argument will check every
+single file in the current directory having the .arc file
+extension.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check every file given.
+		if len(args) > 0 {
+			for _, file := range args {
+				// If an argument is a directory, ignore it.
+				if is, _ := internal.IsDirectory(file); is {
+					continue
+				}
+
+				res, err := vet.DiagnoseFile(file, nil)
+				if err != nil {
+					printError(err)
+				}
+				printVetResult(res)
+			}
+			return
+		}
+
+		// Read all files in current directory and check them.
+		files, err := internal.ReadCurDir()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, file := range files {
+			res, err := vet.DiagnoseFile(file, nil)
+			if err != nil {
+				printError(err)
+			}
+			printVetResult(res)
+		}
+	},
+	SuggestFor: []string{"lint"},
+}
+
+func init() {
+	RootCmd.AddCommand(checkCmd)
+}
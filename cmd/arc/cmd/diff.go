@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/diff"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command.
+var diffCmd = &cobra.Command{
+	Use:   "diff [old file] [new file]",
+	Short: "Compare two ARC programs semantically",
+	Long: `Diff parses two ARC source files and reports the semantic
+differences between them. Added, removed and changed
+instructions are reported per statement. Differences in
+formatting, the notation of integers and comments are
+ignored.
+
+This is useful to review whether reformatting a program
+changed its behaviour.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			printError(fmt.Errorf("diff requires exactly two files, got %d", len(args)))
+			return
+		}
+
+		changes, err := diff.DiffFiles(args[0], args[1])
+		if err != nil {
+			printError(err)
+			return
+		}
+		fmt.Println(changes)
+	},
+	SuggestFor: []string{"compare"},
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+}
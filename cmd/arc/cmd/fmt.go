@@ -2,12 +2,20 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	arcfmt "github.com/lukasmalkmus/arc/fmt"
 	"github.com/lukasmalkmus/arc/internal"
 	"github.com/spf13/cobra"
 )
 
+var (
+	fmtOpts           arcfmt.Options
+	fmtCommentSpacing string
+	fmtDiff           bool
+	fmtList           bool
+)
+
 // fmtCmd represents the fmt command.
 var fmtCmd = &cobra.Command{
 	Use:   "fmt",
@@ -18,33 +26,82 @@ specification and best practices.
 Every argument to this command is expected to be a valid
 ARC source file. Passing no argument will format every
 single file in the current directory having the .arc file
-extension.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		// Format every file given.
-		if len(args) > 0 {
-			for _, file := range args {
-				// If an argument is a directory, ignore it.
-				if is, _ := internal.IsDirectory(file); is {
-					continue
-				}
+extension.
 
-				if err := arcfmt.FormatFile(file); err != nil {
-					printError(err)
-				}
-			}
-			return
-		}
+The "--simplify" ("-s") flag applies semantics-preserving
+simplifications, like dropping zero offsets in expressions
+([%r1+0] becomes [%r1]), rewriting octal integers as
+decimals and removing instructions without any effect.
+
+The "--preserve-comments" ("-c") flag keeps the text of
+comments verbatim, including trailing whitespace and a
+missing space after the leading "!".
+
+The "--comment-spacing" flag sets the space after the
+leading "!" of comments: "single" for "! comment", "none"
+for "!comment" or "keep" to leave it as is.
 
-		// Read all files in current directory and format them.
-		files, err := internal.ReadCurDir()
+The "--indent" flag indents statements by the given number
+of spaces, with labels starting in the first column. The
+"--align-comments" flag aligns the trailing comments of
+consecutive lines into a column. The "--upper-case" flag
+renders the mnemonics of instructions in upper case.
+
+The "--diff" ("-d") flag prints a unified diff between the
+source and the formatted program instead of rewriting the
+file. The "--list" ("-l") flag prints the names of the
+files which aren't formatted instead. With either flag,
+the command exits with a non-zero status if a file isn't
+formatted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		spacing, err := arcfmt.ParseCommentSpacing(fmtCommentSpacing)
 		if err != nil {
-			fmt.Println(err)
+			printError(err)
 			return
 		}
+		fmtOpts.CommentSpacing = spacing
+
+		// Format every file given or all files in the current directory.
+		files := args
+		if len(files) == 0 {
+			if files, err = internal.ReadCurDir(); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+
+		unformatted := false
 		for _, file := range files {
-			if err := arcfmt.FormatFile(file); err != nil {
+			// If an argument is a directory, ignore it.
+			if is, _ := internal.IsDirectory(file); is {
+				continue
+			}
+
+			if !fmtDiff && !fmtList {
+				if err := arcfmt.FormatFile(file, &fmtOpts); err != nil {
+					printError(err)
+				}
+				continue
+			}
+
+			diff, err := arcfmt.DiffFile(file, &fmtOpts)
+			if err != nil {
 				printError(err)
+				continue
+			}
+			if len(diff) == 0 {
+				continue
+			}
+			unformatted = true
+			if fmtList {
+				fmt.Println(file)
 			}
+			if fmtDiff {
+				os.Stdout.Write(diff)
+			}
+		}
+		if unformatted {
+			os.Exit(1)
 		}
 	},
 	SuggestFor: []string{"format"},
@@ -52,4 +109,13 @@ extension.`,
 
 func init() {
 	RootCmd.AddCommand(fmtCmd)
+
+	fmtCmd.Flags().BoolVarP(&fmtOpts.Simplify, "simplify", "s", false, "simplify code")
+	fmtCmd.Flags().BoolVarP(&fmtOpts.PreserveComments, "preserve-comments", "c", false, "keep comments verbatim")
+	fmtCmd.Flags().IntVar(&fmtOpts.IndentWidth, "indent", 0, "number of spaces statements are indented by")
+	fmtCmd.Flags().BoolVar(&fmtOpts.AlignComments, "align-comments", false, "align trailing comments of consecutive lines")
+	fmtCmd.Flags().BoolVar(&fmtOpts.UpperCaseMnemonics, "upper-case", false, "render mnemonics of instructions in upper case")
+	fmtCmd.Flags().BoolVarP(&fmtDiff, "diff", "d", false, "print diffs instead of rewriting files")
+	fmtCmd.Flags().BoolVarP(&fmtList, "list", "l", false, "list files which aren't formatted instead of rewriting them")
+	fmtCmd.Flags().StringVar(&fmtCommentSpacing, "comment-spacing", arcfmt.KeepSpacing.String(), "space after \"!\" of comments (keep, single, none)")
 }
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lukasmalkmus/arc/internal"
+	"github.com/spf13/cobra"
+)
+
+// newCmd represents the new command.
+var newCmd = &cobra.Command{
+	Use:   "new [name]",
+	Short: "Create a skeleton ARC program",
+	Long: `New writes a minimal but complete ARC program to the
+file with the given name. The ".arc" extension is added if
+the name doesn't have it. Existing files are never
+overwritten.
+
+The program declares its beginning and end with .begin and
+.end, starts the code at address 2048 with the exported
+"main" label and returns to the caller when done. It passes all checks
+of the vet command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			printError(fmt.Errorf("new requires exactly one name, got %d", len(args)))
+			return
+		}
+
+		filename := args[0]
+		if !internal.IsArcFile(filename) {
+			filename += ".arc"
+		}
+		f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			printError(err)
+			return
+		}
+		defer f.Close()
+
+		if _, err := f.Write(internal.Skeleton(filename)); err != nil {
+			printError(err)
+			return
+		}
+		fmt.Printf("Created %s\n", filename)
+	},
+	SuggestFor: []string{"init", "create"},
+}
+
+func init() {
+	RootCmd.AddCommand(newCmd)
+}
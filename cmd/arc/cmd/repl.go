@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/lukasmalkmus/arc/parser"
+	"github.com/lukasmalkmus/arc/simulator"
 	"github.com/lukasmalkmus/interactive"
 	"github.com/spf13/cobra"
 )
@@ -22,11 +23,19 @@ var replCmd = &cobra.Command{
 mode, takes an input string from Stdin and tries to parse
 it into an ARC statement. Parser errors will be printed to
 Stdout. Pseudo operations "exit" and "quit" are supported
-and will stop the interactive mode.`,
+and will stop the interactive mode. The pseudo operation
+"print" evaluates an expression like "%r1 + 4" or "[x]"
+against the simulator state and prints its value. The
+pseudo operation "reset" resets the simulator. Given a
+register like "reset %r5", only that register is zeroed,
+"reset flags" clears only the condition codes.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Init parser.
 		p := parser.New(strings.NewReader(""))
 
+		// Init simulator used for evaluating expressions.
+		sim := simulator.New(nil)
+
 		// Create new session.
 		session := interactive.New(">")
 
@@ -48,6 +57,25 @@ and will stop the interactive mode.`,
 				c.Close(0)
 			}
 
+			// Evaluate expressions the user wants to print.
+			if strings.HasPrefix(text, "print ") {
+				val, err := sim.Eval(strings.TrimPrefix(text, "print "))
+				if err != nil {
+					c.Printf("\033[31m%s\033[39m\n", err)
+					return nil
+				}
+				c.Println(val)
+				return nil
+			}
+
+			// Reset the simulator or a part of it.
+			if text == "reset" || strings.HasPrefix(text, "reset ") {
+				if err := sim.ResetTarget(strings.TrimPrefix(text, "reset")); err != nil {
+					c.Printf("\033[31m%s\033[39m\n", err)
+				}
+				return nil
+			}
+
 			// Parse actual input. If evaluation fails print the error. Break
 			// action if no statement was parsed (but the error is nil).
 			p.Feed(text)
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/parser"
+	"github.com/spf13/cobra"
+)
+
+// sizeCmd represents the size command.
+var sizeCmd = &cobra.Command{
+	Use:   "size [files...]",
+	Short: "Report the code and data sizes of ARC programs",
+	Long: `Size reports the memory occupied by ARC programs. For every
+.org section the bytes of code, the bytes of data and the
+highest address used are listed, followed by the totals of
+the program. Statements before the first .org directive are
+placed at address zero.
+
+This is useful to check whether a program fits into the
+memory of the target.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			printError(fmt.Errorf("size requires at least one file"))
+			return
+		}
+
+		for _, file := range args {
+			prog, err := parser.ParseFile(file)
+			if err != nil {
+				printError(err)
+				continue
+			}
+			layout, _ := ast.Layout(prog)
+			printSections(file, layout.Sections)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(sizeCmd)
+}
+
+// printSections prints the sections of a program as table.
+func printSections(file string, secs []ast.Section) {
+	fmt.Println(file)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "origin\tcode\tdata\thigh\t")
+	var code, data int32
+	for _, sec := range secs {
+		fmt.Fprintf(w, "0x%08x\t%d\t%d\t0x%08x\t\n", uint32(sec.Origin), sec.Code, sec.Data, uint32(sec.High))
+		code += sec.Code
+		data += sec.Data
+	}
+	fmt.Fprintf(w, "total\t%d\t%d\t\t\n", code, data)
+	w.Flush()
+}
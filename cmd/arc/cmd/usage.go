@@ -12,6 +12,8 @@ Directives:
 ".begin"
 ".end"
 ".org"
+".global"
+".extern"
 
 Memory:
 "ld"
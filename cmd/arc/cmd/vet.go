@@ -28,6 +28,10 @@ according to the source code position they apply to. By
 default, results are ordered after the execution order of
 the different checks.
 
+The "--forbid-pseudo" flag sets the pseudo instructions the
+"nopseudo" check reports, like "mov" or "cmp". By default,
+no pseudo instruction is forbidden.
+
 Every argument to this command is expected to be a valid
 ARC source file. Passing no argument will vet every single
 file in the current directory having the .arc file extension.`,
@@ -94,4 +98,6 @@ func init() {
 	vetCmd.Flags().BoolVarP(&list, "list", "l", false, "list available checks")
 	vetCmd.Flags().BoolVarP(&vetOpts.Sort, "sort", "s", false, "sort results according to the source code position they apply to")
 	vetCmd.Flags().StringSliceVar(&vetOpts.Checks, "enable", []string{}, "enable a specific check")
+	vetCmd.Flags().StringSliceVar(&vetOpts.ForbiddenPseudo, "forbid-pseudo", []string{}, "pseudo instructions reported by the nopseudo check")
+	vetCmd.Flags().StringVar(&vetOpts.LabelPattern, "label-pattern", "", "regular expression labels must match to pass the naming check")
 }
@@ -0,0 +1,244 @@
+/*
+Package diff compares ARC programs semantically. It operates on the AST of the
+programs and therefore relies on the parser. Formatting differences, like
+whitespace or the notation of integers, and comments are ignored.
+*/
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/parser"
+)
+
+// Op is the kind of a change.
+type Op int
+
+// All kinds of changes.
+const (
+	// Added marks a statement which is only present in the new program.
+	Added Op = iota + 1
+	// Removed marks a statement which is only present in the old program.
+	Removed
+	// Changed marks a statement of the old program which has been replaced
+	// by a statement of the new program.
+	Changed
+)
+
+// Change is a semantic difference between two programs.
+type Change struct {
+	Op Op
+	// Old is the statement of the old program. It is nil for added
+	// statements.
+	Old ast.Statement
+	// New is the statement of the new program. It is nil for removed
+	// statements.
+	New ast.Statement
+}
+
+// String returns the string representation of the change.
+func (c Change) String() string {
+	switch c.Op {
+	case Added:
+		return fmt.Sprintf("%s: added %q", c.New.Pos(), c.New)
+	case Removed:
+		return fmt.Sprintf("%s: removed %q", c.Old.Pos(), c.Old)
+	case Changed:
+		return fmt.Sprintf("%s: changed %q to %q", c.Old.Pos(), c.Old, c.New)
+	}
+	return ""
+}
+
+// Changes are the semantic differences between two programs.
+type Changes []Change
+
+// String returns the string representation of the changes, one change per line.
+func (c Changes) String() string {
+	if len(c) == 0 {
+		return "no semantic difference"
+	}
+	var buf bytes.Buffer
+	for i, change := range c {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(change.String())
+	}
+	return buf.String()
+}
+
+// DiffFiles parses two ARC source files and returns the semantic differences
+// of the second one compared to the first one. An error is returned if one of
+// the files can't be parsed.
+func DiffFiles(from, to string) (Changes, error) {
+	a, err := parser.ParseFile(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := parser.ParseFile(to)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(a, b), nil
+}
+
+// Diff returns the semantic differences of the second program compared to the
+// first one. Statements are matched by computing the longest common subsequence
+// of both programs. Removals directly followed by additions are reported as
+// changes.
+func Diff(from, to *ast.Program) Changes {
+	a, b := statements(from), statements(to)
+	ka, kb := keys(a), keys(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of ka[i:] and
+	// kb[j:].
+	lcs := make([][]int, len(ka)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(kb)+1)
+	}
+	for i := len(ka) - 1; i >= 0; i-- {
+		for j := len(kb) - 1; j >= 0; j-- {
+			if ka[i] == kb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var changes Changes
+	var removed, added []ast.Statement
+	flush := func() {
+		n := len(removed)
+		if len(added) < n {
+			n = len(added)
+		}
+		for k := 0; k < n; k++ {
+			changes = append(changes, Change{Op: Changed, Old: removed[k], New: added[k]})
+		}
+		for _, stmt := range removed[n:] {
+			changes = append(changes, Change{Op: Removed, Old: stmt})
+		}
+		for _, stmt := range added[n:] {
+			changes = append(changes, Change{Op: Added, New: stmt})
+		}
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(ka) || j < len(kb) {
+		switch {
+		case i < len(ka) && j < len(kb) && ka[i] == kb[j]:
+			flush()
+			i++
+			j++
+		case j == len(kb) || (i < len(ka) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	flush()
+
+	return changes
+}
+
+// statements returns the statements of a program without comments.
+func statements(prog *ast.Program) []ast.Statement {
+	stmts := make([]ast.Statement, 0, len(prog.Statements))
+	for _, stmt := range prog.Statements {
+		if _, isComment := stmt.(*ast.CommentStatement); isComment {
+			continue
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// keys returns the canonical string representation of every statement.
+func keys(stmts []ast.Statement) []string {
+	keys := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		keys[i] = canonical(stmt)
+	}
+	return keys
+}
+
+// canonical returns the string representation of a statement with all integers
+// written as decimals. This way, statements only differing in the notation of
+// their integers compare equal.
+func canonical(stmt ast.Statement) string {
+	ints := integers(stmt)
+	lits := make([]string, len(ints))
+	for i, integer := range ints {
+		lits[i] = integer.Literal
+		integer.Literal = strconv.FormatInt(int64(integer.Value), 10)
+	}
+	str := stmt.String()
+	for i, integer := range ints {
+		integer.Literal = lits[i]
+	}
+	return str
+}
+
+// integers returns the integer literals of a statement.
+func integers(stmt ast.Statement) []*ast.Integer {
+	var op ast.Operand
+	switch s := stmt.(type) {
+	case *ast.OrgStatement:
+		return []*ast.Integer{s.Value}
+	case *ast.WordStatement:
+		return s.Values
+	case *ast.EquStatement:
+		return []*ast.Integer{s.Value}
+	case *ast.LabelStatement:
+		if ref, valid := s.Reference.(*ast.Integer); valid {
+			return []*ast.Integer{ref}
+		} else if ref, valid := s.Reference.(ast.Statement); valid {
+			return integers(ref)
+		}
+	case *ast.AddStatement:
+		op = s.Operand
+	case *ast.AddCCStatement:
+		op = s.Operand
+	case *ast.SubStatement:
+		op = s.Operand
+	case *ast.SubCCStatement:
+		op = s.Operand
+	case *ast.AndStatement:
+		op = s.Operand
+	case *ast.AndCCStatement:
+		op = s.Operand
+	case *ast.OrStatement:
+		op = s.Operand
+	case *ast.OrCCStatement:
+		op = s.Operand
+	case *ast.OrnStatement:
+		op = s.Operand
+	case *ast.OrnCCStatement:
+		op = s.Operand
+	case *ast.XorStatement:
+		op = s.Operand
+	case *ast.XorCCStatement:
+		op = s.Operand
+	case *ast.SLLStatement:
+		op = s.Operand
+	case *ast.SRAStatement:
+		op = s.Operand
+	case *ast.CmpStatement:
+		op = s.Operand
+	case *ast.MovStatement:
+		op = s.Operand
+	}
+	if i, valid := op.(*ast.Integer); valid {
+		return []*ast.Integer{i}
+	}
+	return nil
+}
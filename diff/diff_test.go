@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/parser"
+)
+
+// TestDiff validates the semantic differences reported between two programs.
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		out  string
+	}{
+		// Formatting, integer notation and comments are ignored.
+		{
+			from: ".begin\nld [%r1+4], %r2\nadd %r1, 8, %r2\n.end",
+			to:   ".begin\n\n  ld  [ %r1 + 4 ] ,%r2 ! load\n\tadd %r1,010,%r2\n.end",
+			out:  "no semantic difference",
+		},
+		// Changed operand.
+		{
+			from: "add %r1, 1, %r2\nsub %r1, 1, %r2",
+			to:   "add %r1, 2, %r2\nsub %r1, 1, %r2",
+			out:  `1:1: changed "add %r1, 1, %r2" to "add %r1, 2, %r2"`,
+		},
+		// Added and removed instructions.
+		{
+			from: "add %r1, 1, %r2\nsub %r1, 1, %r2",
+			to:   "sub %r1, 1, %r2\nand %r1, 1, %r2",
+			out:  "1:1: removed \"add %r1, 1, %r2\"\n2:1: added \"and %r1, 1, %r2\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			changes := Diff(parse(t, tt.from), parse(t, tt.to))
+			equals(t, tt.out, changes.String())
+		})
+	}
+}
+
+func parse(tb testing.TB, src string) *ast.Program {
+	tb.Helper()
+	prog, err := parser.New(strings.NewReader(src)).Parse()
+	ok(tb, err)
+	return prog
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package fmt
+
+import "fmt"
+
+// CommentSpacing is the style of the space between the leading "!" and the text
+// of a comment.
+type CommentSpacing int
+
+const (
+	// KeepSpacing keeps the spacing of the source, only inserting a space if
+	// the text directly follows the "!".
+	KeepSpacing CommentSpacing = iota
+	// SingleSpace separates the "!" and the text by exactly one space, like
+	// "! comment".
+	SingleSpace
+	// NoSpace puts the text directly after the "!", like "!comment".
+	NoSpace
+)
+
+var commentSpacings = [...]string{
+	KeepSpacing: "keep",
+	SingleSpace: "single",
+	NoSpace:     "none",
+}
+
+func (c CommentSpacing) String() string {
+	if c >= 0 && int(c) < len(commentSpacings) {
+		return commentSpacings[c]
+	}
+	return fmt.Sprintf("CommentSpacing(%d)", int(c))
+}
+
+// ParseCommentSpacing returns the comment spacing with the given name, which is
+// one of "keep", "single" or "none".
+func ParseCommentSpacing(name string) (CommentSpacing, error) {
+	for c, n := range commentSpacings {
+		if n == name {
+			return CommentSpacing(c), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid comment spacing %q: must be one of keep, single, none", name)
+}
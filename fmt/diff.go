@@ -0,0 +1,168 @@
+package fmt
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/lukasmalkmus/arc/parser"
+)
+
+// contextLines is the number of unchanged lines shown around the changes of a
+// diff.
+const contextLines = 3
+
+// DiffFile formats an ARC source file without writing it back. It returns a
+// unified diff between the source and the formatted program, which is empty if
+// the file is already formatted. An error is returned if formatting fails.
+func DiffFile(filename string, options *Options) ([]byte, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := parser.New(bytes.NewReader(src)).Parse()
+	if err != nil {
+		return nil, err
+	}
+	code, err := New(prog, options).Format()
+	if err != nil {
+		return nil, err
+	}
+	return unifiedDiff(filename+".orig", filename, src, code), nil
+}
+
+// edit is a single line of an edit script. Its kind is ' ' for an unchanged,
+// '-' for a removed and '+' for an added line.
+type edit struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the differences between a and b in unified diff format.
+// Nil is returned if a and b are equal.
+func unifiedDiff(nameA, nameB string, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", nameA, nameB)
+	for start := 0; start < len(edits); {
+		// Skip to the next change and collect it with its surrounding context.
+		// Changes separated by no more than twice the context end up in the
+		// same hunk.
+		first := start
+		for first < len(edits) && edits[first].kind == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for i := first; i < len(edits) && i <= last+2*contextLines+1; i++ {
+			if edits[i].kind != ' ' {
+				last = i
+			}
+		}
+		from := first - contextLines
+		if from < start {
+			from = start
+		}
+		to := last + contextLines + 1
+		if to > len(edits) {
+			to = len(edits)
+		}
+		writeHunk(&buf, edits, from, to)
+		start = to
+	}
+	return buf.Bytes()
+}
+
+// writeHunk writes the edits from the index from up to to as hunk.
+func writeHunk(buf *bytes.Buffer, edits []edit, from, to int) {
+	// The hunk header holds the first line and the number of lines of both
+	// sides. A side without lines names the line before the hunk.
+	var lineA, lineB, lenA, lenB int
+	for _, e := range edits[:from] {
+		if e.kind != '+' {
+			lineA++
+		}
+		if e.kind != '-' {
+			lineB++
+		}
+	}
+	for _, e := range edits[from:to] {
+		if e.kind != '+' {
+			lenA++
+		}
+		if e.kind != '-' {
+			lenB++
+		}
+	}
+	if lenA > 0 {
+		lineA++
+	}
+	if lenB > 0 {
+		lineB++
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", lineA, lenA, lineB, lenB)
+
+	for _, e := range edits[from:to] {
+		buf.WriteByte(e.kind)
+		buf.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits text into lines, keeping the line breaks.
+func splitLines(text []byte) []string {
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the edit script transforming the lines a into the lines b.
+// It follows the longest common subsequence of both.
+func diffLines(a, b []string) []edit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	return edits
+}
@@ -10,30 +10,72 @@ parser must not return an error to start formatting.
 package fmt
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"strings"
 
 	"github.com/lukasmalkmus/arc/ast"
 	"github.com/lukasmalkmus/arc/internal"
 	"github.com/lukasmalkmus/arc/parser"
 )
 
+// Options are configuration values for the Formater.
+type Options struct {
+	// Simplify enables semantics-preserving simplifications of the program,
+	// like dropping zero offsets in expressions.
+	Simplify bool
+	// PreserveComments keeps the text of comments verbatim instead of
+	// normalizing it. Neither trailing whitespace is removed nor a space
+	// inserted after the leading "!".
+	PreserveComments bool
+	// CommentSpacing controls the space between the leading "!" and the text
+	// of comments. It has no effect if PreserveComments is enabled.
+	CommentSpacing CommentSpacing
+	// IndentWidth is the number of spaces instructions, data and directives
+	// are indented by. Labels start in the first column and their statement
+	// is aligned with the other statements. Comments on their own line aren't
+	// indented. Zero disables indentation.
+	IndentWidth int
+	// AlignComments aligns the trailing comments of consecutive lines into a
+	// column one space after the longest of these lines.
+	AlignComments bool
+	// UpperCaseMnemonics renders the mnemonics of instructions in upper case,
+	// like "LD" and "ADD", instead of lower case. Directives, registers and
+	// identifiers aren't affected.
+	UpperCaseMnemonics bool
+	// Verify formats the formatted program a second time and returns an error
+	// if the result differs. Formatting must be idempotent, so a difference
+	// indicates a bug in the formatter.
+	Verify bool
+}
+
 // Formater formats ARC source code.
 type Formater struct {
+	opts *Options
 	prog *ast.Program
 }
 
 // New returns a new ARC formater. It operates on the AST of an ARC program.
-func New(prog *ast.Program) *Formater {
-	return &Formater{
+func New(prog *ast.Program, options *Options) *Formater {
+	f := &Formater{
+		opts: options,
 		prog: prog,
 	}
+
+	// Set defaults.
+	if f.opts == nil {
+		f.opts = &Options{}
+	}
+
+	return f
 }
 
 // Format will format ARC source code. The function takes the source from an
 // io.Reader as parameter. It returns the formated program as a slice of bytes.
 // An error is returned if formating fails.
-func Format(src io.Reader) ([]byte, error) {
+func Format(src io.Reader, options *Options) ([]byte, error) {
 	errs := internal.MultiError{}
 
 	// TODO: If the parser can handle invalid source code, we can continue and
@@ -46,7 +88,7 @@ func Format(src io.Reader) ([]byte, error) {
 		return nil, err
 	}
 
-	code, err := New(prog).Format()
+	code, err := New(prog, options).Format()
 	if err != nil {
 		errs.Add(err)
 		return nil, errs.Return()
@@ -55,10 +97,20 @@ func Format(src io.Reader) ([]byte, error) {
 	return code, errs.Return()
 }
 
+// Fprint formats ARC source code like Format, but writes the formatted program
+// to w. An error is returned if parsing, formatting or writing fails.
+func Fprint(w io.Writer, src io.Reader, options *Options) error {
+	prog, err := parser.New(src).Parse()
+	if err != nil {
+		return err
+	}
+	return New(prog, options).Fprint(w)
+}
+
 // FormatFile will format an ARC source file. The function takes a filename as
 // parameter. The formated program will be written back to the source file. The
 // function returns an error if formating fails.
-func FormatFile(filename string) error {
+func FormatFile(filename string, options *Options) error {
 	errs := internal.MultiError{}
 
 	// TODO: If the parser can handle invalid source code, we can continue and
@@ -71,7 +123,7 @@ func FormatFile(filename string) error {
 		return err
 	}
 
-	code, err := New(prog).Format()
+	code, err := New(prog, options).Format()
 	if err != nil {
 		errs.Add(err)
 		return errs.Return()
@@ -85,5 +137,171 @@ func FormatFile(filename string) error {
 // Format will format ARC source code. The function returns the formated program
 // as a slice of bytes. An error is returned if formating fails.
 func (f *Formater) Format() ([]byte, error) {
-	return []byte(f.prog.String()), nil
+	var buf bytes.Buffer
+	if err := f.Fprint(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Fprint formats the program and writes it to w line by line. With the verify
+// option enabled, the whole program is formatted and verified before anything
+// is written. An error is returned if formatting or writing fails.
+func (f *Formater) Fprint(w io.Writer) error {
+	lines := f.layout()
+	if f.opts.Verify {
+		code := []byte(strings.Join(lines, "\n"))
+		if err := f.verify(code); err != nil {
+			return err
+		}
+		_, err := w.Write(code)
+		return err
+	}
+
+	for i, line := range lines {
+		if i > 0 {
+			line = "\n" + line
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layout formats the statements of the program and returns the lines of the
+// formatted program.
+func (f *Formater) layout() []string {
+	if f.opts.Simplify {
+		simplify(f.prog)
+	}
+
+	// Trailing comments stay on the line of their statement. They are
+	// collected separately to align them once all lines are known. A trailing
+	// comment whose statement isn't part of the program anymore is dropped
+	// instead of being moved to another line.
+	lines := make([]string, 0, len(f.prog.Statements))
+	trailing := make([]string, 0, len(f.prog.Statements))
+	var prev ast.Statement
+	for _, stmt := range f.prog.Statements {
+		comment, isComment := stmt.(*ast.CommentStatement)
+		switch {
+		case isComment && comment.Statement != nil:
+			if comment.Statement == prev && len(lines) > 0 {
+				trailing[len(lines)-1] = f.formatComment(comment)
+			}
+		case isComment:
+			lines = append(lines, f.formatComment(comment))
+			trailing = append(trailing, "")
+		default:
+			lines = append(lines, f.formatStatement(stmt))
+			trailing = append(trailing, "")
+		}
+		prev = stmt
+	}
+	return f.appendComments(lines, trailing)
+}
+
+// formatStatement returns the formatted statement, indented by the indent
+// width. The identifier of a label is placed in front of the indentation
+// instead, separated by at least one space from its statement.
+func (f *Formater) formatStatement(stmt ast.Statement) string {
+	label, isLabel := stmt.(*ast.LabelStatement)
+	if !isLabel {
+		return strings.Repeat(" ", max(f.opts.IndentWidth, 0)) + f.formatMnemonic(stmt)
+	}
+
+	ident := label.Ident.String() + ":"
+	ref := label.Reference.String()
+	if refStmt, valid := label.Reference.(ast.Statement); valid {
+		ref = f.formatMnemonic(refStmt)
+	}
+	return ident + strings.Repeat(" ", max(f.opts.IndentWidth-len(ident), 1)) + ref
+}
+
+// formatMnemonic returns the statement with the mnemonic of an instruction in
+// the case selected by the options. Other statements are returned unchanged.
+func (f *Formater) formatMnemonic(stmt ast.Statement) string {
+	str, mnemonic := stmt.String(), stmt.Tok().String()
+	if !f.opts.UpperCaseMnemonics || !stmt.Tok().IsKeyword() || !strings.HasPrefix(str, mnemonic) {
+		return str
+	}
+	return strings.ToUpper(mnemonic) + str[len(mnemonic):]
+}
+
+// appendComments appends the trailing comments to their lines. Aligned
+// comments of consecutive lines start in the same column.
+func (f *Formater) appendComments(lines, trailing []string) []string {
+	res := make([]string, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if trailing[i] == "" {
+			res[i] = lines[i]
+			continue
+		}
+		if !f.opts.AlignComments {
+			res[i] = lines[i] + " " + trailing[i]
+			continue
+		}
+
+		// Find the consecutive lines with trailing comments and their longest
+		// line.
+		end, width := i, 0
+		for ; end < len(lines) && trailing[end] != ""; end++ {
+			width = max(width, len(lines[end]))
+		}
+		for ; i < end; i++ {
+			res[i] = lines[i] + strings.Repeat(" ", width-len(lines[i])+1) + trailing[i]
+		}
+		i--
+	}
+	return res
+}
+
+// max returns the larger of x or y.
+func max(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+// verify formats the formatted code once more with the same options and
+// returns an error if the second pass differs from the first one.
+func (f *Formater) verify(code []byte) error {
+	prog, err := parser.New(bytes.NewReader(code)).Parse()
+	if err != nil {
+		return fmt.Errorf("formatted program doesn't parse: %s", err)
+	}
+	opts := *f.opts
+	opts.Verify = false
+	again, err := New(prog, &opts).Format()
+	if err != nil {
+		return err
+	}
+
+	first, second := strings.Split(string(code), "\n"), strings.Split(string(again), "\n")
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i >= len(first) || i >= len(second) || first[i] != second[i] {
+			return fmt.Errorf("formatting isn't idempotent: second pass differs at line %d", i+1)
+		}
+	}
+	return nil
+}
+
+// formatComment returns the formatted text of a comment.
+func (f *Formater) formatComment(comment *ast.CommentStatement) string {
+	if f.opts.PreserveComments {
+		return comment.Text
+	}
+
+	text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "!"))
+	switch {
+	case text == "":
+		return "!"
+	case f.opts.CommentSpacing == SingleSpace:
+		return "! " + text
+	case f.opts.CommentSpacing == NoSpace:
+		return "!" + text
+	}
+	return comment.String()
 }
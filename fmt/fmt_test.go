@@ -0,0 +1,420 @@
+package fmt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/parser"
+	"github.com/lukasmalkmus/arc/simulator"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// TestFormat validates the formatting of complete programs.
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		src  string
+		opts *Options
+		out  string
+	}{
+		{
+			src:  ".begin\nld [%r1+0], %r2\n.end",
+			opts: nil,
+			out:  ".begin\nld [%r1+0], %r2\n.end",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			out, err := Format(strings.NewReader(tt.src), tt.opts)
+			ok(t, err)
+			equals(t, tt.out, string(out))
+		})
+	}
+}
+
+// TestFprint validates that the program written by Fprint equals the one
+// returned by Format.
+func TestFprint(t *testing.T) {
+	src := "! sum\n.begin\n.org 2048\nld [x], %r1 ! load\nadd %r1, 0x2, %r2\n.org 3000\nx: 5\n.end"
+	for _, opts := range []*Options{nil, {Simplify: true}, {IndentWidth: 8, AlignComments: true, Verify: true}} {
+		want, err := Format(strings.NewReader(src), opts)
+		ok(t, err)
+
+		var buf bytes.Buffer
+		ok(t, Fprint(&buf, strings.NewReader(src), opts))
+		equals(t, string(want), buf.String())
+	}
+}
+
+// TestDiffFile validates the diff of an already formatted and an unformatted
+// file. Neither file is modified.
+func TestDiffFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arc")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+
+	formatted := filepath.Join(dir, "formatted.arc")
+	ok(t, ioutil.WriteFile(formatted, []byte(".begin\nld [x], %r1\nx: 5\n.end"), 0644))
+	diff, err := DiffFile(formatted, nil)
+	ok(t, err)
+	equals(t, 0, len(diff))
+
+	src := ".begin\n.org 2048\nld [x],%r1\nadd %r1, 1, %r2\nst %r2, [x]\nnop\nnop\nnop\nnop\nnop\nx:   5\n.end\n"
+	unformatted := filepath.Join(dir, "unformatted.arc")
+	ok(t, ioutil.WriteFile(unformatted, []byte(src), 0644))
+	diff, err = DiffFile(unformatted, nil)
+	ok(t, err)
+	want := "--- " + unformatted + ".orig\n+++ " + unformatted + "\n" +
+		"@@ -1,6 +1,6 @@\n .begin\n .org 2048\n-ld [x],%r1\n+ld [x], %r1\n add %r1, 1, %r2\n st %r2, [x]\n nop\n" +
+		"@@ -8,5 +8,5 @@\n nop\n nop\n nop\n-x:   5\n-.end\n+x: 5\n+.end\n\\ No newline at end of file\n"
+	equals(t, want, string(diff))
+
+	out, err := ioutil.ReadFile(unformatted)
+	ok(t, err)
+	equals(t, src, string(out))
+}
+
+// TestFormat_Simplify validates the simplifications applied by the simplify
+// option.
+func TestFormat_Simplify(t *testing.T) {
+	tests := []struct {
+		src string
+		out string
+	}{
+		// Zero offsets are dropped, everything else is left unchanged.
+		{
+			src: ".begin\n.org 2048\nld [%r1+0], %r2\nst %r2, [%r3+4]\nx: 0x10\n.end",
+			out: ".begin\n.org 2048\nld [%r1], %r2\nst %r2, [%r3+4]\nx: 0x10\n.end",
+		},
+		{
+			src: "x: ld [%r1+0], %r2\njmpl [%r15+0], %r0",
+			out: "x: ld [%r1], %r2\njmpl [%r15], %r0",
+		},
+		// Octal integers are rewritten as decimals.
+		{
+			src: ".org 04000\nadd %r1, 010, %r2\nx: 007",
+			out: ".org 2048\nadd %r1, 8, %r2\nx: 7",
+		},
+		// Character literals are kept.
+		{
+			src: "add %r0, 'A', %r1\nx: '\\n'",
+			out: "add %r0, 'A', %r1\nx: '\\n'",
+		},
+		// Binary integers are kept.
+		{
+			src: "and %r1, 0b1010, %r2",
+			out: "and %r1, 0b1010, %r2",
+		},
+		// Instructions without effect become nop, keeping their comment.
+		{
+			src: "add %r1, 0, %r1\nsub %r1, 0, %r2\nand %r1, 0, %r1\nor %r2, %r3, %r0 ! discard\naddcc %r1, 0, %r1",
+			out: "nop\nsub %r1, 0, %r2\nand %r1, 0, %r1\nnop ! discard\naddcc %r1, 0, %r1",
+		},
+		{
+			src: "ld [x], %r1 ! load x\nadd %r1, 0, %r1 ! no-op\nx: 5",
+			out: "ld [x], %r1 ! load x\nnop ! no-op\nx: 5",
+		},
+		// Labeled instructions are never replaced.
+		{
+			src: "x: add %r1, 0, %r1",
+			out: "x: add %r1, 0, %r1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			out, err := Format(strings.NewReader(tt.src), &Options{Simplify: true})
+			ok(t, err)
+			equals(t, tt.out, string(out))
+		})
+	}
+}
+
+// TestFormat_SimplifySimulation validates that simplified programs behave like
+// the original ones when simulated. They end in the same state, with the same
+// memory contents and after executing every statement equally often.
+func TestFormat_SimplifySimulation(t *testing.T) {
+	arraySum, err := ioutil.ReadFile(filepath.Join("..", "testdata", "array_sum.arc"))
+	ok(t, err)
+
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"array sum", string(arraySum)},
+		// The load through %r5 reads the data at an absolute address, which
+		// would read an instruction if the no-ops before were removed.
+		{"absolute addresses", ".begin\n.org 2048\nadd %r1, 0, %r1\nor %r2, %r3, %r0\nadd %r0, 2080, %r5\nld %r5, %r6\nld [x+0], %r1\nadd %r1, 010, %r1\nst %r1, [y+0]\nsra %r1, 0, %r1\nx: 010\ny: 0\n.end"},
+		{"register offsets", ".begin\n.org 2048\nadd %r0, 2060, %r1\nsll %r2, 0, %r2\nld [%r1+0], %r3\nld [%r1+4], %r4\n.word 7, 9\n.end"},
+	}
+
+	simulate := func(t *testing.T, src string) (simulator.StopReason, simulator.State, []int32, map[token.Pos]int) {
+		t.Helper()
+		prog, err := parser.Parse(src)
+		ok(t, err)
+		s := simulator.New(&simulator.Options{StepLimit: 1000})
+		reason, err := s.Run(prog)
+		ok(t, err)
+		var mem []int32
+		for addr := int32(2048); addr < 3100; addr += 4 {
+			word, err := s.ReadWord(addr)
+			ok(t, err)
+			mem = append(mem, word)
+		}
+		return reason, s.Snapshot(), mem, s.Coverage()
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Format(strings.NewReader(tt.src), &Options{Simplify: true})
+			ok(t, err)
+			assert(t, string(out) != tt.src, "expected the program to be simplified")
+
+			wantReason, wantState, wantMem, wantCov := simulate(t, tt.src)
+			gotReason, gotState, gotMem, gotCov := simulate(t, string(out))
+			equals(t, wantReason, gotReason)
+			equals(t, wantState, gotState)
+			equals(t, wantMem, gotMem)
+			equals(t, len(wantCov), len(gotCov))
+		})
+	}
+}
+
+// TestFormat_PreserveComments validates that comments are kept verbatim by the
+// preserve comments option.
+func TestFormat_PreserveComments(t *testing.T) {
+	tests := []struct {
+		src  string
+		opts *Options
+		out  string
+	}{
+		{
+			src:  "!   -----  Data  ----- !  \n!data\n.org 3000\nx: 25",
+			opts: &Options{},
+			out:  "!   -----  Data  ----- !\n! data\n.org 3000\nx: 25",
+		},
+		{
+			src:  "!   -----  Data  ----- !  \n!data\n.org 3000\nx: 25",
+			opts: &Options{PreserveComments: true},
+			out:  "!   -----  Data  ----- !  \n!data\n.org 3000\nx: 25",
+		},
+		{
+			src:  "! ----- !\nld [%r1+0], %r2\n!no space",
+			opts: &Options{PreserveComments: true, Simplify: true},
+			out:  "! ----- !\nld [%r1], %r2\n!no space",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			out, err := Format(strings.NewReader(tt.src), tt.opts)
+			ok(t, err)
+			equals(t, tt.out, string(out))
+		})
+	}
+}
+
+// TestFormat_CommentSpacing validates the spacing styles of comments.
+func TestFormat_CommentSpacing(t *testing.T) {
+	src := "!comment\n!   aligned  \n! single\n!\nld %r1, %r2"
+	tests := []struct {
+		spacing CommentSpacing
+		out     string
+	}{
+		{KeepSpacing, "! comment\n!   aligned\n! single\n!\nld %r1, %r2"},
+		{SingleSpace, "! comment\n! aligned\n! single\n!\nld %r1, %r2"},
+		{NoSpace, "!comment\n!aligned\n!single\n!\nld %r1, %r2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spacing.String(), func(t *testing.T) {
+			out, err := Format(strings.NewReader(src), &Options{CommentSpacing: tt.spacing})
+			ok(t, err)
+			equals(t, tt.out, string(out))
+
+			spacing, err := ParseCommentSpacing(tt.spacing.String())
+			ok(t, err)
+			equals(t, tt.spacing, spacing)
+		})
+	}
+
+	_, err := ParseCommentSpacing("double")
+	assert(t, err != nil, "expected error but got nil")
+}
+
+// assert fails the test if the condition is false.
+func assert(tb testing.TB, condition bool, msg string, v ...interface{}) {
+	tb.Helper()
+	if !condition {
+		tb.Fatalf("\033[31m "+msg+"\033[39m\n\n", v...)
+	}
+}
+
+// TestFormat_TrailingComments validates that trailing comments stay on the
+// line of their statement.
+func TestFormat_TrailingComments(t *testing.T) {
+	src := ".begin   ! start\n.org 0x800	!code section\n! own line\nld [%r1+0], %r2 ! load\n.end"
+	out, err := Format(strings.NewReader(src), &Options{Simplify: true})
+	ok(t, err)
+	equals(t, ".begin ! start\n.org 0x800 ! code section\n! own line\nld [%r1], %r2 ! load\n.end", string(out))
+
+	// Labeled statements keep their trailing comment, too.
+	out, err = Format(strings.NewReader("ld [x], %r1 ! Load x.\nx: 5\t\t! Data."), nil)
+	ok(t, err)
+	equals(t, "ld [x], %r1 ! Load x.\nx: 5 ! Data.", string(out))
+}
+
+// TestFormat_Columns validates the layout of labels, statements and trailing
+// comments in columns against the golden file of the array sum sample.
+func TestFormat_Columns(t *testing.T) {
+	src, err := ioutil.ReadFile("../testdata/array_sum.arc")
+	ok(t, err)
+	golden, err := ioutil.ReadFile("../testdata/array_sum.golden")
+	ok(t, err)
+
+	out, err := Format(bytes.NewReader(src), &Options{IndentWidth: 8, AlignComments: true})
+	ok(t, err)
+	equals(t, strings.TrimSuffix(string(golden), "\n"), string(out))
+
+	// Labels longer than the indentation are separated by a single space.
+	// Trailing comments of consecutive lines are aligned.
+	src = []byte("subroutine: ld [x], %r1 ! load\njmpl %r15+4, %r0 ! return\n\nx: 4 ! data\n! own line\n.org 3000 ! data")
+	out, err = Format(bytes.NewReader(src), &Options{IndentWidth: 4, AlignComments: true})
+	ok(t, err)
+	equals(t, "subroutine: ld [x], %r1 ! load\n    jmpl [%r15+4], %r0  ! return\nx:  4                   ! data\n! own line\n    .org 3000 ! data", string(out))
+}
+
+// TestFormat_UpperCaseMnemonics validates the case of mnemonics in both
+// directions. Directives, registers and labels keep their case.
+func TestFormat_UpperCaseMnemonics(t *testing.T) {
+	src := ".begin\nLD [x], %r1\nLoop: Add %r1, 1, %r1\nBA Loop\nnop\nx: 5\n.end"
+	tests := []struct {
+		opts *Options
+		out  string
+	}{
+		{
+			opts: &Options{UpperCaseMnemonics: true},
+			out:  ".begin\nLD [x], %r1\nLoop: ADD %r1, 1, %r1\nBA Loop\nNOP\nx: 5\n.end",
+		},
+		{
+			opts: &Options{},
+			out:  ".begin\nld [x], %r1\nLoop: add %r1, 1, %r1\nba Loop\nnop\nx: 5\n.end",
+		},
+		{
+			opts: &Options{UpperCaseMnemonics: true, IndentWidth: 8},
+			out:  "        .begin\n        LD [x], %r1\nLoop:   ADD %r1, 1, %r1\n        BA Loop\n        NOP\nx:      5\n        .end",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			out, err := Format(strings.NewReader(src), tt.opts)
+			ok(t, err)
+			equals(t, tt.out, string(out))
+		})
+	}
+}
+
+// TestFormat_Idempotent formats every program in the testdata directory and
+// a few additional ones twice with different options and fails if the second
+// pass differs from the first.
+func TestFormat_Idempotent(t *testing.T) {
+	opts := map[string]Options{
+		"default":          {},
+		"simplify":         {Simplify: true},
+		"preserveComments": {PreserveComments: true},
+		"singleSpace":      {CommentSpacing: SingleSpace},
+		"noSpace":          {CommentSpacing: NoSpace},
+		"columns":          {IndentWidth: 8, AlignComments: true},
+		"upperCase":        {UpperCaseMnemonics: true},
+	}
+	srcs := map[string]string{
+		"shift": ".begin\n.org 2048\nsll %r1, 2, %r2 !shift\nsra %r2, %r1, %r3\n.end",
+		"statements": `.begin
+.org 2048
+.equ N, 0x10
+.global f
+.extern g
+ld [x], %r1
+ld [%r1-4], %r2
+st %r1, %r2
+addcc %r1, %r2, %r3
+sub %r1, -1, %r2
+andcc %r1, 1, %r2
+or %r1, 1, %r2
+orncc %r1, 1, %r2
+xor %r1, 1, %r2
+sll %r1, 2, %r2
+sra %r1, %r2, %r3
+bne f
+bneg f
+call g
+f: nop
+jmpl %r15+4, %r0
+cmp %r1, N
+mov %r2, %r1
+clr %r1
+retl
+x: 0x10
+.word 1, -2, 0x3
+.assert x + 4 == 2068
+.end`,
+	}
+
+	err := filepath.Walk("../testdata", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".arc" {
+			return err
+		}
+		src, err := ioutil.ReadFile(path)
+		srcs[path] = string(src)
+		return err
+	})
+	ok(t, err)
+
+	for name, src := range srcs {
+		for optName, o := range opts {
+			src, o := src, o
+			t.Run(name+"/"+optName, func(t *testing.T) {
+				prog, err := parser.Parse(src)
+				if err != nil {
+					t.Skipf("program doesn't parse: %s", err)
+				}
+				o.Verify = true
+				_, err = New(prog, &o).Format()
+				ok(t, err)
+			})
+		}
+	}
+}
+
+// TestFormat_Verify validates that the verify option reports a formatter which
+// isn't idempotent.
+func TestFormat_Verify(t *testing.T) {
+	f := New(&ast.Program{}, &Options{Verify: true})
+	err := f.verify([]byte("ld [%r1+0], %r2\n!comment"))
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, "formatting isn't idempotent: second pass differs at line 2", err.Error())
+	ok(t, f.verify([]byte("ld [%r1+0], %r2\n! comment")))
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}
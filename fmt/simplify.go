@@ -0,0 +1,165 @@
+package fmt
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// simplify applies semantics-preserving simplifications to the program. The
+// following transformations are applied:
+//
+//	[%r1+0] -> [%r1]          (zero offsets are dropped)
+//	010     -> 8              (octal integers are rewritten as decimals)
+//	add %r1, 0, %r1 -> nop    (instructions without any effect become nop)
+//
+// Instructions without effect are replaced instead of removed, so the address
+// of every following statement stays the same. Their trailing comment stays
+// on the line of the nop. Instructions referenced by a label are never
+// replaced.
+func simplify(prog *ast.Program) {
+	for i, stmt := range prog.Statements {
+		if !isNoOp(stmt) {
+			simplifyStatement(stmt)
+			continue
+		}
+		nop := &ast.NopStatement{Token: token.NOP, Position: stmt.Pos()}
+		prog.Statements[i] = nop
+		if i+1 < len(prog.Statements) {
+			if comment, valid := prog.Statements[i+1].(*ast.CommentStatement); valid && comment.Statement == stmt {
+				comment.Statement = nop
+			}
+		}
+	}
+}
+
+// simplifyStatement simplifies the expressions and integers of a statement.
+func simplifyStatement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.OrgStatement:
+		simplifyInteger(s.Value)
+	case *ast.EquStatement:
+		simplifyInteger(s.Value)
+	case *ast.WordStatement:
+		for _, val := range s.Values {
+			simplifyInteger(val)
+		}
+	case *ast.LabelStatement:
+		if ref, valid := s.Reference.(*ast.Integer); valid {
+			simplifyInteger(ref)
+		} else if ref, valid := s.Reference.(ast.Statement); valid {
+			simplifyStatement(ref)
+		}
+	case *ast.LoadStatement:
+		simplifyMemoryLocation(s.Source)
+	case *ast.StoreStatement:
+		simplifyMemoryLocation(s.Destination)
+	case *ast.AddStatement:
+		simplifyOperand(s.Operand)
+	case *ast.AddCCStatement:
+		simplifyOperand(s.Operand)
+	case *ast.SubStatement:
+		simplifyOperand(s.Operand)
+	case *ast.SubCCStatement:
+		simplifyOperand(s.Operand)
+	case *ast.AndStatement:
+		simplifyOperand(s.Operand)
+	case *ast.AndCCStatement:
+		simplifyOperand(s.Operand)
+	case *ast.OrStatement:
+		simplifyOperand(s.Operand)
+	case *ast.OrCCStatement:
+		simplifyOperand(s.Operand)
+	case *ast.OrnStatement:
+		simplifyOperand(s.Operand)
+	case *ast.OrnCCStatement:
+		simplifyOperand(s.Operand)
+	case *ast.XorStatement:
+		simplifyOperand(s.Operand)
+	case *ast.XorCCStatement:
+		simplifyOperand(s.Operand)
+	case *ast.SLLStatement:
+		simplifyOperand(s.Operand)
+	case *ast.SRAStatement:
+		simplifyOperand(s.Operand)
+	case *ast.CmpStatement:
+		simplifyOperand(s.Operand)
+	case *ast.MovStatement:
+		simplifyOperand(s.Operand)
+	case *ast.JumpAndLinkStatement:
+		simplifyExpression(s.ReturnAddress)
+	}
+}
+
+// simplifyMemoryLocation simplifies a memory location if it is an expression.
+func simplifyMemoryLocation(memLoc ast.MemoryLocation) {
+	if exp, valid := memLoc.(*ast.Expression); valid {
+		simplifyExpression(exp)
+	}
+}
+
+// simplifyExpression drops the zero offset of an expression.
+func simplifyExpression(exp *ast.Expression) {
+	if exp.Operator != "" && exp.Offset != nil && exp.Offset.Value == 0 {
+		exp.Operator = ""
+		exp.Offset = nil
+	}
+	if exp.Offset != nil {
+		simplifyInteger(exp.Offset)
+	}
+}
+
+// simplifyOperand simplifies an operand if it is an integer.
+func simplifyOperand(op ast.Operand) {
+	if i, valid := op.(*ast.Integer); valid {
+		simplifyInteger(i)
+	}
+}
+
+// simplifyInteger rewrites octal integer literals as decimals. Hexadecimal and
+// binary literals are kept because they usually express addresses or bit
+// patterns.
+func simplifyInteger(i *ast.Integer) {
+	lit := i.Literal
+	if len(lit) > 1 && lit[0] == '0' && !strings.HasPrefix(lit, "0x") && !strings.HasPrefix(lit, "0b") {
+		i.Literal = strconv.FormatInt(int64(i.Value), 10)
+	}
+}
+
+// isNoOp returns true if the statement has no effect at all. These are
+// instructions which don't set the condition codes and either write into %r0
+// or apply an identity operation (e.g. adding zero) to their source register.
+func isNoOp(stmt ast.Statement) bool {
+	switch s := stmt.(type) {
+	case *ast.AddStatement:
+		return noOp(s.Source, s.Operand, s.Destination, true)
+	case *ast.SubStatement:
+		return noOp(s.Source, s.Operand, s.Destination, true)
+	case *ast.AndStatement:
+		return noOp(s.Source, s.Operand, s.Destination, false)
+	case *ast.OrStatement:
+		return noOp(s.Source, s.Operand, s.Destination, true)
+	case *ast.OrnStatement:
+		return noOp(s.Source, s.Operand, s.Destination, false)
+	case *ast.XorStatement:
+		return noOp(s.Source, s.Operand, s.Destination, true)
+	case *ast.SLLStatement:
+		return noOp(s.Source, s.Operand, s.Destination, true)
+	case *ast.SRAStatement:
+		return noOp(s.Source, s.Operand, s.Destination, true)
+	}
+	return false
+}
+
+// noOp returns true if the result is written into %r0 or if zeroIdentity is set
+// and the operation applies zero to the source register, writing the result back
+// into it.
+func noOp(src *ast.Register, op ast.Operand, dst *ast.Register, zeroIdentity bool) bool {
+	if dst.Name == "%r0" {
+		return true
+	}
+	i, valid := op.(*ast.Integer)
+	return zeroIdentity && valid && i.Value == 0 && src.Name == dst.Name
+}
@@ -4,5 +4,6 @@ which are used by multiple packages:
 - MultiError datatype
 - File utils for common I/O operations
 - Name of AST statement object to string function
+- Skeleton of a new ARC program
 */
 package internal
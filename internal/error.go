@@ -1,7 +1,6 @@
 package internal
 
 import (
-	"fmt"
 	"sort"
 	"strings"
 )
@@ -42,14 +41,11 @@ func (m MultiError) Return() error {
 	return nil
 }
 
-// Sort sorts the underlying slice of errors.
+// Sort sorts the underlying slice of errors by the source position they are
+// prefixed with. Positions are compared by filename, line and character, so
+// "file.arc:9:1" sorts before "file.arc:10:1".
 func (m *MultiError) Sort() {
-	strs := make([]string, len(m.errs))
-	for k, err := range m.errs {
-		strs[k] = err.Error()
-	}
-	sort.Strings(strs)
-	for k, err := range strs {
-		m.errs[k] = fmt.Errorf("%s", err)
-	}
+	sort.SliceStable(m.errs, func(i, j int) bool {
+		return lessByPosition(m.errs[i].Error(), m.errs[j].Error())
+	})
 }
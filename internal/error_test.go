@@ -103,6 +103,14 @@ func TestMultiError_Sort(t *testing.T) {
 			errs: []error{fmt.Errorf(`4:13: unresolved IDENTIFIER "y"`), fmt.Errorf(`3:8: unresolved IDENTIFIER "x"`)},
 			err:  "3:8: unresolved IDENTIFIER \"x\"\n4:13: unresolved IDENTIFIER \"y\"",
 		},
+		{
+			errs: []error{fmt.Errorf(`file.arc:10:1: second error`), fmt.Errorf(`file.arc:9:1: first error`)},
+			err:  "file.arc:9:1: first error\nfile.arc:10:1: second error",
+		},
+		{
+			errs: []error{fmt.Errorf(`b.arc:1:1: third error`), fmt.Errorf(`a.arc:2:5: second error`), fmt.Errorf(`a.arc:2:1: first error`)},
+			err:  "a.arc:2:1: first error\na.arc:2:5: second error\nb.arc:1:1: third error",
+		},
 	}
 
 	for _, tt := range tests {
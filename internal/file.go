@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -41,3 +43,29 @@ func ReadDir(dirname string) ([]string, error) {
 	}
 	return files, nil
 }
+
+// ReadFile reads the contents of a file, but reads at most limit bytes. An error
+// is returned if the file is larger than the limit, without reading it any
+// further. This protects against exhausting memory with huge files. A limit of
+// zero or less reads the file without limit.
+func ReadFile(filename string, limit int64) ([]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if limit <= 0 {
+		return ioutil.ReadAll(f)
+	}
+
+	// Read one byte more than the limit to detect files exceeding it.
+	b, err := ioutil.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("%s: file exceeds the size limit of %d bytes", filename, limit)
+	}
+	return b, nil
+}
@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -89,3 +91,34 @@ func TestReadDir(t *testing.T) {
 		})
 	}
 }
+
+func TestReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arc")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "prog.arc")
+	ok(t, ioutil.WriteFile(filename, []byte("ld %r1, %r2"), 0644))
+
+	tests := []struct {
+		limit int64
+		err   string
+	}{
+		{limit: 0},
+		{limit: 11},
+		{limit: 100},
+		{limit: 10, err: filename + ": file exceeds the size limit of 10 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			b, err := ReadFile(filename, tt.limit)
+			if tt.err != "" {
+				assert(t, err != nil, "expected error but got nil")
+				equals(t, tt.err, err.Error())
+				return
+			}
+			ok(t, err)
+			equals(t, "ld %r1, %r2", string(b))
+		})
+	}
+}
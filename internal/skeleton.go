@@ -0,0 +1,25 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Skeleton returns the source code of a minimal but complete ARC program with
+// the given name. It declares the program with .begin and .end, starts the code
+// at address 2048 with the exported main label and returns to the caller when
+// done. The name is used as filename in the leading comment.
+func Skeleton(name string) []byte {
+	name = strings.TrimSuffix(filepath.Base(name), ".arc")
+	return []byte(fmt.Sprintf(`! %s.arc
+.begin
+.org 2048
+.global main
+main:   ld [x], %%r1       ! Load x.
+        jmpl %%r15+4, %%r0  ! Return to the caller.
+
+x:      0
+.end
+`, name))
+}
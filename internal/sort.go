@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// SortByPosition sorts messages which are prefixed with a source position, like
+// "file.arc:3:8: message" or "3:8: message". Messages are ordered by filename,
+// line and character. Messages without a position are placed behind all others
+// and ordered lexically.
+func SortByPosition(msgs []string) {
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return lessByPosition(msgs[i], msgs[j])
+	})
+}
+
+// lessByPosition reports whether message a is positioned before message b.
+// Positions are compared numerically, so line 9 comes before line 10. Messages
+// with equal positions are compared lexically.
+func lessByPosition(a, b string) bool {
+	pa, oka := parsePosition(a)
+	pb, okb := parsePosition(b)
+	switch {
+	case oka != okb:
+		return oka
+	case !oka:
+		return a < b
+	case pa.Filename != pb.Filename:
+		return pa.Filename < pb.Filename
+	case pa.Line != pb.Line:
+		return pa.Line < pb.Line
+	case pa.Char != pb.Char:
+		return pa.Char < pb.Char
+	}
+	return a < b
+}
+
+// parsePosition extracts the source position a message is prefixed with. It
+// returns false if the message has no position prefix.
+func parsePosition(msg string) (token.Pos, bool) {
+	end := strings.Index(msg, ": ")
+	if end < 0 {
+		return token.Pos{}, false
+	}
+	parts := strings.Split(msg[:end], ":")
+	if len(parts) < 2 {
+		return token.Pos{}, false
+	}
+	line, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return token.Pos{}, false
+	}
+	char, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return token.Pos{}, false
+	}
+	filename := strings.Join(parts[:len(parts)-2], ":")
+	return token.Pos{Filename: filename, Line: line, Char: char}, true
+}
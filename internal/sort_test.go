@@ -0,0 +1,26 @@
+package internal
+
+import "testing"
+
+func TestSortByPosition(t *testing.T) {
+	tests := []struct {
+		msgs []string
+		want []string
+	}{
+		{
+			msgs: []string{"10:1: c", "2:5: b", "2:1: a"},
+			want: []string{"2:1: a", "2:5: b", "10:1: c"},
+		},
+		{
+			msgs: []string{"b.arc:1:1: c", "no position", "a.arc:12:1: b", "a.arc:3:1: a"},
+			want: []string{"a.arc:3:1: a", "a.arc:12:1: b", "b.arc:1:1: c", "no position"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			SortByPosition(tt.msgs)
+			equals(t, tt.want, tt.msgs)
+		})
+	}
+}
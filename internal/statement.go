@@ -14,6 +14,16 @@ func StatementName(stmt ast.Statement) string {
 		return "END"
 	case *ast.OrgStatement:
 		return "ORG"
+	case *ast.GlobalStatement:
+		return "GLOBAL"
+	case *ast.ExternStatement:
+		return "EXTERN"
+	case *ast.WordStatement:
+		return "WORD"
+	case *ast.EquStatement:
+		return "EQU"
+	case *ast.AssertStatement:
+		return "ASSERT"
 	case *ast.LabelStatement:
 		return "LABEL"
 	case *ast.LoadStatement:
@@ -48,6 +58,16 @@ func StatementName(stmt ast.Statement) string {
 		return "SLL"
 	case *ast.SRAStatement:
 		return "SRA"
+	case *ast.NopStatement:
+		return "NOP"
+	case *ast.CmpStatement:
+		return "CMP"
+	case *ast.RetStatement:
+		return "RET"
+	case *ast.MovStatement:
+		return "MOV"
+	case *ast.ClrStatement:
+		return "CLR"
 	default:
 		return ""
 	}
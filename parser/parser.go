@@ -6,8 +6,10 @@ package which provides lexical analysis (tokenizing) of ARC source code.
 package parser
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -37,6 +39,14 @@ type Parser struct {
 
 	unresolvedIdents map[string]*ast.Identifier
 	declaredLabels   map[string]*ast.LabelStatement
+	externIdents     map[string]*ast.ExternStatement
+
+	unresolvedConsts map[string]*ast.Identifier
+	declaredConsts   map[string]*ast.EquStatement
+
+	// assertIdents are the identifiers used by assertions. They refer to
+	// labels or constants, which is only known after parsing.
+	assertIdents []*ast.Identifier
 }
 
 // New returns a new instance of Parser.
@@ -52,6 +62,10 @@ func New(r io.Reader) *Parser {
 
 		unresolvedIdents: make(map[string]*ast.Identifier),
 		declaredLabels:   make(map[string]*ast.LabelStatement),
+		externIdents:     make(map[string]*ast.ExternStatement),
+
+		unresolvedConsts: make(map[string]*ast.Identifier),
+		declaredConsts:   make(map[string]*ast.EquStatement),
 	}
 	return p
 }
@@ -60,17 +74,29 @@ func New(r io.Reader) *Parser {
 // *os.File as argument instead of the more general io.Reader interface.
 // Therefore it will enhance token positions with the filename.
 func NewFileParser(f *os.File) *Parser {
+	return NewNamed(f, f.Name())
+}
+
+// NewNamed returns a new instance of Parser which enhances token positions
+// with the given filename. Statements parsed from a file included by another
+// one keep the name of the included file in their positions this way, so
+// diagnostics point to the file they originate from.
+func NewNamed(r io.Reader, filename string) *Parser {
 	// Init Parser with EOF token. This ensures functions must read the first
 	// token themselves.
 	p := &Parser{
-		scanner: scanner.NewFileScanner(f),
+		scanner: scanner.NewNamed(r, filename),
 
 		tok: token.EOF,
 		lit: "",
-		pos: token.Pos{Filename: f.Name()},
+		pos: token.Pos{Filename: filename},
 
 		unresolvedIdents: make(map[string]*ast.Identifier),
 		declaredLabels:   make(map[string]*ast.LabelStatement),
+		externIdents:     make(map[string]*ast.ExternStatement),
+
+		unresolvedConsts: make(map[string]*ast.Identifier),
+		declaredConsts:   make(map[string]*ast.EquStatement),
 	}
 	return p
 }
@@ -91,11 +117,39 @@ func ParseFile(filename string) (*ast.Program, error) {
 	return NewFileParser(src).Parse()
 }
 
+// ParseFileLimit parses the contents of a file into a Program AST object like
+// ParseFile, but rejects files larger than limit bytes. A limit of zero or less
+// doesn't limit the size. An error is returned if reading the file fails, it
+// exceeds the limit or parsing fails.
+func ParseFileLimit(filename string, limit int64) (*ast.Program, error) {
+	src, err := internal.ReadFile(filename, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNamed(bytes.NewReader(src), filename).Parse()
+}
+
 // ParseStatement parses a string into a Statement AST object.
 func ParseStatement(s string) (ast.Statement, error) {
 	return New(strings.NewReader(s)).ParseStatement()
 }
 
+// ParseExpression parses a string into an Expression AST object, like
+// "[x+4]" or "%r1 - 8". The square brackets are optional. An error is returned
+// if anything follows the expression.
+func ParseExpression(s string) (*ast.Expression, error) {
+	p := New(strings.NewReader(s))
+	exp, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if p.next(); p.tok != token.EOF {
+		return nil, p.newParseError(token.EOF)
+	}
+	return exp, nil
+}
+
 // Feed will provide the parser with a new scanner source, which effectively
 // adds a new source of tokens. This preserves the previous parsing context
 // while parsing new data.
@@ -112,16 +166,33 @@ func (p *Parser) Parse() (*ast.Program, error) {
 	// skipped.
 	p.scanIgnoreNewLine()
 
-	// Parse input line by line.
+	// Parse input line by line. The line of the previous statement is kept to
+	// count the blank lines in front of comments.
+	var (
+		prev     ast.Statement
+		prevLine int
+	)
 	for p.tok != token.EOF {
 		// Parse statement. An error will be added to the list of errors.
 		stmt, err := p.parseStatement(true)
 		if err != nil {
 			errs.Add(err)
 			p.skipStatement()
+			prev = nil
 			continue
 		}
 
+		// A comment on the same line as the previous statement trails it. A
+		// comment on a line of its own records the blank lines in front of it.
+		if comment, valid := stmt.(*ast.CommentStatement); valid {
+			if prev != nil && prev.Pos().Line == comment.Pos().Line {
+				comment.Statement = prev
+			} else if blank := comment.Pos().Line - prevLine - 1; blank > 0 {
+				comment.BlankLines = blank
+			}
+		}
+		prev, prevLine = stmt, stmt.Pos().Line
+
 		// Add statement to the programs list of statements.
 		prog.AddStatement(stmt)
 
@@ -134,6 +205,19 @@ func (p *Parser) Parse() (*ast.Program, error) {
 		err := &ParseError{Pos: ident.Pos(), Message: fmt.Sprintf("unresolved IDENTIFIER %q", lit)}
 		errs.Add(err)
 	}
+	for lit, ident := range p.unresolvedConsts {
+		err := &ParseError{Pos: ident.Pos(), Message: fmt.Sprintf("undefined constant %q", lit)}
+		errs.Add(err)
+	}
+	for _, ident := range p.assertIdents {
+		_, label := p.declaredLabels[ident.Name]
+		_, constant := p.declaredConsts[ident.Name]
+		_, extern := p.externIdents[ident.Name]
+		if !label && !constant && !extern {
+			err := &ParseError{Pos: ident.Pos(), Message: fmt.Sprintf("undefined label or constant %q", ident.Name)}
+			errs.Add(err)
+		}
+	}
 
 	// Generate errors for subroutine calls which call a label that doesn't
 	// point to another statement (but to an integer for example).
@@ -150,21 +234,48 @@ func (p *Parser) Parse() (*ast.Program, error) {
 			continue
 		}
 
-		// Generate an error if the soubroutines target label references an
-		// integer value.
-		// TODO: Improve this part: Don't just check for integers.
+		// Generate an error if the soubroutines target label references data,
+		// an integer value or a .word directive.
 		if ref, valid := subRoutine.Reference.(*ast.Integer); valid {
 			err := &ParseError{Pos: callStmt.Pos(), Message: fmt.Sprintf("impossible subroutine call to %q (references %s)", subRoutine.Ident, ref.Token)}
 			errs.Add(err)
+		} else if ref, valid := subRoutine.Reference.(*ast.WordStatement); valid {
+			err := &ParseError{Pos: callStmt.Pos(), Message: fmt.Sprintf("impossible subroutine call to %q (references %s)", subRoutine.Ident, ref.Token)}
+			errs.Add(err)
 		}
 	}
 
+	// Link .begin directives with their matching .end directives.
+	linkBeginEnd(prog)
+
 	// Sort errors.
 	errs.Sort()
 
 	return prog, errs.Return()
 }
 
+// linkBeginEnd links every .begin directive to its matching .end directive by
+// setting its end position. Programs can't be nested, so an .end closes the
+// .begin preceding it and a .begin before that .end is left unlinked. A .begin
+// without .end keeps its end position unset. Unbalanced directives are
+// reported by the directives check of vet, not by the parser.
+func linkBeginEnd(prog *ast.Program) {
+	var begin *ast.BeginStatement
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.BeginStatement:
+			if begin == nil {
+				begin = s
+			}
+		case *ast.EndStatement:
+			if begin != nil {
+				begin.EndPos = s.Pos()
+				begin = nil
+			}
+		}
+	}
+}
+
 // ParseStatement parses lexical tokens into a Statement AST object.
 func (p *Parser) ParseStatement() (stmt ast.Statement, err error) {
 	// Read the first token and parse and allow referenced label parsing.
@@ -177,6 +288,13 @@ func (p *Parser) ParseStatement() (stmt ast.Statement, err error) {
 // false. This is useful for avoiding recursive parsing of labels. Labels can't
 // reference another label.
 func (p *Parser) parseStatement(withLabel bool) (stmt ast.Statement, err error) {
+	// Keywords are reserved and can't name a label. Without this check the
+	// colon would be reported as an invalid operand.
+	if p.tok.IsKeyword() && p.peek() == token.COLON {
+		msg := fmt.Sprintf("cannot use keyword %q as a label name", p.lit)
+		return nil, &ParseError{Message: msg, Pos: p.pos}
+	}
+
 	switch p.tok {
 	case token.COMMENT:
 		return p.parseCommentStatement()
@@ -186,6 +304,16 @@ func (p *Parser) parseStatement(withLabel bool) (stmt ast.Statement, err error)
 		return p.parseEndStatement()
 	case token.ORG:
 		return p.parseOrgStatement()
+	case token.GLOBAL:
+		return p.parseGlobalStatement()
+	case token.EXTERN:
+		return p.parseExternStatement()
+	case token.WORD:
+		return p.parseWordStatement()
+	case token.EQU:
+		return p.parseEquStatement()
+	case token.ASSERT:
+		return p.parseAssertStatement()
 	case token.IDENT:
 		if !withLabel {
 			return &ast.LabelStatement{}, nil
@@ -237,6 +365,16 @@ func (p *Parser) parseStatement(withLabel bool) (stmt ast.Statement, err error)
 		return p.parseCallStatement()
 	case token.JMPL:
 		return p.parseJumpAndLinkStatement()
+	case token.NOP:
+		return p.parseNopStatement()
+	case token.CMP:
+		return p.parseCmpStatement()
+	case token.RET, token.RETL:
+		return p.parseRetStatement()
+	case token.MOV:
+		return p.parseMovStatement()
+	case token.CLR:
+		return p.parseClrStatement()
 	}
 
 	// We expect a comment, an identifier, a directive or a keyword.
@@ -290,12 +428,203 @@ func (p *Parser) parseEndStatement() (stmt *ast.EndStatement, err error) {
 func (p *Parser) parseOrgStatement() (stmt *ast.OrgStatement, err error) {
 	stmt = &ast.OrgStatement{Token: p.tok, Position: p.pos}
 
-	// The directive should be followed by an integer.
-	stmt.Value, err = p.parseInteger()
+	// The directive should be followed by an integer. Negative origins are
+	// not allowed.
+	stmt.Value, err = p.parseSignedInteger()
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Value.Value < 0 {
+		msg := fmt.Sprintf("invalid origin %s: address must not be negative", stmt.Value)
+		return nil, &ParseError{Message: msg, Pos: stmt.Value.Pos()}
+	}
+
+	// Finally we should see the end of the directive.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseGlobalStatement parses a GlobalStatement AST object.
+func (p *Parser) parseGlobalStatement() (stmt *ast.GlobalStatement, err error) {
+	stmt = &ast.GlobalStatement{Token: p.tok, Position: p.pos}
+
+	// The directive should be followed by the identifier of the exported
+	// label. The label must be declared in the program.
+	stmt.Ident, err = p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	// Finally we should see the end of the directive.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseExternStatement parses an ExternStatement AST object.
+func (p *Parser) parseExternStatement() (stmt *ast.ExternStatement, err error) {
+	stmt = &ast.ExternStatement{Token: p.tok, Position: p.pos}
+
+	// The directive should be followed by the identifier of the imported
+	// label.
+	if p.next(); p.tok != token.IDENT {
+		return nil, p.newParseError(token.IDENT)
+	}
+	stmt.Ident = &ast.Identifier{Token: p.tok, Position: p.pos, Name: p.lit}
+
+	// An imported label can't be declared in the program itself.
+	if decl, prs := p.declaredLabels[stmt.Ident.Name]; prs {
+		msg := fmt.Sprintf("extern label %q already declared: declaration at %s", stmt.Ident, decl.Pos().NoFile())
+		return nil, &ParseError{Message: msg, Pos: stmt.Pos()}
+	}
+
+	// Finally we should see the end of the directive.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Imported identifiers are resolved by the linker.
+	p.externIdents[stmt.Ident.Name] = stmt
+	delete(p.unresolvedIdents, stmt.Ident.Name)
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseEquStatement parses an EquStatement AST object.
+func (p *Parser) parseEquStatement() (stmt *ast.EquStatement, err error) {
+	stmt = &ast.EquStatement{Token: p.tok, Position: p.pos}
+
+	// The directive should be followed by the identifier of the constant.
+	if p.next(); p.tok != token.IDENT {
+		return nil, p.newParseError(token.IDENT)
+	}
+	stmt.Name = &ast.Identifier{Token: p.tok, Position: p.pos, Name: p.lit}
+
+	// A constant can't be declared twice and shares its name with labels.
+	if decl, prs := p.declaredConsts[stmt.Name.Name]; prs {
+		msg := fmt.Sprintf("constant %q already declared: previous declaration at %s", stmt.Name, decl.Pos().NoFile())
+		return nil, &ParseError{Message: msg, Pos: stmt.Pos()}
+	}
+	if decl, prs := p.declaredLabels[stmt.Name.Name]; prs {
+		msg := fmt.Sprintf("constant %q already declared as label at %s", stmt.Name, decl.Pos().NoFile())
+		return nil, &ParseError{Message: msg, Pos: stmt.Pos()}
+	}
+
+	// Next we should see a comma as separator between name and value.
+	if p.next(); p.tok != token.COMMA {
+		return nil, p.newParseError(token.COMMA)
+	}
+
+	// Next we should see the value of the constant.
+	stmt.Value, err = p.parseSignedInteger()
+	if err != nil {
+		return nil, err
+	}
+
+	// Finally we should see the end of the directive.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Declare the constant and remove its identifier from the list of
+	// unresolved constants.
+	p.declaredConsts[stmt.Name.Name] = stmt
+	delete(p.unresolvedConsts, stmt.Name.Name)
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseAssertStatement parses an AssertStatement AST object.
+func (p *Parser) parseAssertStatement() (stmt *ast.AssertStatement, err error) {
+	stmt = &ast.AssertStatement{Token: p.tok, Position: p.pos}
+
+	// The directive should be followed by a constant expression.
+	stmt.Left, err = p.parseConstantExpression()
 	if err != nil {
 		return nil, err
 	}
 
+	// The expression might be compared to a second one.
+	if p.next(); p.tok.IsComparison() {
+		stmt.Comparison = p.lit
+		stmt.Right, err = p.parseConstantExpression()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		p.unscan()
+	}
+
+	// Finally we should see the end of the directive.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseConstantExpression parses a sum of integers and identifiers and
+// creates a ConstantExpression AST object.
+func (p *Parser) parseConstantExpression() (exp *ast.ConstantExpression, err error) {
+	exp = &ast.ConstantExpression{}
+	for {
+		// We expect an integer or the identifier of a label or constant.
+		var op ast.Operand
+		if p.next(); isSignedInteger(p.tok) {
+			p.unscan()
+			if op, err = p.parseSignedInteger(); err != nil {
+				return nil, err
+			}
+		} else if p.tok == token.IDENT {
+			ident := &ast.Identifier{Token: p.tok, Position: p.pos, Name: p.lit}
+			p.assertIdents = append(p.assertIdents, ident)
+			op = ident
+		} else {
+			return nil, p.newParseError(token.INT, token.IDENT)
+		}
+		if len(exp.Operands) == 0 {
+			exp.Position = op.Pos()
+		}
+		exp.Operands = append(exp.Operands, op)
+
+		// The sum continues as long as an operator follows.
+		if p.next(); !p.tok.IsOperator() {
+			p.unscan()
+			return exp, nil
+		}
+		exp.Operators = append(exp.Operators, p.lit)
+	}
+}
+
+// parseWordStatement parses a WordStatement AST object.
+func (p *Parser) parseWordStatement() (stmt *ast.WordStatement, err error) {
+	stmt = &ast.WordStatement{Token: p.tok, Position: p.pos}
+
+	// The directive should be followed by one or more integers, separated by
+	// commas.
+	for {
+		val, err := p.parseSignedInteger()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Values = append(stmt.Values, val)
+
+		if p.next(); p.tok != token.COMMA {
+			p.unscan()
+			break
+		}
+	}
+
 	// Finally we should see the end of the directive.
 	if err := p.expectStatementEndOrComment(); err != nil {
 		return nil, err
@@ -318,6 +647,16 @@ func (p *Parser) parseLabelStatement() (stmt *ast.LabelStatement, err error) {
 		err := &ParseError{Message: msg, Pos: stmt.Pos()}
 		return nil, err
 	}
+	if ext, prs := p.externIdents[stmt.Ident.Name]; prs {
+		msg := fmt.Sprintf("label %q already declared as extern at %s", stmt.Ident, ext.Pos().NoFile())
+		err := &ParseError{Message: msg, Pos: stmt.Pos()}
+		return nil, err
+	}
+	if equ, prs := p.declaredConsts[stmt.Ident.Name]; prs {
+		msg := fmt.Sprintf("label %q already declared as constant at %s", stmt.Ident, equ.Pos().NoFile())
+		err := &ParseError{Message: msg, Pos: stmt.Pos()}
+		return nil, err
+	}
 
 	// Labels end with a colon (assignment).
 	if p.next(); p.tok != token.COLON {
@@ -326,9 +665,9 @@ func (p *Parser) parseLabelStatement() (stmt *ast.LabelStatement, err error) {
 
 	// We either want an integer or a statement.
 	// TODO: We need a string datatype!
-	if p.next(); p.tok == token.INT {
+	if p.next(); isSignedInteger(p.tok) {
 		p.unscan()
-		stmt.Reference, err = p.parseInteger()
+		stmt.Reference, err = p.parseSignedInteger()
 		if err != nil {
 			return nil, err
 		}
@@ -1021,6 +1360,111 @@ func (p *Parser) parseJumpAndLinkStatement() (stmt *ast.JumpAndLinkStatement, er
 	return stmt, nil
 }
 
+// parseNopStatement parses a NopStatement AST object.
+func (p *Parser) parseNopStatement() (stmt *ast.NopStatement, err error) {
+	stmt = &ast.NopStatement{Token: p.tok, Position: p.pos}
+
+	// The statement has no operands and ends right away.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseCmpStatement parses a CmpStatement AST object.
+func (p *Parser) parseCmpStatement() (stmt *ast.CmpStatement, err error) {
+	stmt = &ast.CmpStatement{Token: p.tok, Position: p.pos}
+
+	// First we should see the source register.
+	stmt.Source, err = p.parseRegister()
+	if err != nil {
+		return nil, err
+	}
+
+	// Next we should see a comma as separator between the operands.
+	if p.next(); p.tok != token.COMMA {
+		return nil, p.newParseError(token.COMMA)
+	}
+
+	// Then we should see the second operand.
+	stmt.Operand, err = p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	// Finally we should see the end of the statement.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseRetStatement parses a RetStatement AST object.
+func (p *Parser) parseRetStatement() (stmt *ast.RetStatement, err error) {
+	stmt = &ast.RetStatement{Token: p.tok, Position: p.pos}
+
+	// The statement has no operands and ends right away.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseMovStatement parses a MovStatement AST object.
+func (p *Parser) parseMovStatement() (stmt *ast.MovStatement, err error) {
+	stmt = &ast.MovStatement{Token: p.tok, Position: p.pos}
+
+	// First we should see the operand which is copied.
+	stmt.Operand, err = p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	// Next we should see a comma as separator between operand and destination.
+	if p.next(); p.tok != token.COMMA {
+		return nil, p.newParseError(token.COMMA)
+	}
+
+	// The last needed information is the destination register.
+	stmt.Destination, err = p.parseRegister()
+	if err != nil {
+		return nil, err
+	}
+
+	// Finally we should see the end of the statement.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
+// parseClrStatement parses a ClrStatement AST object.
+func (p *Parser) parseClrStatement() (stmt *ast.ClrStatement, err error) {
+	stmt = &ast.ClrStatement{Token: p.tok, Position: p.pos}
+
+	// The only operand is the register which is cleared.
+	stmt.Destination, err = p.parseRegister()
+	if err != nil {
+		return nil, err
+	}
+
+	// Finally we should see the end of the statement.
+	if err := p.expectStatementEndOrComment(); err != nil {
+		return nil, err
+	}
+
+	// Return the successfully parsed statement.
+	return stmt, nil
+}
+
 // parseIdent parses an identifier and creates an Identifier AST object.
 func (p *Parser) parseIdent() (*ast.Identifier, error) {
 	if p.next(); p.tok != token.IDENT {
@@ -1030,7 +1474,9 @@ func (p *Parser) parseIdent() (*ast.Identifier, error) {
 	// If the identifier has not been declared yet, we add it to the list of
 	// unresolved identifiers.
 	ident := &ast.Identifier{Token: p.tok, Position: p.pos, Name: p.lit}
-	if _, prs := p.declaredLabels[p.lit]; !prs {
+	_, declared := p.declaredLabels[p.lit]
+	_, extern := p.externIdents[p.lit]
+	if !declared && !extern {
 		p.unresolvedIdents[p.lit] = ident
 	}
 	return ident, nil
@@ -1041,31 +1487,71 @@ func (p *Parser) parseRegister() (*ast.Register, error) {
 	if p.next(); p.tok != token.REG {
 		return nil, p.newParseError(token.REG)
 	}
-	return &ast.Register{Name: p.lit}, nil
+	return &ast.Register{Token: p.tok, Position: p.pos, Name: p.lit}, nil
 }
 
-// parseInteger parses an integer and returns an Integer AST object.
-func (p *Parser) parseInteger() (*ast.Integer, error) {
-	if p.next(); p.tok != token.INT {
+// isSignedInteger reports whether a token starts a signed integer, which is
+// an integer or the sign preceding it.
+func isSignedInteger(tok token.Token) bool {
+	return tok == token.INT || tok == token.MINUS || tok == token.PLUS
+}
+
+// parseSignedInteger parses an integer, which might be signed, and returns an
+// Integer AST object. It is used wherever an integer is expected, so a sign is
+// accepted in all of these places alike.
+func (p *Parser) parseSignedInteger() (*ast.Integer, error) {
+	// The integer might be signed by a leading minus or plus. A plus doesn't
+	// change the value and is dropped from the literal.
+	p.next()
+	pos, lit := p.pos, ""
+	if p.tok == token.MINUS {
+		lit = p.lit
+		p.next()
+	} else if p.tok == token.PLUS {
+		p.next()
+	}
+	if p.tok != token.INT {
 		return nil, p.newParseError(token.INT)
 	}
-	i, err := strconv.ParseInt(p.lit, 0, 32)
-	if err != nil {
+	lit += p.lit
+
+	i, err := integerValue(lit)
+	if err != nil || i < math.MinInt32 || i > math.MaxInt32 {
 		return nil, &ParseError{
-			Message: fmt.Sprintf("INTEGER %q out of 32 bit range", p.lit),
-			Pos:     p.pos,
+			Message: fmt.Sprintf("INTEGER %q out of 32 bit range", lit),
+			Pos:     pos,
 		}
 	}
-	return &ast.Integer{Token: p.tok, Position: p.pos, Value: int32(i), Literal: p.lit}, nil
+	return &ast.Integer{Token: p.tok, Position: pos, Value: int32(i), Literal: lit}, nil
+}
+
+// integerValue returns the value of an integer literal, which might be signed by
+// a leading minus. Digit separators are stripped and character literals stand
+// for the code point of the character.
+func integerValue(lit string) (int64, error) {
+	sign, abs := int64(1), lit
+	if strings.HasPrefix(abs, "-") {
+		sign, abs = -1, abs[1:]
+	}
+	if ch, valid := scanner.CharValue(abs); valid {
+		return sign * int64(ch), nil
+	}
+	return strconv.ParseInt(strings.Replace(lit, "_", "", -1), 0, 64)
 }
 
-// parseSIMM13 parses a SIMM13 integer.
-func (p *Parser) parseSIMM13() (*ast.Integer, error) {
+// parseSIMM13 parses the magnitude of a SIMM13 integer. The sign is given by
+// the operator preceding it, so the magnitude of a negative offset may be one
+// larger than the one of a positive offset.
+func (p *Parser) parseSIMM13(negative bool) (*ast.Integer, error) {
 	if p.next(); p.tok != token.INT {
 		return nil, p.newParseError(token.INT)
 	}
-	i, err := strconv.ParseUint(p.lit, 0, 13)
-	if err != nil {
+	max := int64(4095)
+	if negative {
+		max = 4096
+	}
+	i, err := integerValue(p.lit)
+	if err != nil || i < 0 || i > max {
 		return nil, &ParseError{
 			Message: fmt.Sprintf("INTEGER %q is not a valid SIMM13", p.lit),
 			Pos:     p.pos,
@@ -1103,33 +1589,30 @@ func (p *Parser) parseExpression() (exp *ast.Expression, err error) {
 	}
 
 	// After the base we either expect an operator or a closing bracket. The
-	// closing bracket is not allowed if there was no opening bracket.
-	if p.next(); !sawBracket && p.tok == token.EOF {
-		return exp, nil
-	} else if !p.tok.IsOperator() && p.tok != token.RBRACKET {
-		return nil, p.newParseError(token.PLUS, token.MINUS, token.RBRACKET)
-	} else if !sawBracket && p.tok == token.RBRACKET {
-		return nil, p.newParseError(token.PLUS, token.MINUS)
-	}
-
-	// If we don't see the closing square bracket, we expect to see an
-	// operator.
-	if p.tok.IsOperator() {
+	// closing bracket is not allowed if there was no opening bracket. Without
+	// brackets, the expression simply ends if no operator follows.
+	if p.next(); p.tok.IsOperator() {
 		exp.Operator = p.lit
 
 		// We expect the offset value.
-		exp.Offset, err = p.parseSIMM13()
+		exp.Offset, err = p.parseSIMM13(p.tok == token.MINUS)
 		if err != nil {
 			return nil, err
 		}
+	} else if sawBracket && p.tok != token.RBRACKET {
+		return nil, p.newParseError(token.PLUS, token.MINUS, token.RBRACKET)
+	} else if !sawBracket && p.tok == token.RBRACKET {
+		return nil, p.newParseError(token.PLUS, token.MINUS)
 	} else {
 		p.unscan()
 	}
 
 	// The expression must close with a right square bracket if one was
 	// specified.
-	if p.next(); sawBracket && p.tok != token.RBRACKET {
-		return nil, p.newParseError(token.RBRACKET)
+	if sawBracket {
+		if p.next(); p.tok != token.RBRACKET {
+			return nil, p.newParseError(token.RBRACKET)
+		}
 	}
 
 	return exp, nil
@@ -1141,21 +1624,30 @@ func (p *Parser) parseOperand() (ast.Operand, error) {
 
 	// Checking errors of the parseRegister function isn't required here,
 	// because we have already checked for the correct token. But the
-	// parseInteger function needs checking because the literal can still be
+	// parseSignedInteger function needs checking because the literal can still be
 	// overflowing the integer width.
 	if p.next(); p.tok == token.REG {
 		p.unscan()
 		reg, _ := p.parseRegister()
 		op = reg
-	} else if p.tok == token.INT {
+	} else if isSignedInteger(p.tok) {
 		p.unscan()
-		i, err := p.parseInteger()
+		i, err := p.parseSignedInteger()
 		if err != nil {
 			return nil, err
 		}
 		op = i
+	} else if p.tok == token.IDENT {
+		// Identifiers used as operands refer to constants. If the constant
+		// has not been declared yet, we add it to the list of unresolved
+		// constants.
+		ident := &ast.Identifier{Token: p.tok, Position: p.pos, Name: p.lit}
+		if _, declared := p.declaredConsts[p.lit]; !declared {
+			p.unresolvedConsts[p.lit] = ident
+		}
+		op = ident
 	} else {
-		return nil, p.newParseError(token.INT, token.REG)
+		return nil, p.newParseError(token.INT, token.REG, token.IDENT)
 	}
 
 	return op, nil
@@ -1176,7 +1668,7 @@ func (p *Parser) parseMemoryLocation() (ast.MemoryLocation, error) {
 		}
 		memLoc = exp
 	} else if p.tok == token.REG {
-		memLoc = &ast.Register{Name: p.lit}
+		memLoc = &ast.Register{Token: p.tok, Position: p.pos, Name: p.lit}
 	} else {
 		return nil, p.newParseError(token.LBRACKET, token.REG)
 	}
@@ -1197,10 +1689,10 @@ func (p *Parser) expectStatementEnd() error {
 // comment. It will error if the next token is not a comment, NL (newline) or
 // EOF token.
 func (p *Parser) expectStatementEndOrComment() error {
-	if p.next(); p.tok == token.COMMENT {
-		p.unscan()
-	} else if p.tok != token.NL && p.tok != token.EOF {
+	if p.next(); !p.tok.EndsStatement() {
 		return p.newParseError(token.COMMENT, token.NL, token.EOF)
+	} else if p.tok == token.COMMENT {
+		p.unscan()
 	}
 	return nil
 }
@@ -1248,6 +1740,17 @@ func (p *Parser) next() {
 // unscan pushes the previously read token back onto the buffer.
 func (p *Parser) unscan() { p.buf.n = 1 }
 
+// peek returns the next non-whitespace token without consuming it. The current
+// token is kept.
+func (p *Parser) peek() token.Token {
+	tok, lit, pos := p.tok, p.lit, p.pos
+	p.next()
+	next := p.tok
+	p.unscan()
+	p.tok, p.lit, p.pos = tok, lit, pos
+	return next
+}
+
 // ParseError represents an error that occurred during parsing.
 type ParseError struct {
 	Message  string
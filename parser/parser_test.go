@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
 	"reflect"
@@ -184,7 +185,7 @@ func TestParse(t *testing.T) {
 		ld %r3, %r4
 		.end`,
 			err: `3:6: found KEYWORD "ld", expected "[", REGISTER
-7:6: found IDENTIFIER "x", expected INTEGER, "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+7:6: found IDENTIFIER "x", expected INTEGER, "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 		{
 			prog: `
@@ -203,6 +204,30 @@ func TestParse(t *testing.T) {
 			err: `3:8: unresolved IDENTIFIER "x"
 4:13: unresolved IDENTIFIER "y"`,
 		},
+		{
+			prog: `
+			.begin
+			.extern x
+			call x
+			ld [y], %r1
+			.end`,
+			err: `5:8: unresolved IDENTIFIER "y"`,
+		},
+		{
+			prog: `
+			.global x
+			.extern y
+			x: 25`,
+		},
+		{
+			prog: `
+			x: 25
+			.extern x
+			.extern y
+			y: 25`,
+			err: `3:4: extern label "x" already declared: declaration at 2:4
+5:4: label "y" already declared as extern at 4:4`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -218,6 +243,125 @@ func TestParse(t *testing.T) {
 	}
 }
 
+// TestParse_BeginEnd validates that the .begin directive is linked to its
+// matching .end directive and that unbalanced directives aren't errors.
+func TestParse_BeginEnd(t *testing.T) {
+	prog, err := Parse(validProg)
+	ok(t, err)
+
+	var begin *ast.BeginStatement
+	var end *ast.EndStatement
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.BeginStatement:
+			begin = s
+		case *ast.EndStatement:
+			end = s
+		}
+	}
+	assert(t, begin != nil && end != nil, "expected .begin and .end")
+	equals(t, begin.EndPos, end.Pos())
+	equals(t, token.Pos{Line: 20, Char: 1}, begin.EndPos)
+
+	// Unbalanced directives still parse, leaving them to the directives
+	// check of vet.
+	prog, err = Parse(".begin\n.org 2048\nld %r1, %r2\n")
+	ok(t, err)
+	equals(t, token.Pos{}, prog.Statements[0].(*ast.BeginStatement).EndPos)
+
+	prog, err = Parse(".end\n.begin\n.begin\nld %r1, %r2\n.end\n.end")
+	ok(t, err)
+	equals(t, token.Pos{Line: 5, Char: 1}, prog.Statements[1].(*ast.BeginStatement).EndPos)
+	equals(t, token.Pos{}, prog.Statements[2].(*ast.BeginStatement).EndPos)
+}
+
+// TestParse_Linkage validates that a label imported by one program and
+// exported by another is resolved by neither parser but recorded with the
+// respective visibility.
+func TestParse_Linkage(t *testing.T) {
+	main, err := Parse(`
+	.begin
+	.extern sum
+	call sum
+	.end`)
+	ok(t, err)
+	equals(t, main.Linkage(), map[string]ast.Visibility{"sum": ast.Extern})
+
+	lib, err := Parse(`
+	.begin
+	.global sum
+	sum: add %r1, %r2, %r3
+	jmpl [%r15+4], %r0
+	.end`)
+	ok(t, err)
+	equals(t, lib.Linkage(), map[string]ast.Visibility{"sum": ast.Global})
+}
+
+// TestParse_TrailingComments validates that comments on the same line as a
+// directive or instruction are attached to it.
+func TestParse_TrailingComments(t *testing.T) {
+	src := ".begin ! start\n.org 0x800 ! code section\n! own line\nx: ld [x], %r1 !load\n.end"
+	prog, err := Parse(src)
+	ok(t, err)
+	equals(t, len(prog.Statements), 8)
+
+	tests := []struct {
+		idx  int
+		text string
+		stmt ast.Statement
+	}{
+		{1, "! start", prog.Statements[0]},
+		{3, "! code section", prog.Statements[2]},
+		{4, "! own line", nil},
+		{6, "!load", prog.Statements[5]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			comment, valid := prog.Statements[tt.idx].(*ast.CommentStatement)
+			assert(t, valid, "expected comment but got %T", prog.Statements[tt.idx])
+			equals(t, comment.Text, tt.text)
+			equals(t, comment.Statement, tt.stmt)
+		})
+	}
+	_, isOrg := prog.Statements[2].(*ast.OrgStatement)
+	assert(t, isOrg, "expected .org but got %T", prog.Statements[2])
+}
+
+// TestParse_CommentLines validates the classification of the comments of the
+// sample program into standalone and trailing comments and the blank lines
+// recorded in front of standalone comments.
+func TestParse_CommentLines(t *testing.T) {
+	prog, err := Parse(validProg)
+	ok(t, err)
+
+	type comment struct {
+		Text       string
+		Standalone bool
+		BlankLines int
+	}
+	var comments []comment
+	for _, stmt := range prog.Statements {
+		if c, valid := stmt.(*ast.CommentStatement); valid {
+			comments = append(comments, comment{c.Text, c.Standalone(), c.BlankLines})
+		}
+	}
+	equals(t, []comment{
+		{"! main.arc", true, 1},
+		{"! This is a valid ARC sample program.", true, 0},
+		{"! Load x.", false, 0},
+		{"! Load y.", false, 0},
+		{"! Always branch to exit routine.", false, 0},
+		{"! jmpl %r15 + 4, %r6", false, 0},
+		{"! Start data section at 0x1000.", true, 1},
+	}, comments)
+
+	// Consecutive blank lines are counted.
+	prog, err = Parse("nop\n\n\n! two\n\n! one")
+	ok(t, err)
+	equals(t, 2, prog.Statements[1].(*ast.CommentStatement).BlankLines)
+	equals(t, 1, prog.Statements[2].(*ast.CommentStatement).BlankLines)
+}
+
 // TestParseFile will validate the correct parsing of a file containing a
 // complete program.
 func TestParseFile(t *testing.T) {
@@ -247,6 +391,196 @@ func TestParseFile(t *testing.T) {
 	}
 }
 
+// TestNewNamed validates that statements parsed from an included file carry
+// the name of that file in their positions when spliced into the including
+// program.
+func TestNewNamed(t *testing.T) {
+	main, err := NewNamed(strings.NewReader(".begin\nld %r1, %r2\n.end"), "main.arc").Parse()
+	ok(t, err)
+	inc, err := NewNamed(strings.NewReader("\nst %r2, %r3"), "inc.arc").Parse()
+	ok(t, err)
+
+	stmts := append([]ast.Statement{}, main.Statements[:2]...)
+	stmts = append(stmts, inc.Statements...)
+	stmts = append(stmts, main.Statements[2:]...)
+
+	var got []string
+	for _, stmt := range stmts {
+		got = append(got, stmt.Pos().String())
+	}
+	equals(t, []string{"main.arc:1:1", "main.arc:2:1", "inc.arc:2:1", "main.arc:3:1"}, got)
+
+	_, err = NewNamed(strings.NewReader("st %r2"), "inc.arc").Parse()
+	assert(t, err != nil, "expected error but got nil")
+	assert(t, strings.HasPrefix(err.Error(), "inc.arc:1:"), "expected error in inc.arc but got %q", err)
+}
+
+// TestProgram_Comments validates that the comments of a program are collected
+// in order and the comment blocks documenting statements are found.
+func TestProgram_Comments(t *testing.T) {
+	prog, err := Parse(validProg)
+	ok(t, err)
+
+	var texts []string
+	for _, comment := range prog.Comments() {
+		texts = append(texts, comment.Text)
+	}
+	equals(t, []string{
+		"! main.arc",
+		"! This is a valid ARC sample program.",
+		"! Load x.",
+		"! Load y.",
+		"! Always branch to exit routine.",
+		"! jmpl %r15 + 4, %r6",
+		"! Start data section at 0x1000.",
+	}, texts)
+
+	// The header documents the .begin directive, the comment above the data
+	// section documents the .org directive. The trailing comment of the last
+	// instruction doesn't document anything.
+	begin, data, main := prog.Statements[2], prog.Statements[17], prog.Statements[4]
+	equals(t, ".begin", begin.String())
+	equals(t, ".org 0x1000", data.String())
+	equals(t, prog.Comments()[:2], prog.Doc(begin))
+	equals(t, prog.Comments()[6:], prog.Doc(data))
+	equals(t, 0, len(prog.Doc(main)))
+}
+
+// TestParseStatement_String verifies that the string representation of a parsed
+// statement parses to the same statement again, so formatted programs
+// round-trip through the parser.
+func TestParseStatement_String(t *testing.T) {
+	tests := []string{
+		"ld [%r1+4], %r2",
+		"st %r2, [x]",
+		"add %r1, 5, %r2",
+		"addcc %r1, %r2, %r3",
+		"sub %r1, -1, %r2",
+		"subcc %r1, 1, %r1",
+		"and %r1, 0xff, %r2",
+		"andcc %r1, %r2, %r3",
+		"or %r1, %r2, %r3",
+		"orcc %r1, %r2, %r3",
+		"orn %r1, %r2, %r3",
+		"orncc %r1, %r2, %r3",
+		"xor %r1, %r2, %r3",
+		"xorcc %r1, %r2, %r3",
+		"sll %r1, 4, %r2",
+		"sra %r1, %r2, %r3",
+		"jmpl [%r15+4], %r0",
+		"cmp %r1, 0",
+		"mov 5, %r1",
+		".assert 1",
+		".assert 4 - 2 + 0x10 >= 18",
+	}
+
+	for _, str := range tests {
+		t.Run(str, func(t *testing.T) {
+			stmt, err := ParseStatement(str)
+			ok(t, err)
+			equals(t, str, stmt.String())
+			again, err := ParseStatement(stmt.String())
+			ok(t, err)
+			equals(t, stmt.String(), again.String())
+			ast.AssertEqual(t, stmt, again)
+		})
+	}
+}
+
+// TestWalk validates that walking a program visits every statement, operand,
+// expression and identifier.
+func TestWalk(t *testing.T) {
+	prog, err := Parse(validProg)
+	ok(t, err)
+
+	counts := make(map[string]int)
+	ast.Walk(prog, func(n ast.Node) bool {
+		counts[reflect.TypeOf(n).Elem().Name()]++
+		return true
+	})
+	equals(t, map[string]int{
+		"CommentStatement": 7,
+		"BeginStatement":   1,
+		"EndStatement":     1,
+		"OrgStatement":     2,
+		"LabelStatement":   5,
+		"LoadStatement":    3,
+		"StoreStatement":   1,
+		"AddStatement":     1,
+		"SubCCStatement":   1,
+		"SLLStatement":     1,
+		"BAStatement":      1,
+		"Expression":       4,
+		"Identifier":       10,
+		"Register":         11,
+		"Integer":          7,
+	}, counts)
+
+	// Returning false skips the children of a node, so only the identifiers
+	// declared by labels are visited.
+	var labels int
+	ast.Walk(prog, func(n ast.Node) bool {
+		if _, valid := n.(*ast.Identifier); valid {
+			labels++
+		}
+		_, isLabel := n.(*ast.LabelStatement)
+		return isLabel
+	})
+	equals(t, 5, labels)
+}
+
+// TestProgram_JSON validates that a program round-trips through its JSON
+// representation, including positions, integer literals and the statements
+// comments trail.
+func TestProgram_JSON(t *testing.T) {
+	prog, err := Parse(validProg)
+	ok(t, err)
+	data, err := json.Marshal(prog)
+	ok(t, err)
+
+	res := &ast.Program{}
+	ok(t, json.Unmarshal(data, res))
+	equals(t, prog, res)
+	equals(t, prog.String(), res.String())
+	// Trailing comments reference the decoded statement, not a copy of it.
+	for i, stmt := range res.Statements {
+		if comment, valid := stmt.(*ast.CommentStatement); valid && comment.Statement != nil {
+			equals(t, true, res.Statements[i-1] == comment.Statement)
+		}
+	}
+
+	err = json.Unmarshal([]byte(`{"Statements":[{"type":"FooStatement"}]}`), res)
+	equals(t, `statement 0: unknown node type "FooStatement"`, err.Error())
+}
+
+// TestParseExpression validates the parsing of standalone expressions.
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		str string
+		exp string
+		err string
+	}{
+		{str: "%r1 + 4", exp: "[%r1+4]"},
+		{str: "[x]", exp: "[x]"},
+		{str: "[x - 8]", exp: "[x-8]"},
+		{str: "[x", err: `1:3: found EOF, expected "+", "-", "]"`},
+		{str: "x y", err: `1:3: found IDENTIFIER "y", expected EOF`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			exp, err := ParseExpression(tt.str)
+			if tt.err != "" {
+				assert(t, err != nil, "expected error but got nil")
+				equals(t, tt.err, err.Error())
+				return
+			}
+			ok(t, err)
+			equals(t, tt.exp, exp.String())
+		})
+	}
+}
+
 // TestParser_ParseCommentStatement validates the correct parsing of the begin directive.
 func TestParser_ParseCommentStatement(t *testing.T) {
 	tests := []struct {
@@ -279,7 +613,7 @@ func TestParser_ParseBeginStatement(t *testing.T) {
 		err  string
 	}{
 		{str: ".begin", stmt: &ast.BeginStatement{Token: token.BEGIN, Position: testPos}},
-		{str: ".beg", err: `1:1: found ILLEGAL ".beg", expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`},
+		{str: ".beg", err: `1:1: found ILLEGAL ".beg", expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`},
 		{str: "begin", err: `1:6: found EOF, expected ":"`},
 		{str: ".begin 123", err: `1:8: found INTEGER "123", expected COMMENT, NEWLINE, EOF`},
 	}
@@ -305,7 +639,7 @@ func TestParser_ParseEndStatement(t *testing.T) {
 		err  string
 	}{
 		{str: ".end", stmt: &ast.EndStatement{Token: token.END, Position: testPos}},
-		{str: ".ed", err: `1:1: found ILLEGAL ".ed", expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`},
+		{str: ".ed", err: `1:1: found ILLEGAL ".ed", expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`},
 		{str: "end", err: `1:4: found EOF, expected ":"`},
 		{str: ".end 123", err: `1:6: found INTEGER "123", expected COMMENT, NEWLINE, EOF`},
 	}
@@ -331,9 +665,12 @@ func TestParser_ParseOrgStatement(t *testing.T) {
 		err  string
 	}{
 		{str: ".org 2048", stmt: &ast.OrgStatement{Token: token.ORG, Position: testPos, Value: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 2048, Literal: "2048"}}},
+		{str: ".org 2_048", stmt: &ast.OrgStatement{Token: token.ORG, Position: testPos, Value: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 2048, Literal: "2_048"}}},
 		{str: ".org 2048 128", err: `1:11: found INTEGER "128", expected COMMENT, NEWLINE, EOF`},
 		{str: ".org", err: `1:5: found EOF, expected INTEGER`},
-		{str: ".og", err: `1:1: found ILLEGAL ".og", expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`},
+		{str: ".org -4", err: `1:6: invalid origin -4: address must not be negative`},
+		{str: ".org +4", stmt: &ast.OrgStatement{Token: token.ORG, Position: testPos, Value: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 4, Literal: "4"}}},
+		{str: ".og", err: `1:1: found ILLEGAL ".og", expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`},
 		{str: "org", err: `1:4: found EOF, expected ":"`},
 	}
 
@@ -350,6 +687,251 @@ func TestParser_ParseOrgStatement(t *testing.T) {
 	}
 }
 
+// TestParser_ParseGlobalStatement validates the correct parsing of the global
+// directive.
+func TestParser_ParseGlobalStatement(t *testing.T) {
+	tests := []struct {
+		str  string
+		stmt ast.Statement
+		err  string
+	}{
+		{str: ".global main", stmt: &ast.GlobalStatement{Token: token.GLOBAL, Position: testPos, Ident: &ast.Identifier{Token: token.IDENT, Position: posAfter(9), Name: "main"}}},
+		{str: ".global 2048", err: `1:9: found INTEGER "2048", expected IDENTIFIER`},
+		{str: ".global main x", err: `1:14: found IDENTIFIER "x", expected COMMENT, NEWLINE, EOF`},
+		{str: ".global", err: `1:8: found EOF, expected IDENTIFIER`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.str)
+			if globalStmt, valid := tt.stmt.(*ast.GlobalStatement); valid {
+				ok(t, err)
+				equals(t, stmt, globalStmt)
+			} else {
+				equals(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
+
+// TestParser_ParseExternStatement validates the correct parsing of the extern
+// directive.
+func TestParser_ParseExternStatement(t *testing.T) {
+	tests := []struct {
+		str  string
+		stmt ast.Statement
+		err  string
+	}{
+		{str: ".extern sum", stmt: &ast.ExternStatement{Token: token.EXTERN, Position: testPos, Ident: &ast.Identifier{Token: token.IDENT, Position: posAfter(9), Name: "sum"}}},
+		{str: ".extern %r1", err: `1:9: found REGISTER "%r1", expected IDENTIFIER`},
+		{str: ".extern sum x", err: `1:13: found IDENTIFIER "x", expected COMMENT, NEWLINE, EOF`},
+		{str: ".extern", err: `1:8: found EOF, expected IDENTIFIER`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.str)
+			if externStmt, valid := tt.stmt.(*ast.ExternStatement); valid {
+				ok(t, err)
+				equals(t, stmt, externStmt)
+			} else {
+				equals(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
+
+// TestParser_ParseEquStatement validates the correct parsing of the equ
+// directive and the use of constants as operands.
+func TestParser_ParseEquStatement(t *testing.T) {
+	tests := []struct {
+		str  string
+		stmt ast.Statement
+		err  string
+	}{
+		{str: ".equ SIZE, 16", stmt: &ast.EquStatement{Token: token.EQU, Position: testPos,
+			Name:  &ast.Identifier{Token: token.IDENT, Position: posAfter(6), Name: "SIZE"},
+			Value: &ast.Integer{Token: token.INT, Position: posAfter(12), Value: 16, Literal: "16"},
+		}},
+		{str: ".equ N, -4", stmt: &ast.EquStatement{Token: token.EQU, Position: testPos,
+			Name:  &ast.Identifier{Token: token.IDENT, Position: posAfter(6), Name: "N"},
+			Value: &ast.Integer{Token: token.INT, Position: posAfter(9), Value: -4, Literal: "-4"},
+		}},
+		{str: ".equ N, +4", stmt: &ast.EquStatement{Token: token.EQU, Position: testPos,
+			Name:  &ast.Identifier{Token: token.IDENT, Position: posAfter(6), Name: "N"},
+			Value: &ast.Integer{Token: token.INT, Position: posAfter(9), Value: 4, Literal: "4"},
+		}},
+		{str: ".equ 16", err: `1:6: found INTEGER "16", expected IDENTIFIER`},
+		{str: ".equ SIZE 16", err: `1:11: found INTEGER "16", expected ","`},
+		{str: ".equ SIZE, x", err: `1:12: found IDENTIFIER "x", expected INTEGER`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.str)
+			if equStmt, valid := tt.stmt.(*ast.EquStatement); valid {
+				ok(t, err)
+				equals(t, stmt, equStmt)
+			} else {
+				equals(t, err.Error(), tt.err)
+			}
+		})
+	}
+
+	// Constants can be used as operands before and after their declaration.
+	prog, err := Parse("add %r1, SIZE, %r2\n.equ SIZE, 16\nsub %r2, SIZE, %r3")
+	ok(t, err)
+	equals(t, map[string]int32{"SIZE": 16}, prog.Constants())
+
+	// Constants must be declared and can't be redeclared.
+	_, err = Parse("add %r1, SIZE, %r2")
+	equals(t, `1:10: undefined constant "SIZE"`, err.Error())
+	_, err = Parse(".equ SIZE, 16\n.equ SIZE, 32")
+	equals(t, `2:1: constant "SIZE" already declared: previous declaration at 1:1`, err.Error())
+	_, err = Parse("x: 1\n.equ x, 32")
+	equals(t, `2:1: constant "x" already declared as label at 1:1`, err.Error())
+	_, err = Parse(".equ x, 32\nx: 1")
+	equals(t, `2:1: label "x" already declared as constant at 1:1`, err.Error())
+}
+
+// TestParser_ParseKeywordLabel validates that keywords can't name a label.
+func TestParser_ParseKeywordLabel(t *testing.T) {
+	tests := []struct {
+		str string
+		err string
+	}{
+		{"add: 5", `1:1: cannot use keyword "add" as a label name`},
+		{"ld: 3", `1:1: cannot use keyword "ld" as a label name`},
+		{"MOV : 3", `1:1: cannot use keyword "MOV" as a label name`},
+		{"x: ba: 3", `1:4: cannot use keyword "ba" as a label name`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			_, err := ParseStatement(tt.str)
+			equals(t, tt.err, err.Error())
+		})
+	}
+
+	// The statements after the misused keyword are parsed as usual.
+	_, err := Parse("add: 5\nadd %r1, 1, %r2\nld: 3")
+	equals(t, "1:1: cannot use keyword \"add\" as a label name\n3:1: cannot use keyword \"ld\" as a label name", err.Error())
+}
+
+// TestParser_ParseSectionLabel validates that a label can name the section
+// started by an .org directive.
+func TestParser_ParseSectionLabel(t *testing.T) {
+	stmt, err := ParseStatement("data: .org 3000 ! array")
+	ok(t, err)
+	label, valid := stmt.(*ast.LabelStatement)
+	assert(t, valid, "expected label but got %T", stmt)
+	org, valid := label.Reference.(*ast.OrgStatement)
+	assert(t, valid, "expected .org but got %T", label.Reference)
+	equals(t, int32(3000), org.Value.Value)
+	equals(t, "data: .org 3000", stmt.String())
+
+	// Branches and memory instructions can reference the section.
+	_, err = Parse("ld [data], %r1\nba data\ndata: .org 3000\n.word 1")
+	ok(t, err)
+}
+
+// TestParser_ParseBranchLabel validates that branches can be labeled, even
+// with the label as their own target.
+func TestParser_ParseBranchLabel(t *testing.T) {
+	prog, err := Parse("loop: ba loop\nwait: bne loop")
+	ok(t, err)
+	label, valid := prog.Statements[0].(*ast.LabelStatement)
+	assert(t, valid, "expected label but got %T", prog.Statements[0])
+	ba, valid := label.Reference.(*ast.BAStatement)
+	assert(t, valid, "expected ba but got %T", label.Reference)
+	equals(t, "loop", ba.Target.Name)
+	equals(t, "wait: bne loop", prog.Statements[1].String())
+}
+
+// TestParser_ParseAssertStatement validates the parsing of assertions and
+// their constant expressions.
+func TestParser_ParseAssertStatement(t *testing.T) {
+	prog, err := Parse(".equ CODE, 2048\n.assert STACK - CODE >= 0x100\n.org 4096\nSTACK: 0")
+	ok(t, err)
+	stmt := prog.Statements[1].(*ast.AssertStatement)
+	equals(t, ".assert STACK - CODE >= 0x100", stmt.String())
+	equals(t, token.Pos{Line: 2, Char: 9}, stmt.Left.Pos())
+	equals(t, []string{"-"}, stmt.Left.Operators)
+	equals(t, ">=", stmt.Comparison)
+	equals(t, int32(0x100), stmt.Right.Operands[0].(*ast.Integer).Value)
+
+	// Integers might be signed like everywhere else.
+	prog, err = Parse(".assert +4 == 4\n.assert 1 - -1 == 2")
+	ok(t, err)
+	equals(t, ".assert 4 == 4", prog.Statements[0].String())
+	equals(t, ".assert 1 - -1 == 2", prog.Statements[1].String())
+
+	tests := []struct {
+		str string
+		err string
+	}{
+		{".assert", "1:8: found EOF, expected INTEGER, IDENTIFIER"},
+		{".assert 1 +", "1:11: found EOF, expected INTEGER, IDENTIFIER"},
+		{".assert 1 <", "1:11: found EOF, expected INTEGER, IDENTIFIER"},
+		{".assert 1 2", `1:11: found INTEGER "2", expected COMMENT, NEWLINE, EOF`},
+		{".assert %r1", `1:9: found REGISTER "%r1", expected INTEGER, IDENTIFIER`},
+		{".assert x == 1", `1:9: undefined label or constant "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			_, err := Parse(tt.str)
+			equals(t, tt.err, err.Error())
+		})
+	}
+}
+
+// TestParser_ParseWordStatement validates the correct parsing of the word
+// directive.
+func TestParser_ParseWordStatement(t *testing.T) {
+	tests := []struct {
+		str  string
+		stmt ast.Statement
+		err  string
+	}{
+		{str: ".word 1, 2, 3", stmt: &ast.WordStatement{Token: token.WORD, Position: testPos, Values: []*ast.Integer{
+			{Token: token.INT, Position: posAfter(7), Value: 1, Literal: "1"},
+			{Token: token.INT, Position: posAfter(10), Value: 2, Literal: "2"},
+			{Token: token.INT, Position: posAfter(13), Value: 3, Literal: "3"},
+		}}},
+		{str: ".word 0x10", stmt: &ast.WordStatement{Token: token.WORD, Position: testPos, Values: []*ast.Integer{
+			{Token: token.INT, Position: posAfter(7), Value: 16, Literal: "0x10"},
+		}}},
+		{str: ".word -1, +2", stmt: &ast.WordStatement{Token: token.WORD, Position: testPos, Values: []*ast.Integer{
+			{Token: token.INT, Position: posAfter(7), Value: -1, Literal: "-1"},
+			{Token: token.INT, Position: posAfter(11), Value: 2, Literal: "2"},
+		}}},
+		{str: ".word", err: `1:6: found EOF, expected INTEGER`},
+		{str: ".word +", err: `1:7: found EOF, expected INTEGER`},
+		{str: ".word 1,", err: `1:8: found EOF, expected INTEGER`},
+		{str: ".word 1 2", err: `1:9: found INTEGER "2", expected COMMENT, NEWLINE, EOF`},
+		{str: ".word x", err: `1:7: found IDENTIFIER "x", expected INTEGER`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.str)
+			if wordStmt, valid := tt.stmt.(*ast.WordStatement); valid {
+				ok(t, err)
+				equals(t, stmt, wordStmt)
+			} else {
+				equals(t, err.Error(), tt.err)
+			}
+		})
+	}
+
+	// Labels can reference a word directive like an integer.
+	prog, err := Parse("x: .word 1, -2\ny: 3")
+	ok(t, err)
+	equals(t, "x: .word 1, -2\ny: 3", prog.Statements.String())
+	_, valid := prog.Statements[0].(*ast.LabelStatement).Reference.(*ast.WordStatement)
+	assert(t, valid, "expected label to reference a word directive")
+}
+
 // TestParser_ParseLabelStatement validates the correct parsing of st commands.
 func TestParser_ParseLabelStatement(t *testing.T) {
 	tests := []struct {
@@ -366,6 +948,24 @@ func TestParser_ParseLabelStatement(t *testing.T) {
 				Reference: &ast.Integer{Token: token.INT, Position: posAfter(4), Value: 25, Literal: "25"},
 			},
 		},
+		{
+			str: "x: -0xa",
+			stmt: &ast.LabelStatement{
+				Token:     token.IDENT,
+				Position:  testPos,
+				Ident:     &ast.Identifier{Token: token.IDENT, Position: testPos, Name: "x"},
+				Reference: &ast.Integer{Token: token.INT, Position: posAfter(4), Value: -10, Literal: "-0xa"},
+			},
+		},
+		{
+			str: "x: +5",
+			stmt: &ast.LabelStatement{
+				Token:     token.IDENT,
+				Position:  testPos,
+				Ident:     &ast.Identifier{Token: token.IDENT, Position: testPos, Name: "x"},
+				Reference: &ast.Integer{Token: token.INT, Position: posAfter(4), Value: 5, Literal: "5"},
+			},
+		},
 		{
 			str: "mylabel: ld %r1, %r2",
 			stmt: &ast.LabelStatement{
@@ -375,15 +975,17 @@ func TestParser_ParseLabelStatement(t *testing.T) {
 				Reference: &ast.LoadStatement{
 					Token:       token.LOAD,
 					Position:    posAfter(10),
-					Source:      &ast.Register{Name: "%r1"},
-					Destination: &ast.Register{Name: "%r2"},
+					Source:      &ast.Register{Token: token.REG, Position: posAfter(13), Name: "%r1"},
+					Destination: &ast.Register{Token: token.REG, Position: posAfter(18), Name: "%r2"},
 				},
 			},
 		},
-		{str: "x: y: 25", err: `1:4: found IDENTIFIER "y", expected INTEGER, "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`},
+		{str: "x: y: 25", err: `1:4: found IDENTIFIER "y", expected INTEGER, "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`},
 		{str: "x: 25;", err: `1:6: found ILLEGAL ";", expected COMMENT, NEWLINE, EOF`},
 		{str: "x: ld", err: `1:6: found EOF, expected "[", REGISTER`},
 		{str: "X: 90000000000000", err: `1:4: INTEGER "90000000000000" out of 32 bit range`},
+		{str: "X: -90000000000000", err: `1:4: INTEGER "-90000000000000" out of 32 bit range`},
+		{str: "x: -", err: `1:4: found EOF, expected INTEGER`},
 	}
 
 	for _, tt := range tests {
@@ -411,8 +1013,8 @@ func TestParser_ParseLoadStatement(t *testing.T) {
 			stmt: &ast.LoadStatement{
 				Token:       token.LOAD,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Destination: &ast.Register{Name: "%r2"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(4), Name: "%r1"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(9), Name: "%r2"},
 			},
 		},
 		{
@@ -427,21 +1029,21 @@ func TestParser_ParseLoadStatement(t *testing.T) {
 						Name:     "x",
 					},
 				},
-				Destination: &ast.Register{Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(9), Name: "%r2"},
 			},
 		},
 		{
-			str: "ld [%r1+8191], %r2",
+			str: "ld [%r1+4095], %r2",
 			stmt: &ast.LoadStatement{
 				Token:    token.LOAD,
 				Position: testPos,
 				Source: &ast.Expression{
 					Position: posAfter(4),
-					Base:     &ast.Register{Name: "%r1"},
+					Base:     &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 					Operator: "+",
-					Offset:   &ast.Integer{Token: token.INT, Position: posAfter(9), Value: 8191, Literal: "8191"},
+					Offset:   &ast.Integer{Token: token.INT, Position: posAfter(9), Value: 4095, Literal: "4095"},
 				},
-				Destination: &ast.Register{Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(16), Name: "%r2"},
 			},
 		},
 		{
@@ -451,11 +1053,11 @@ func TestParser_ParseLoadStatement(t *testing.T) {
 				Position: testPos,
 				Source: &ast.Expression{
 					Position: posAfter(4),
-					Base:     &ast.Register{Name: "%r1"},
+					Base:     &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 					Operator: "+",
 					Offset:   &ast.Integer{Token: token.INT, Position: posAfter(9), Value: 0, Literal: "0"},
 				},
-				Destination: &ast.Register{Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(13), Name: "%r2"},
 			},
 		},
 		{
@@ -478,9 +1080,17 @@ func TestParser_ParseLoadStatement(t *testing.T) {
 			str: "ld %r1, %r2, %r3",
 			err: `1:12: found ",", expected COMMENT, NEWLINE, EOF`,
 		},
+		{
+			str: "ld %r1], %r2",
+			err: `1:7: found "]", expected ","`,
+		},
+		{
+			str: "ld [%r1+4, %r2",
+			err: `1:10: found ",", expected "]"`,
+		},
 		{
 			str: "\nld %r1, %r2",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -509,8 +1119,8 @@ func TestParser_ParseStoreStatement(t *testing.T) {
 			stmt: &ast.StoreStatement{
 				Token:       token.STORE,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(4), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(9), Name: "%r1"},
 			},
 		},
 		{
@@ -518,7 +1128,7 @@ func TestParser_ParseStoreStatement(t *testing.T) {
 			stmt: &ast.StoreStatement{
 				Token:    token.STORE,
 				Position: testPos,
-				Source:   &ast.Register{Name: "%r2"},
+				Source:   &ast.Register{Token: token.REG, Position: posAfter(4), Name: "%r2"},
 				Destination: &ast.Expression{
 					Position: posAfter(9),
 					Base: &ast.Identifier{Token: token.IDENT,
@@ -529,16 +1139,16 @@ func TestParser_ParseStoreStatement(t *testing.T) {
 			},
 		},
 		{
-			str: "st %r2, [%r1+8191]",
+			str: "st %r2, [%r1+4095]",
 			stmt: &ast.StoreStatement{
 				Token:    token.STORE,
 				Position: testPos,
-				Source:   &ast.Register{Name: "%r2"},
+				Source:   &ast.Register{Token: token.REG, Position: posAfter(4), Name: "%r2"},
 				Destination: &ast.Expression{
 					Position: posAfter(9),
-					Base:     &ast.Register{Name: "%r1"},
+					Base:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r1"},
 					Operator: "+",
-					Offset:   &ast.Integer{Token: token.INT, Position: posAfter(14), Value: 8191, Literal: "8191"},
+					Offset:   &ast.Integer{Token: token.INT, Position: posAfter(14), Value: 4095, Literal: "4095"},
 				},
 			},
 		},
@@ -547,10 +1157,10 @@ func TestParser_ParseStoreStatement(t *testing.T) {
 			stmt: &ast.StoreStatement{
 				Token:    token.STORE,
 				Position: testPos,
-				Source:   &ast.Register{Name: "%r2"},
+				Source:   &ast.Register{Token: token.REG, Position: posAfter(4), Name: "%r2"},
 				Destination: &ast.Expression{
 					Position: posAfter(9),
-					Base:     &ast.Register{Name: "%r1"},
+					Base:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r1"},
 					Operator: "+",
 					Offset:   &ast.Integer{Token: token.INT, Position: posAfter(14), Value: 0, Literal: "0"},
 				},
@@ -578,7 +1188,7 @@ func TestParser_ParseStoreStatement(t *testing.T) {
 		},
 		{
 			str: "\nst %r2, %r1",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -607,9 +1217,9 @@ func TestParser_ParseAddStatement(t *testing.T) {
 			stmt: &ast.AddStatement{
 				Token:       token.ADD,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(15), Name: "%r3"},
 			},
 		},
 		{
@@ -617,9 +1227,9 @@ func TestParser_ParseAddStatement(t *testing.T) {
 			stmt: &ast.AddStatement{
 				Token:       token.ADD,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r3"},
 			},
 		},
 		{
@@ -635,8 +1245,28 @@ func TestParser_ParseAddStatement(t *testing.T) {
 			err: `1:15: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "add %r1, x, %r3",
-			err: `1:10: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "add %r1, -1, %r2",
+			stmt: &ast.AddStatement{
+				Token:       token.ADD,
+				Position:    testPos,
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: -1, Literal: "-1"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r2"},
+			},
+		},
+		{
+			str: "add %r1, +1, %r2",
+			stmt: &ast.AddStatement{
+				Token:       token.ADD,
+				Position:    testPos,
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 1, Literal: "1"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r2"},
+			},
+		},
+		{
+			str: "add %r1, [x], %r3",
+			err: `1:10: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "add x, %r2, %r3",
@@ -656,7 +1286,7 @@ func TestParser_ParseAddStatement(t *testing.T) {
 		},
 		{
 			str: "\nadd %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -685,9 +1315,9 @@ func TestParser_ParseAddCCStatement(t *testing.T) {
 			stmt: &ast.AddCCStatement{
 				Token:       token.ADDCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(12), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(17), Name: "%r3"},
 			},
 		},
 		{
@@ -695,9 +1325,9 @@ func TestParser_ParseAddCCStatement(t *testing.T) {
 			stmt: &ast.AddCCStatement{
 				Token:       token.ADDCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(12), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(16), Name: "%r3"},
 			},
 		},
 		{
@@ -713,8 +1343,8 @@ func TestParser_ParseAddCCStatement(t *testing.T) {
 			err: `1:17: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "addcc %r1, x, %r3",
-			err: `1:12: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "addcc %r1, [x], %r3",
+			err: `1:12: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "addcc x, %r2, %r3",
@@ -734,7 +1364,7 @@ func TestParser_ParseAddCCStatement(t *testing.T) {
 		},
 		{
 			str: "\naddcc %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -763,9 +1393,9 @@ func TestParser_ParseSubStatement(t *testing.T) {
 			stmt: &ast.SubStatement{
 				Token:       token.SUB,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(15), Name: "%r3"},
 			},
 		},
 		{
@@ -773,9 +1403,9 @@ func TestParser_ParseSubStatement(t *testing.T) {
 			stmt: &ast.SubStatement{
 				Token:       token.SUB,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r3"},
 			},
 		},
 		{
@@ -791,8 +1421,8 @@ func TestParser_ParseSubStatement(t *testing.T) {
 			err: `1:15: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "sub %r1, x, %r3",
-			err: `1:10: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "sub %r1, [x], %r3",
+			err: `1:10: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "sub x, %r2, %r3",
@@ -812,7 +1442,7 @@ func TestParser_ParseSubStatement(t *testing.T) {
 		},
 		{
 			str: "\nsub %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -841,9 +1471,9 @@ func TestParser_ParseSubCCStatement(t *testing.T) {
 			stmt: &ast.SubCCStatement{
 				Token:       token.SUBCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(12), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(17), Name: "%r3"},
 			},
 		},
 		{
@@ -851,9 +1481,9 @@ func TestParser_ParseSubCCStatement(t *testing.T) {
 			stmt: &ast.SubCCStatement{
 				Token:       token.SUBCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(12), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(16), Name: "%r3"},
 			},
 		},
 		{
@@ -869,8 +1499,8 @@ func TestParser_ParseSubCCStatement(t *testing.T) {
 			err: `1:17: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "subcc %r1, x, %r3",
-			err: `1:12: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "subcc %r1, [x], %r3",
+			err: `1:12: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "subcc x, %r2, %r3",
@@ -890,7 +1520,7 @@ func TestParser_ParseSubCCStatement(t *testing.T) {
 		},
 		{
 			str: "\nsubcc %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -919,9 +1549,9 @@ func TestParser_ParseAndStatement(t *testing.T) {
 			stmt: &ast.AndStatement{
 				Token:       token.AND,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(15), Name: "%r3"},
 			},
 		},
 		{
@@ -929,9 +1559,9 @@ func TestParser_ParseAndStatement(t *testing.T) {
 			stmt: &ast.AndStatement{
 				Token:       token.AND,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r3"},
 			},
 		},
 		{
@@ -947,8 +1577,8 @@ func TestParser_ParseAndStatement(t *testing.T) {
 			err: `1:15: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "and %r1, x, %r3",
-			err: `1:10: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "and %r1, [x], %r3",
+			err: `1:10: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "and x, %r2, %r3",
@@ -968,7 +1598,7 @@ func TestParser_ParseAndStatement(t *testing.T) {
 		},
 		{
 			str: "\nand %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -997,9 +1627,9 @@ func TestParser_ParseAndCCStatement(t *testing.T) {
 			stmt: &ast.AndCCStatement{
 				Token:       token.ANDCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(12), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(17), Name: "%r3"},
 			},
 		},
 		{
@@ -1007,9 +1637,9 @@ func TestParser_ParseAndCCStatement(t *testing.T) {
 			stmt: &ast.AndCCStatement{
 				Token:       token.ANDCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(12), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(16), Name: "%r3"},
 			},
 		},
 		{
@@ -1025,8 +1655,8 @@ func TestParser_ParseAndCCStatement(t *testing.T) {
 			err: `1:17: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "andcc %r1, x, %r3",
-			err: `1:12: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "andcc %r1, [x], %r3",
+			err: `1:12: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "andcc x, %r2, %r3",
@@ -1046,7 +1676,7 @@ func TestParser_ParseAndCCStatement(t *testing.T) {
 		},
 		{
 			str: "\nandcc %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1075,9 +1705,9 @@ func TestParser_ParseOrStatement(t *testing.T) {
 			stmt: &ast.OrStatement{
 				Token:       token.OR,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(4), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(9), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r3"},
 			},
 		},
 		{
@@ -1085,9 +1715,9 @@ func TestParser_ParseOrStatement(t *testing.T) {
 			stmt: &ast.OrStatement{
 				Token:       token.OR,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(4), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(9), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(13), Name: "%r3"},
 			},
 		},
 		{
@@ -1103,8 +1733,8 @@ func TestParser_ParseOrStatement(t *testing.T) {
 			err: `1:14: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "or %r1, x, %r3",
-			err: `1:9: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "or %r1, [x], %r3",
+			err: `1:9: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "or x, %r2, %r3",
@@ -1124,7 +1754,7 @@ func TestParser_ParseOrStatement(t *testing.T) {
 		},
 		{
 			str: "\nor %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1153,9 +1783,9 @@ func TestParser_ParseOrCCStatement(t *testing.T) {
 			stmt: &ast.OrCCStatement{
 				Token:       token.ORCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(6), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(11), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(16), Name: "%r3"},
 			},
 		},
 		{
@@ -1163,9 +1793,9 @@ func TestParser_ParseOrCCStatement(t *testing.T) {
 			stmt: &ast.OrCCStatement{
 				Token:       token.ORCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(6), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(11), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(15), Name: "%r3"},
 			},
 		},
 		{
@@ -1181,8 +1811,8 @@ func TestParser_ParseOrCCStatement(t *testing.T) {
 			err: `1:16: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "orcc %r1, x, %r3",
-			err: `1:11: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "orcc %r1, [x], %r3",
+			err: `1:11: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "orcc x, %r2, %r3",
@@ -1202,7 +1832,7 @@ func TestParser_ParseOrCCStatement(t *testing.T) {
 		},
 		{
 			str: "\norcc %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1231,9 +1861,9 @@ func TestParser_ParseOrnStatement(t *testing.T) {
 			stmt: &ast.OrnStatement{
 				Token:       token.ORN,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(15), Name: "%r3"},
 			},
 		},
 		{
@@ -1241,9 +1871,9 @@ func TestParser_ParseOrnStatement(t *testing.T) {
 			stmt: &ast.OrnStatement{
 				Token:       token.ORN,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r3"},
 			},
 		},
 		{
@@ -1259,8 +1889,8 @@ func TestParser_ParseOrnStatement(t *testing.T) {
 			err: `1:15: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "orn %r1, x, %r3",
-			err: `1:10: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "orn %r1, [x], %r3",
+			err: `1:10: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "orn x, %r2, %r3",
@@ -1280,7 +1910,7 @@ func TestParser_ParseOrnStatement(t *testing.T) {
 		},
 		{
 			str: "\norn %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1309,9 +1939,9 @@ func TestParser_ParseOrnCCStatement(t *testing.T) {
 			stmt: &ast.OrnCCStatement{
 				Token:       token.ORNCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(12), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(17), Name: "%r3"},
 			},
 		},
 		{
@@ -1319,9 +1949,9 @@ func TestParser_ParseOrnCCStatement(t *testing.T) {
 			stmt: &ast.OrnCCStatement{
 				Token:       token.ORNCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(12), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(16), Name: "%r3"},
 			},
 		},
 		{
@@ -1337,8 +1967,8 @@ func TestParser_ParseOrnCCStatement(t *testing.T) {
 			err: `1:17: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "orncc %r1, x, %r3",
-			err: `1:12: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "orncc %r1, [x], %r3",
+			err: `1:12: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "orncc x, %r2, %r3",
@@ -1358,7 +1988,7 @@ func TestParser_ParseOrnCCStatement(t *testing.T) {
 		},
 		{
 			str: "\norncc %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1387,9 +2017,9 @@ func TestParser_ParseXorStatement(t *testing.T) {
 			stmt: &ast.XorStatement{
 				Token:       token.XOR,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(15), Name: "%r3"},
 			},
 		},
 		{
@@ -1397,9 +2027,9 @@ func TestParser_ParseXorStatement(t *testing.T) {
 			stmt: &ast.XorStatement{
 				Token:       token.XOR,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r3"},
 			},
 		},
 		{
@@ -1415,8 +2045,8 @@ func TestParser_ParseXorStatement(t *testing.T) {
 			err: `1:15: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "xor %r1, x, %r3",
-			err: `1:10: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "xor %r1, [x], %r3",
+			err: `1:10: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "xor x, %r2, %r3",
@@ -1436,7 +2066,7 @@ func TestParser_ParseXorStatement(t *testing.T) {
 		},
 		{
 			str: "\nxor %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1465,9 +2095,9 @@ func TestParser_ParseXorCCStatement(t *testing.T) {
 			stmt: &ast.XorCCStatement{
 				Token:       token.XORCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(12), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(17), Name: "%r3"},
 			},
 		},
 		{
@@ -1475,9 +2105,9 @@ func TestParser_ParseXorCCStatement(t *testing.T) {
 			stmt: &ast.XorCCStatement{
 				Token:       token.XORCC,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(12), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(16), Name: "%r3"},
 			},
 		},
 		{
@@ -1493,8 +2123,8 @@ func TestParser_ParseXorCCStatement(t *testing.T) {
 			err: `1:17: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "xorcc %r1, x, %r3",
-			err: `1:12: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "xorcc %r1, [x], %r3",
+			err: `1:12: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "xorcc x, %r2, %r3",
@@ -1514,7 +2144,7 @@ func TestParser_ParseXorCCStatement(t *testing.T) {
 		},
 		{
 			str: "\nxorcc %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1543,9 +2173,9 @@ func TestParser_ParseSLLStatement(t *testing.T) {
 			stmt: &ast.SLLStatement{
 				Token:       token.SLL,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(15), Name: "%r3"},
 			},
 		},
 		{
@@ -1553,9 +2183,9 @@ func TestParser_ParseSLLStatement(t *testing.T) {
 			stmt: &ast.SLLStatement{
 				Token:       token.SLL,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r3"},
 			},
 		},
 		{
@@ -1571,8 +2201,8 @@ func TestParser_ParseSLLStatement(t *testing.T) {
 			err: `1:15: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "sll %r1, x, %r3",
-			err: `1:10: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "sll %r1, [x], %r3",
+			err: `1:10: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "sll x, %r2, %r3",
@@ -1592,7 +2222,7 @@ func TestParser_ParseSLLStatement(t *testing.T) {
 		},
 		{
 			str: "\nsll %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1621,9 +2251,9 @@ func TestParser_ParseSRAStatement(t *testing.T) {
 			stmt: &ast.SRAStatement{
 				Token:       token.SRA,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
-				Operand:     &ast.Register{Name: "%r2"},
-				Destination: &ast.Register{Name: "%r3"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r2"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(15), Name: "%r3"},
 			},
 		},
 		{
@@ -1631,9 +2261,9 @@ func TestParser_ParseSRAStatement(t *testing.T) {
 			stmt: &ast.SRAStatement{
 				Token:       token.SRA,
 				Position:    testPos,
-				Source:      &ast.Register{Name: "%r1"},
+				Source:      &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
 				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 32, Literal: "32"},
-				Destination: &ast.Register{Name: "%r3"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r3"},
 			},
 		},
 		{
@@ -1649,8 +2279,8 @@ func TestParser_ParseSRAStatement(t *testing.T) {
 			err: `1:15: found INTEGER "32", expected REGISTER`,
 		},
 		{
-			str: "sra %r1, x, %r3",
-			err: `1:10: found IDENTIFIER "x", expected INTEGER, REGISTER`,
+			str: "sra %r1, [x], %r3",
+			err: `1:10: found "[", expected INTEGER, REGISTER, IDENTIFIER`,
 		},
 		{
 			str: "sra x, %r2, %r3",
@@ -1670,7 +2300,7 @@ func TestParser_ParseSRAStatement(t *testing.T) {
 		},
 		{
 			str: "\nsra %r1, %r2, %r3",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1728,7 +2358,7 @@ func TestParser_ParseBEStatement(t *testing.T) {
 		},
 		{
 			str: "\nbe x",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1786,7 +2416,7 @@ func TestParser_ParseBNEStatement(t *testing.T) {
 		},
 		{
 			str: "\nbne x",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1844,7 +2474,7 @@ func TestParser_ParseBNEGStatement(t *testing.T) {
 		},
 		{
 			str: "\nbneg x",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1902,7 +2532,7 @@ func TestParser_ParseBPOSStatement(t *testing.T) {
 		},
 		{
 			str: "\nbneg x",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -1960,7 +2590,7 @@ func TestParser_ParseBAStatement(t *testing.T) {
 		},
 		{
 			str: "\nbe x",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -2018,7 +2648,7 @@ func TestParser_ParseCallStatement(t *testing.T) {
 		},
 		{
 			str: "\ncall x",
-			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl"`,
+			err: `1:1: found NEWLINE, expected COMMENT, IDENTIFIER, ".begin", ".end", ".org", ".global", ".extern", ".word", ".equ", ".assert", "ld", "st", "add", "addcc", "sub", "subcc", "and", "andcc", "or", "orcc", "orn", "orncc", "xor", "xorcc", "sll", "sra", "be", "bne", "bneg", "bpos", "ba", "call", "jmpl", "nop", "cmp", "ret", "retl", "mov", "clr"`,
 		},
 	}
 
@@ -2035,6 +2665,194 @@ func TestParser_ParseCallStatement(t *testing.T) {
 	}
 }
 
+// TestParser_ParseJumpAndLinkStatement validates the correct parsing of jmpl
+// commands. The brackets around the return address are optional.
+func TestParser_ParseJumpAndLinkStatement(t *testing.T) {
+	tests := []struct {
+		str  string
+		stmt ast.Statement
+		err  string
+	}{
+		{
+			str: "jmpl [%r15+4], %r0",
+			stmt: &ast.JumpAndLinkStatement{
+				Token:    token.JMPL,
+				Position: testPos,
+				ReturnAddress: &ast.Expression{
+					Position: testPos,
+					Base:     &ast.Register{Token: token.REG, Position: posAfter(7), Name: "%r15"},
+					Operator: "+",
+					Offset:   &ast.Integer{Token: token.INT, Position: posAfter(12), Value: 4, Literal: "4"},
+				},
+				FromAddress: &ast.Register{Token: token.REG, Position: posAfter(16), Name: "%r0"},
+			},
+		},
+		{
+			str: "jmpl %r15+4, %r0",
+			stmt: &ast.JumpAndLinkStatement{
+				Token:    token.JMPL,
+				Position: testPos,
+				ReturnAddress: &ast.Expression{
+					Position: testPos,
+					Base:     &ast.Register{Token: token.REG, Position: posAfter(6), Name: "%r15"},
+					Operator: "+",
+					Offset:   &ast.Integer{Token: token.INT, Position: posAfter(11), Value: 4, Literal: "4"},
+				},
+				FromAddress: &ast.Register{Token: token.REG, Position: posAfter(14), Name: "%r0"},
+			},
+		},
+		{
+			str: "jmpl %r15, %r0",
+			stmt: &ast.JumpAndLinkStatement{
+				Token:    token.JMPL,
+				Position: testPos,
+				ReturnAddress: &ast.Expression{
+					Position: testPos,
+					Base:     &ast.Register{Token: token.REG, Position: posAfter(6), Name: "%r15"},
+				},
+				FromAddress: &ast.Register{Token: token.REG, Position: posAfter(12), Name: "%r0"},
+			},
+		},
+		{
+			str: "jmpl %r15+4 %r0",
+			err: `1:13: found REGISTER "%r0", expected ","`,
+		},
+		{
+			str: "jmpl %r15+4], %r0",
+			err: `1:12: found "]", expected ","`,
+		},
+		{
+			str: "jmpl [%r15+4, %r0",
+			err: `1:13: found ",", expected "]"`,
+		},
+		{
+			str: "jmpl [%r15+4], 4",
+			err: `1:16: found INTEGER "4", expected REGISTER`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.str)
+			if tt.err == "" {
+				ok(t, err)
+				equals(t, tt.stmt, stmt)
+			} else {
+				equals(t, tt.err, err.Error())
+			}
+		})
+	}
+}
+
+// TestParser_ParsePseudoStatement verifies the correct parsing of pseudo
+// instructions.
+func TestParser_ParsePseudoStatement(t *testing.T) {
+	tests := []struct {
+		str  string
+		stmt ast.Statement
+		err  string
+	}{
+		{
+			str:  "nop",
+			stmt: &ast.NopStatement{Token: token.NOP, Position: testPos},
+		},
+		{
+			str: "cmp %r1, 5",
+			stmt: &ast.CmpStatement{
+				Token:    token.CMP,
+				Position: testPos,
+				Source:   &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Operand:  &ast.Integer{Token: token.INT, Position: posAfter(10), Value: 5, Literal: "5"},
+			},
+		},
+		{
+			str:  "ret",
+			stmt: &ast.RetStatement{Token: token.RET, Position: testPos},
+		},
+		{
+			str:  "retl",
+			stmt: &ast.RetStatement{Token: token.RETL, Position: testPos},
+		},
+		{
+			str: "mov %r1, %r2",
+			stmt: &ast.MovStatement{
+				Token:       token.MOV,
+				Position:    testPos,
+				Operand:     &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r1"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(10), Name: "%r2"},
+			},
+		},
+		{
+			str: "mov -1, %r2",
+			stmt: &ast.MovStatement{
+				Token:       token.MOV,
+				Position:    testPos,
+				Operand:     &ast.Integer{Token: token.INT, Position: posAfter(5), Value: -1, Literal: "-1"},
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(9), Name: "%r2"},
+			},
+		},
+		{
+			str: "clr %r3",
+			stmt: &ast.ClrStatement{
+				Token:       token.CLR,
+				Position:    testPos,
+				Destination: &ast.Register{Token: token.REG, Position: posAfter(5), Name: "%r3"},
+			},
+		},
+		{
+			str: "mov %r1, 5",
+			err: `1:10: found INTEGER "5", expected REGISTER`,
+		},
+		{
+			str: "clr 5",
+			err: `1:5: found INTEGER "5", expected REGISTER`,
+		},
+		{
+			str: "nop %r1",
+			err: `1:5: found REGISTER "%r1", expected COMMENT, NEWLINE, EOF`,
+		},
+		{
+			str: "cmp %r1",
+			err: `1:8: found EOF, expected ","`,
+		},
+		{
+			str: "cmp 5, %r1",
+			err: `1:5: found INTEGER "5", expected REGISTER`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			stmt, err := ParseStatement(tt.str)
+			if tt.err == "" {
+				ok(t, err)
+				equals(t, stmt, tt.stmt)
+				equals(t, tt.str, stmt.String())
+			} else {
+				equals(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
+
+// TestLower verifies that pseudo instructions are lowered to real instructions
+// and that the lowered program parses to the same program again.
+func TestLower(t *testing.T) {
+	src := "loop: cmp %r1, 0\nnop\nbne loop\nret\nretl\nmov %r1, %r2\nmov 5, %r2\nclr %r3"
+	prog, err := Parse(src)
+	ok(t, err)
+
+	lowered := ast.Lower(prog)
+	want := "loop: subcc %r1, 0, %r0\nadd %r0, %r0, %r0\nbne loop\njmpl [%r15+4], %r0\njmpl [%r15+4], %r0\nor %r0, %r1, %r2\nor %r0, 5, %r2\nor %r0, %r0, %r3"
+	equals(t, want, lowered.String())
+	equals(t, true, prog.Statements[2] == lowered.Statements[2])
+	equals(t, src, prog.String())
+
+	reparsed, err := Parse(lowered.String())
+	ok(t, err)
+	equals(t, want, ast.Lower(reparsed).String())
+}
+
 // TestParser_ParseIdent verifies the correct parsing of identifiers.
 func TestParser_ParseIdent(t *testing.T) {
 	tests := []struct {
@@ -2068,7 +2886,7 @@ func TestParser_ParseRegister(t *testing.T) {
 		obj *ast.Register
 		err string
 	}{
-		{str: "%r1", obj: &ast.Register{Name: "%r1"}},
+		{str: "%r1", obj: &ast.Register{Token: token.REG, Position: testPos, Name: "%r1"}},
 		{str: "r1", err: `1:1: found IDENTIFIER "r1", expected REGISTER`},
 	}
 
@@ -2085,8 +2903,8 @@ func TestParser_ParseRegister(t *testing.T) {
 	}
 }
 
-// TestParser_ParseInteger verifies the correct parsing of integers.
-func TestParser_ParseInteger(t *testing.T) {
+// TestParser_ParseSignedInteger verifies the correct parsing of integers.
+func TestParser_ParseSignedInteger(t *testing.T) {
 	tests := []struct {
 		str string
 		obj *ast.Integer
@@ -2097,13 +2915,19 @@ func TestParser_ParseInteger(t *testing.T) {
 		{str: "001", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 1, Literal: "001"}},
 		{str: "0", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 0, Literal: "0"}},
 		{str: "0x800", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 2048, Literal: "0x800"}},
+		{str: "0b1010", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 10, Literal: "0b1010"}},
+		{str: "'A'", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 65, Literal: "'A'"}},
+		{str: `'\n'`, obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 10, Literal: `'\n'`}},
+		{str: `'\0'`, obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 0, Literal: `'\0'`}},
+		{str: `'\''`, obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 39, Literal: `'\''`}},
+		{str: "-'A'", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: -65, Literal: "-'A'"}},
 		{str: "90000000000000", err: `1:1: INTEGER "90000000000000" out of 32 bit range`},
 		{str: "x", err: `1:1: found IDENTIFIER "x", expected INTEGER`},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.str, func(t *testing.T) {
-			integer, err := New(strings.NewReader(tt.str)).parseInteger()
+			integer, err := New(strings.NewReader(tt.str)).parseSignedInteger()
 			if tt.err == "" {
 				ok(t, err)
 				equals(t, integer, tt.obj)
@@ -2125,15 +2949,16 @@ func TestParser_ParseSIMM13(t *testing.T) {
 		{str: "100", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 100, Literal: "100"}},
 		{str: "001", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 1, Literal: "001"}},
 		{str: "0", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 0, Literal: "0"}},
-		{str: "8191", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 8191, Literal: "8191"}},
+		{str: "4095", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 4095, Literal: "4095"}},
 		{str: "0x800", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 2048, Literal: "0x800"}},
+		{str: "4096", err: `1:1: INTEGER "4096" is not a valid SIMM13`},
 		{str: "8192", err: `1:1: INTEGER "8192" is not a valid SIMM13`},
 		{str: "-1", err: `1:1: found "-", expected INTEGER`},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.str, func(t *testing.T) {
-			integer, err := New(strings.NewReader(tt.str)).parseSIMM13()
+			integer, err := New(strings.NewReader(tt.str)).parseSIMM13(false)
 			if tt.err == "" {
 				ok(t, err)
 				equals(t, integer, tt.obj)
@@ -2151,10 +2976,12 @@ func TestParser_ParseExpression(t *testing.T) {
 		obj *ast.Expression
 		err string
 	}{
-		{str: "[%r1+8191]", obj: &ast.Expression{Base: &ast.Register{Name: "%r1"}, Operator: "+", Offset: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 8191, Literal: "8191"}}},
-		{str: "[%r1+0]", obj: &ast.Expression{Base: &ast.Register{Name: "%r1"}, Operator: "+", Offset: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 0, Literal: "0"}}},
-		{str: "%r1+8191", obj: &ast.Expression{Base: &ast.Register{Name: "%r1"}, Operator: "+", Offset: &ast.Integer{Token: token.INT, Position: posAfter(5), Value: 8191, Literal: "8191"}}},
-		{str: "%r1+0", obj: &ast.Expression{Base: &ast.Register{Name: "%r1"}, Operator: "+", Offset: &ast.Integer{Token: token.INT, Position: posAfter(5), Value: 0, Literal: "0"}}},
+		{str: "[%r1+4095]", obj: &ast.Expression{Base: &ast.Register{Token: token.REG, Position: posAfter(2), Name: "%r1"}, Operator: "+", Offset: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 4095, Literal: "4095"}}},
+		{str: "[%r1+0]", obj: &ast.Expression{Base: &ast.Register{Token: token.REG, Position: posAfter(2), Name: "%r1"}, Operator: "+", Offset: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 0, Literal: "0"}}},
+		{str: "%r1+4095", obj: &ast.Expression{Base: &ast.Register{Token: token.REG, Position: testPos, Name: "%r1"}, Operator: "+", Offset: &ast.Integer{Token: token.INT, Position: posAfter(5), Value: 4095, Literal: "4095"}}},
+		{str: "%r1+0", obj: &ast.Expression{Base: &ast.Register{Token: token.REG, Position: testPos, Name: "%r1"}, Operator: "+", Offset: &ast.Integer{Token: token.INT, Position: posAfter(5), Value: 0, Literal: "0"}}},
+		{str: "[%r1-4]", obj: &ast.Expression{Base: &ast.Register{Token: token.REG, Position: posAfter(2), Name: "%r1"}, Operator: "-", Offset: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 4, Literal: "4"}}},
+		{str: "[%r1-4096]", obj: &ast.Expression{Base: &ast.Register{Token: token.REG, Position: posAfter(2), Name: "%r1"}, Operator: "-", Offset: &ast.Integer{Token: token.INT, Position: posAfter(6), Value: 4096, Literal: "4096"}}},
 		{str: "[x]", obj: &ast.Expression{Base: &ast.Identifier{Token: token.IDENT, Position: posAfter(2), Name: "x"}, Operator: "", Offset: nil}},
 		{str: "x", obj: &ast.Expression{Base: &ast.Identifier{Token: token.IDENT, Position: posAfter(1), Name: "x"}, Operator: "", Offset: nil}},
 		{str: "x]", err: `1:2: found "]", expected "+", "-"`}, // TODO: Improve this error message.
@@ -2165,6 +2992,8 @@ func TestParser_ParseExpression(t *testing.T) {
 		// {str: "%r1+8191]", err: `1:6: found "]", expected EOF`},
 		{str: "[%r1*8191]", err: `1:5: found ILLEGAL "*", expected "+", "-", "]"`},
 		{str: "[%r1+]", err: `1:6: found "]", expected INTEGER`},
+		{str: "[%r1+4096]", err: `1:6: INTEGER "4096" is not a valid SIMM13`},
+		{str: "[%r1-4097]", err: `1:6: INTEGER "4097" is not a valid SIMM13`},
 		{str: "[%r1+45", err: `1:8: found EOF, expected "]"`},
 	}
 
@@ -2189,8 +3018,14 @@ func TestParser_ParseOperand(t *testing.T) {
 		err string
 	}{
 		{str: "64", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 64, Literal: "64"}},
-		{str: "%r1", obj: &ast.Register{Name: "%r1"}},
-		{str: "x", err: `1:1: found IDENTIFIER "x", expected INTEGER, REGISTER`},
+		{str: "-1", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: -1, Literal: "-1"}},
+		{str: "+4", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: 4, Literal: "4"}},
+		{str: "-2147483648", obj: &ast.Integer{Token: token.INT, Position: testPos, Value: -2147483648, Literal: "-2147483648"}},
+		{str: "-2147483649", err: `1:1: INTEGER "-2147483649" out of 32 bit range`},
+		{str: "- x", err: `1:3: found IDENTIFIER "x", expected INTEGER`},
+		{str: "%r1", obj: &ast.Register{Token: token.REG, Position: testPos, Name: "%r1"}},
+		{str: "x", obj: &ast.Identifier{Token: token.IDENT, Position: testPos, Name: "x"}},
+		{str: "[x]", err: `1:1: found "[", expected INTEGER, REGISTER, IDENTIFIER`},
 	}
 
 	for _, tt := range tests {
@@ -2222,7 +3057,7 @@ func TestParser_ParseMemoryLocation(t *testing.T) {
 				},
 			},
 		},
-		{str: "%r1", obj: &ast.Register{Name: "%r1"}},
+		{str: "%r1", obj: &ast.Register{Token: token.REG, Position: testPos, Name: "%r1"}},
 		{str: "x", err: `1:1: found IDENTIFIER "x", expected "[", REGISTER`},
 		{str: "123", err: `1:1: found INTEGER "123", expected "[", REGISTER`},
 		{str: "[x+]", err: `1:4: found "]", expected INTEGER`},
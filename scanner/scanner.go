@@ -1,7 +1,9 @@
 /*
 Package scanner implements a buffered scanner which provides lexical analysis
 (tokenizing) of ARC source code. A scanner takes a bufio.Reader as source which
-can then be tokenized through repeated calls to the Scan method.
+can then be tokenized through repeated calls to the Scan method. Source code
+which is already held in memory can be scanned from a byte slice instead, which
+avoids the buffering per rune.
 */
 package scanner
 
@@ -12,6 +14,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/lukasmalkmus/arc/token"
 )
@@ -23,6 +26,13 @@ type Scanner struct {
 	r              *bufio.Reader
 	pos            token.Pos
 	resetCharCount bool
+
+	// src is the source of a scanner created by NewBytes. It is scanned by
+	// advancing off instead of reading from r. last is the size of the
+	// previously read rune, which is zero if it can't be unread.
+	src  []byte
+	off  int
+	last int
 }
 
 // New returns a new instance of Scanner.
@@ -33,13 +43,32 @@ func New(r io.Reader) *Scanner {
 	}
 }
 
+// NewBytes returns a new instance of Scanner which scans the given source.
+// Instead of reading rune by rune from a buffered reader, it scans the slice by
+// index, which is faster for large inputs. The slice must not be modified while
+// scanning.
+func NewBytes(src []byte) *Scanner {
+	return &Scanner{
+		src: src,
+		pos: token.Pos{Filename: "", Line: 1, Char: 0},
+	}
+}
+
 // NewFileScanner returns a new instance of Scanner, but will exclusively take
 // an *os.File as argument instead of the more general io.Reader interface.
 // Therefore it will enhance token positions with the filename.
 func NewFileScanner(f *os.File) *Scanner {
+	return NewNamed(f, f.Name())
+}
+
+// NewNamed returns a new instance of Scanner which enhances token positions
+// with the given filename. It is used for sources which originate from a file
+// but aren't read from an *os.File, like files included by another one, so
+// their tokens carry the name of the file they originate from.
+func NewNamed(r io.Reader, filename string) *Scanner {
 	return &Scanner{
-		r:   bufio.NewReader(f),
-		pos: token.Pos{Filename: f.Name(), Line: 1, Char: 0},
+		r:   bufio.NewReader(r),
+		pos: token.Pos{Filename: filename, Line: 1, Char: 0},
 	}
 }
 
@@ -52,7 +81,7 @@ func (s *Scanner) Scan() (token.Token, string, token.Pos) {
 	// If we see a newline then consume all contiguous newline.
 	// If we see an exclamation mark then consume as a comment.
 	// If we see a dot then consume as a directive.
-	// If we see a digit consume as an integer.
+	// If we see a digit or a single quote consume as an integer.
 	// If we see a letter or % then consume as an ident or reserved word.
 	if isWhitespace(ch) {
 		s.unread()
@@ -72,6 +101,9 @@ func (s *Scanner) Scan() (token.Token, string, token.Pos) {
 	} else if isNumber(ch) {
 		s.unread()
 		return s.scanInteger()
+	} else if ch == '\'' {
+		s.unread()
+		return s.scanChar()
 	} else if ch == '%' {
 		s.unread()
 		return s.scanRegister()
@@ -94,12 +126,45 @@ func (s *Scanner) Scan() (token.Token, string, token.Pos) {
 		return token.COMMA, string(ch), pos
 	case ':':
 		return token.COLON, string(ch), pos
+	case '=':
+		if next, _ := s.read(); next == '=' {
+			return token.EQL, "==", pos
+		}
+		s.unread()
+	case '<':
+		if next, _ := s.read(); next == '=' {
+			return token.LEQ, "<=", pos
+		}
+		s.unread()
+		return token.LSS, string(ch), pos
+	case '>':
+		if next, _ := s.read(); next == '=' {
+			return token.GEQ, ">=", pos
+		}
+		s.unread()
+		return token.GTR, string(ch), pos
 	}
 
 	// No match results in an illegal token.
 	return token.ILLEGAL, string(ch), pos
 }
 
+// ScanLexeme returns the next significant token as lexeme. Instead of being
+// returned as separate tokens, whitespace, newlines and comments are attached
+// to the following significant token as its leading trivia. Trivia at the end
+// of the input is attached to the EOF token. This enables a lossless
+// representation of the source.
+func (s *Scanner) ScanLexeme() token.Lexeme {
+	var trivia []token.Lexeme
+	for {
+		tok, lit, pos := s.Scan()
+		if !tok.IsTrivia() {
+			return token.Lexeme{Token: tok, Literal: lit, Pos: pos, LeadingTrivia: trivia}
+		}
+		trivia = append(trivia, token.Lexeme{Token: tok, Literal: lit, Pos: pos})
+	}
+}
+
 // scanComment consumes the current rune and all contiguous comment runes.
 func (s *Scanner) scanComment() (token.Token, string, token.Pos) {
 	// Create a buffer and drop first character.
@@ -202,7 +267,7 @@ func (s *Scanner) scanInteger() (token.Token, string, token.Pos) {
 		} else if (ch == 'x' || ch == 'X') && sawX {
 			s.unread()
 			break
-		} else if !isNumber(ch) && (ch != 'x' && ch != 'X') {
+		} else if !isNumber(ch) && (ch != 'x' && ch != 'X') && ch != '_' {
 			s.unread()
 			break
 		} else {
@@ -210,16 +275,101 @@ func (s *Scanner) scanInteger() (token.Token, string, token.Pos) {
 		}
 	}
 
+	// Binary integers are prefixed with 0b. The b is a hex digit, so it is
+	// already part of the literal and only has to be told apart from one.
+	lit := buf.String()
+	sawB := len(lit) > 1 && lit[0] == '0' && (lit[1] == 'b' || lit[1] == 'B')
+	if sawB {
+		for _, ch := range lit[2:] {
+			if ch != '0' && ch != '1' && ch != '_' {
+				return token.ILLEGAL, lit, pos
+			}
+		}
+	}
+
+	// Underscores are allowed as digit separators, but only between two
+	// digits. This makes leading, trailing and double underscores illegal.
+	for i := 0; i < len(lit); i++ {
+		if lit[i] == '_' && (i == len(lit)-1 || !isNumber(rune(lit[i-1])) || !isNumber(rune(lit[i+1])) || (sawB && i == 2)) {
+			return token.ILLEGAL, lit, pos
+		}
+	}
+
 	// Check if literal can be parsed to valid integer.
-	if _, err := strconv.ParseInt(buf.String(), 0, 64); err != nil {
+	if _, err := strconv.ParseInt(strings.Replace(lit, "_", "", -1), 0, 64); err != nil {
 		return token.ILLEGAL, buf.String(), pos
 	}
 	val := strings.Replace(buf.String(), "X", "x", -1)
+	if sawB {
+		val = "0b" + val[2:]
+	}
 
 	// Return as an integer.
 	return token.INT, val, pos
 }
 
+// scanChar consumes a character literal enclosed in single quotes, like 'A'.
+// The literal is an integer whose value is the code point of the character.
+func (s *Scanner) scanChar() (token.Token, string, token.Pos) {
+	// Create a buffer and read the opening quote into it.
+	var buf bytes.Buffer
+	ch, pos := s.read()
+	buf.WriteRune(ch)
+
+	// Read every subsequent character into the buffer until the closing quote.
+	// An escaped character is read along with its backslash. Newlines and EOF
+	// will cause the loop to exit.
+	for {
+		ch, _ := s.read()
+		if ch == eof {
+			break
+		} else if isNewline(ch) {
+			s.unread()
+			break
+		}
+		buf.WriteRune(ch)
+		if ch == '\'' {
+			break
+		} else if ch == '\\' {
+			if ch, _ = s.read(); ch == eof {
+				break
+			} else if isNewline(ch) {
+				s.unread()
+				break
+			}
+			buf.WriteRune(ch)
+		}
+	}
+
+	// The literal must hold exactly one, possibly escaped, character.
+	if _, valid := CharValue(buf.String()); !valid {
+		return token.ILLEGAL, buf.String(), pos
+	}
+	return token.INT, buf.String(), pos
+}
+
+// escapes maps the characters of escape sequences allowed in character
+// literals to the character they stand for.
+var escapes = map[rune]rune{'n': '\n', 't': '\t', '0': 0, '\'': '\'', '\\': '\\'}
+
+// CharValue returns the code point of a character literal enclosed in single
+// quotes, like 'A'. The escape sequences \n, \t, \0, \' and \\ are supported.
+// It returns false if the literal doesn't hold exactly one character.
+func CharValue(lit string) (rune, bool) {
+	if len(lit) < 3 || lit[0] != '\'' || lit[len(lit)-1] != '\'' {
+		return 0, false
+	}
+	body := []rune(lit[1 : len(lit)-1])
+	switch {
+	case len(body) == 1 && body[0] != '\\' && body[0] != '\'':
+		return body[0], true
+	case len(body) == 2 && body[0] == '\\':
+		ch, valid := escapes[body[1]]
+		return ch, valid
+	}
+	return 0, false
+}
+
 // scanNewline consumes the current rune and all contiguous newline.
 func (s *Scanner) scanNewline() (token.Token, string, token.Pos) {
 	// Create a buffer and read the current character into it.
@@ -281,6 +431,12 @@ func (s *Scanner) scanRegister() (token.Token, string, token.Pos) {
 		return token.ILLEGAL, buf.String(), pos
 	}
 
+	// The program counter is the only special register which can be read by
+	// name.
+	if buf.String() == "%pc" {
+		return token.REG, buf.String(), pos
+	}
+
 	// First identifier char must be a 'r'.
 	if ch := buf.Bytes()[1]; ch != 'r' {
 		return token.ILLEGAL, buf.String(), pos
@@ -322,6 +478,17 @@ func (s *Scanner) read() (rune, token.Pos) {
 	}
 	s.pos.Char++
 
+	if s.r == nil {
+		if s.off >= len(s.src) {
+			s.last = 0
+			return eof, s.pos
+		}
+		ch, size := utf8.DecodeRune(s.src[s.off:])
+		s.off += size
+		s.last = size
+		return ch, s.pos
+	}
+
 	ch, _, err := s.r.ReadRune()
 	if err != nil {
 		return eof, s.pos
@@ -329,9 +496,15 @@ func (s *Scanner) read() (rune, token.Pos) {
 	return ch, s.pos
 }
 
-// unread places the previously read rune back on the reader.
+// unread places the previously read rune back on the reader. Like
+// bufio.Reader.UnreadRune, only the rune read last can be unread.
 func (s *Scanner) unread() {
-	s.r.UnreadRune()
+	if s.r == nil {
+		s.off -= s.last
+		s.last = 0
+	} else {
+		s.r.UnreadRune()
+	}
 	s.pos.Char--
 }
 
@@ -344,8 +517,10 @@ func isNewline(ch rune) bool { return ch == '\n' || ch == '\r' }
 // isLetter returns true if the rune is a letter.
 func isLetter(ch rune) bool { return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') }
 
-// isNumber returns true if the rune is a digit.
-func isNumber(ch rune) bool { return (ch >= '0' && ch <= '9') || (ch >= 'A' && ch <= 'F') }
+// isNumber returns true if the rune is a decimal or hexadecimal digit.
+func isNumber(ch rune) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'A' && ch <= 'F') || (ch >= 'a' && ch <= 'f')
+}
 
 // stripCR removes every carriage-return from a slice of bytes, effectively
 // turning a CRLF into a LF.
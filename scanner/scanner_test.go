@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"bytes"
 	"reflect"
 	"strings"
 	"testing"
@@ -8,6 +9,30 @@ import (
 	"github.com/lukasmalkmus/arc/token"
 )
 
+// validProg is a valid ARC program, the same as used by the parser tests.
+const validProg = `
+! main.arc
+! This is a valid ARC sample program.
+.begin
+.org 0x800
+main:   ld [x], %r1				! Load x.
+        ld [y], %r2				! Load y.
+        add %r1, %r2, %r3
+		subcc %r3, 2, %r4
+		sll %r4, 3, %r5
+        st %r5, [z]
+        ba exit					! Always branch to exit routine.
+exit:	ld [z], %r6				! jmpl %r15 + 4, %r6
+
+! Start data section at 0x1000.
+.org 0x1000
+x: 2
+y: 4
+z: 0
+.end
+
+`
+
 func TestScanner_Scan(t *testing.T) {
 	tests := []struct {
 		str  string
@@ -27,9 +52,20 @@ func TestScanner_Scan(t *testing.T) {
 		{"123x", token.ILLEGAL, "123x", 1},   // Illegal integer (wrong hex representation)
 		{"08", token.ILLEGAL, "08", 1},       // Octal out of range
 		{"0xx08", token.ILLEGAL, "0xx08", 1}, // Illegal hex syntax
+		{"_1", token.ILLEGAL, "_", 1},        // Leading digit separator, neither integer nor identifier
+		{"1__0", token.ILLEGAL, "1__0", 1},   // Double digit separator
+		{"1_", token.ILLEGAL, "1_", 1},       // Trailing digit separator
+		{"0x_10", token.ILLEGAL, "0x_10", 1}, // Digit separator after prefix
+		{"0b12", token.ILLEGAL, "0b12", 1},   // Binary out of range
+		{"0b_1", token.ILLEGAL, "0b_1", 1},   // Digit separator after binary prefix
+		{"''", token.ILLEGAL, "''", 1},       // Empty character
+		{"'AB'", token.ILLEGAL, "'AB'", 1},   // More than one character
+		{"'A", token.ILLEGAL, "'A", 1},       // Unterminated character
+		{`'\q'`, token.ILLEGAL, `'\q'`, 1},   // Unknown escape sequence
 		{"%", token.ILLEGAL, "%", 1},         // No ident after register char
 		{"%%", token.ILLEGAL, "%", 1},        // No ident after register char
 		{"%2", token.ILLEGAL, "%2", 1},       // First ident char is not a letter
+		{"%psr", token.ILLEGAL, "%psr", 1},   // Only the pc is a named special register
 		{"", token.EOF, "", 1},
 		{" ", token.WS, " ", 1},
 		{"   ", token.WS, "   ", 1},
@@ -54,6 +90,7 @@ func TestScanner_Scan(t *testing.T) {
 		{"%r1", token.REG, "%r1", 1},
 		{"%r10", token.REG, "%r10", 1},
 		{"%r31", token.REG, "%r31", 1},
+		{"%pc", token.REG, "%pc", 1},
 
 		// Integers
 		{"4", token.INT, "4", 1},
@@ -61,9 +98,20 @@ func TestScanner_Scan(t *testing.T) {
 		{"12", token.INT, "12", 1},
 		{"16", token.INT, "16", 1},
 		{"128", token.INT, "128", 1},
-		{"07", token.INT, "07", 1},     // Octal
-		{"0x08", token.INT, "0x08", 1}, // Hex
-		{"0X08", token.INT, "0x08", 1}, // X will get transformed to lower case
+		{"07", token.INT, "07", 1},                   // Octal
+		{"0x08", token.INT, "0x08", 1},               // Hex
+		{"0X08", token.INT, "0x08", 1},               // X will get transformed to lower case
+		{"0xa", token.INT, "0xa", 1},                 // Hex with lower case digit
+		{"0xFF", token.INT, "0xFF", 1},               // Hex with upper case digits
+		{"1_000", token.INT, "1_000", 1},             // Digit separator
+		{"0x0010_0000", token.INT, "0x0010_0000", 1}, // Hex with digit separator
+		{"0b1111", token.INT, "0b1111", 1},           // Binary
+		{"0B0", token.INT, "0b0", 1},                 // B will get transformed to lower case
+		{"0b1010_1010", token.INT, "0b1010_1010", 1}, // Binary with digit separator
+		{"'A'", token.INT, "'A'", 1},                 // Character
+		{`'\n'`, token.INT, `'\n'`, 1},               // Escaped newline
+		{`'\0'`, token.INT, `'\0'`, 1},               // Escaped null character
+		{`'\''`, token.INT, `'\''`, 1},               // Escaped quote
 
 		// Operators
 		{"+", token.PLUS, "+", 1},
@@ -77,6 +125,15 @@ func TestScanner_Scan(t *testing.T) {
 		{",", token.COMMA, ",", 1},
 		{":", token.COLON, ":", 1},
 
+		// Comparison operators
+		{"==", token.EQL, "==", 1},
+		{"<", token.LSS, "<", 1},
+		{"<=", token.LEQ, "<=", 1},
+		{">", token.GTR, ">", 1},
+		{">= 4", token.GEQ, ">=", 1},
+		{"=", token.ILLEGAL, "=", 1},
+		{"=>", token.ILLEGAL, "=", 1},
+
 		// Keywords
 		{"ld", token.LOAD, "ld", 1},
 		{"LD", token.LOAD, "LD", 1},
@@ -131,6 +188,9 @@ func TestScanner_Scan(t *testing.T) {
 		{".begin", token.BEGIN, ".begin", 1},
 		{".end", token.END, ".end", 1},
 		{".org", token.ORG, ".org", 1},
+		{".global", token.GLOBAL, ".global", 1},
+		{".extern", token.EXTERN, ".extern", 1},
+		{".assert", token.ASSERT, ".assert", 1},
 	}
 
 	for _, tt := range tests {
@@ -150,6 +210,79 @@ func TestScanner_Scan(t *testing.T) {
 	}
 }
 
+// TestScanner_ScanLexeme validates that trivia is attached to the following
+// significant token.
+func TestScanner_ScanLexeme(t *testing.T) {
+	s := New(strings.NewReader("\n\n! comment\n  ld\n"))
+
+	lex := s.ScanLexeme()
+	equals(t, token.LOAD, lex.Token)
+	equals(t, "ld", lex.Literal)
+	equals(t, token.Pos{Line: 4, Char: 3}, lex.Pos)
+
+	var trivia []token.Token
+	var lits []string
+	for _, tr := range lex.LeadingTrivia {
+		trivia = append(trivia, tr.Token)
+		lits = append(lits, tr.Literal)
+	}
+	equals(t, []token.Token{token.NL, token.COMMENT, token.NL, token.WS}, trivia)
+	equals(t, []string{"\n\n", "! comment", "\n", "  "}, lits)
+
+	// Trailing trivia is attached to EOF.
+	lex = s.ScanLexeme()
+	equals(t, token.EOF, lex.Token)
+	equals(t, 1, len(lex.LeadingTrivia))
+	equals(t, token.NL, lex.LeadingTrivia[0].Token)
+}
+
+// TestNewBytes validates that scanning from a byte slice produces the same
+// tokens as scanning from a reader.
+func TestNewBytes(t *testing.T) {
+	for _, src := range []string{validProg, "", "ld", "x: 0xx08 !\r\n\r\n%", "äöü\n.org 2048"} {
+		r, b := New(strings.NewReader(src)), NewBytes([]byte(src))
+		for {
+			rTok, rLit, rPos := r.Scan()
+			bTok, bLit, bPos := b.Scan()
+			equals(t, rTok, bTok)
+			equals(t, rLit, bLit)
+			equals(t, rPos, bPos)
+			if rTok == token.EOF {
+				break
+			}
+		}
+	}
+}
+
+// BenchmarkScanner_Scan benchmarks scanning a large program from a reader.
+func BenchmarkScanner_Scan(b *testing.B) {
+	src := largeProg(1000)
+	b.SetBytes(int64(len(src)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := New(bytes.NewReader(src))
+		for tok, _, _ := s.Scan(); tok != token.EOF; tok, _, _ = s.Scan() {
+		}
+	}
+}
+
+// BenchmarkNewBytes_Scan benchmarks scanning a large program from a byte slice.
+func BenchmarkNewBytes_Scan(b *testing.B) {
+	src := largeProg(1000)
+	b.SetBytes(int64(len(src)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := NewBytes(src)
+		for tok, _, _ := s.Scan(); tok != token.EOF; tok, _, _ = s.Scan() {
+		}
+	}
+}
+
+// largeProg generates a program by repeating validProg n times.
+func largeProg(n int) []byte {
+	return bytes.Repeat([]byte(validProg), n)
+}
+
 // assert fails the test if the condition is false.
 func assert(tb testing.TB, condition bool, msg string, v ...interface{}) {
 	tb.Helper()
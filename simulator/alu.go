@@ -0,0 +1,68 @@
+package simulator
+
+import (
+	"strings"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// operation computes the result of an arithmetic or logic instruction.
+type operation func(a, b int32) int32
+
+func add(a, b int32) int32 { return a + b }
+func sub(a, b int32) int32 { return a - b }
+func and(a, b int32) int32 { return a & b }
+func or(a, b int32) int32  { return a | b }
+func orn(a, b int32) int32 { return a | ^b }
+func xor(a, b int32) int32 { return a ^ b }
+
+// sll shifts logically to the left and sra arithmetically to the right, which
+// preserves the sign bit. Only the lowest five bits of the shift count are
+// used, so it is taken modulo 32.
+func sll(a, b int32) int32 { return a << (uint32(b) & 31) }
+func sra(a, b int32) int32 { return a >> (uint32(b) & 31) }
+
+// conditions computes the overflow and carry condition codes of an
+// instruction from its operands and result.
+type conditions func(a, b, res int32) (overflow, carry bool)
+
+// addCC sets overflow if the signed sum doesn't fit into 32 bits and carry if
+// the unsigned sum doesn't.
+func addCC(a, b, res int32) (bool, bool) {
+	overflow := (a < 0) == (b < 0) && (res < 0) != (a < 0)
+	carry := uint64(uint32(a))+uint64(uint32(b)) > 0xffffffff
+	return overflow, carry
+}
+
+// subCC sets overflow if the signed difference doesn't fit into 32 bits and
+// carry if the unsigned subtraction borrows.
+func subCC(a, b, res int32) (bool, bool) {
+	overflow := (a < 0) != (b < 0) && (res < 0) != (a < 0)
+	carry := uint32(a) < uint32(b)
+	return overflow, carry
+}
+
+// logicCC clears overflow and carry, as logic instructions never set them.
+func logicCC(a, b, res int32) (bool, bool) { return false, false }
+
+// execALU executes an arithmetic or logic statement on the simulator. The
+// result of the operation applied to the source register and the operand is
+// written to the destination register. If cc is not nil, the condition codes
+// are set as well.
+func (s *Simulator) execALU(stmt ast.Statement, src *ast.Register, op ast.Operand, dst *ast.Register, f operation, cc conditions) error {
+	a, b, err := s.operands(stmt, src, op)
+	if err != nil {
+		return err
+	}
+	if err = s.writable(stmt, dst); err != nil {
+		return err
+	}
+	res := f(a, b)
+	s.setRegister(strings.TrimPrefix(dst.Name, "%"), Register(res))
+	if cc != nil {
+		overflow, carry := cc(a, b, res)
+		s.setConditionCodes(res, overflow, carry)
+	}
+	s.incPC()
+	return nil
+}
@@ -1,4 +1,5 @@
 package simulator
 
-// Memory is a block of memory.
-type Memory int8
+// Memory is the main memory of the simulator. It maps word aligned addresses to
+// the words stored there. Only words which have been written are present.
+type Memory map[int32]int32
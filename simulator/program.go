@@ -0,0 +1,147 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// Load resets the simulator and loads a program into it. Every instruction and
+// data word is placed at the address the assembler would assign to it. The
+// values of data labels and .word directives are written to memory and the
+// program counter is set to the first statement of the program. An error is
+// returned if statements overlap in memory, like after an .org directive
+// pointing into code placed before. The program isn't loaded then.
+func (s *Simulator) Load(prog *ast.Program) error {
+	s.Reset()
+
+	layout, err := ast.Layout(prog)
+	if err != nil {
+		return err
+	}
+	s.consts = prog.Constants()
+	for name, addr := range layout.Symbols {
+		s.symbols[name] = addr
+	}
+	start := true
+	for _, stmt := range prog.Statements {
+		addr, occupies := layout.Addrs[stmt]
+		if !occupies {
+			continue
+		}
+		if start {
+			s.registers["pc"] = Register(addr)
+			start = false
+		}
+		s.program[addr] = stmt
+
+		for i, val := range data(stmt) {
+			s.memory[addr+int32(i)*4] = val
+		}
+	}
+
+	return nil
+}
+
+// data returns the words of data statements. These are labels referencing an
+// integer and .word directives, labeled or not. Nil is returned for any other
+// statement.
+func data(stmt ast.Statement) []int32 {
+	switch s := stmt.(type) {
+	case *ast.LabelStatement:
+		if i, valid := s.Reference.(*ast.Integer); valid {
+			return []int32{i.Value}
+		}
+		if w, valid := s.Reference.(*ast.WordStatement); valid {
+			return data(w)
+		}
+	case *ast.WordStatement:
+		vals := make([]int32, len(s.Values))
+		for i, val := range s.Values {
+			vals[i] = val.Value
+		}
+		return vals
+	}
+	return nil
+}
+
+// Step executes the statement the program counter points to. If the program
+// counter doesn't point to an instruction, nothing is executed and Finished is
+// returned. An error is returned if the execution of the statement fails.
+func (s *Simulator) Step() (StopReason, error) {
+	if !s.executable() {
+		return Finished, nil
+	}
+	stmt := s.program[int32(s.registers["pc"])]
+	if err := s.Exec(stmt); err != nil {
+		return Failed, err
+	}
+	s.coverage[stmt.Pos()]++
+	return Stepped, nil
+}
+
+// Coverage returns how often every statement of the loaded program has been
+// executed, keyed by the position of the statement. Statements which haven't
+// been executed are missing. Only statements executed by Step, and therefore
+// by Run and RunUntilLabel, are counted. Stepping back doesn't reduce the
+// counts. Loading a program resets them.
+func (s *Simulator) Coverage() map[token.Pos]int {
+	res := make(map[token.Pos]int, len(s.coverage))
+	for pos, n := range s.coverage {
+		res[pos] = n
+	}
+	return res
+}
+
+// Run loads the program and executes it to completion. The program is complete
+// once the program counter leaves the code, that is it points to data or to an
+// address without any statement. The final state can be inspected afterwards.
+// The returned reason tells why the run stopped: Finished on completion,
+// StepLimit if the program doesn't complete within the step limit, which
+// guards against infinite loops, and Failed if a statement fails to execute.
+// Only the latter comes with an error, as does a program failing to load.
+func (s *Simulator) Run(prog *ast.Program) (StopReason, error) {
+	if err := s.Load(prog); err != nil {
+		return Failed, err
+	}
+	return s.run(func() bool { return false })
+}
+
+// RunUntilLabel executes the loaded program until the program counter reaches
+// the address of the given label and returns ReachedLabel. The statement at
+// the label is not executed. The other reasons are the same as for Run. An
+// error is returned if the label isn't defined or a statement fails to
+// execute.
+func (s *Simulator) RunUntilLabel(name string) (StopReason, error) {
+	addr, ok := s.symbols[name]
+	if !ok {
+		return Failed, fmt.Errorf("undefined label %q", name)
+	}
+	return s.run(func() bool { return int32(s.registers["pc"]) == addr })
+}
+
+// run steps through the loaded program until it finishes, the step limit is
+// reached or stop returns true, which is checked before every step.
+func (s *Simulator) run(stop func() bool) (StopReason, error) {
+	for steps := 0; ; steps++ {
+		if stop() {
+			return ReachedLabel, nil
+		}
+		if steps == s.opts.StepLimit && s.executable() {
+			return StepLimit, nil
+		}
+		if reason, err := s.Step(); reason != Stepped {
+			return reason, err
+		}
+	}
+}
+
+// executable returns true if the program counter points to an instruction.
+func (s *Simulator) executable() bool {
+	stmt, ok := s.program[int32(s.registers["pc"])]
+	if !ok {
+		return false
+	}
+	return data(stmt) == nil
+}
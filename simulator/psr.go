@@ -0,0 +1,89 @@
+package simulator
+
+import "fmt"
+
+// Condition code bits of the processor status register (psr). They are set by
+// the instructions with the "cc" suffix and tested by the conditional
+// branches.
+const (
+	psrC Register = 1 << (20 + iota) // carry
+	psrV                             // overflow
+	psrZ                             // zero
+	psrN                             // negative
+)
+
+// psrCC masks all condition code bits of the processor status register.
+const psrCC = psrN | psrZ | psrV | psrC
+
+// Flags are the condition codes of the simulator.
+type Flags struct {
+	// N is set if the result was negative.
+	N bool
+	// Z is set if the result was zero.
+	Z bool
+	// V is set if the result overflowed the signed 32 bit range.
+	V bool
+	// C is set if the result produced a carry (or borrow) out of 32 bits.
+	C bool
+}
+
+// String returns the string representation of the flags, like "N=0 Z=1 V=0
+// C=0".
+func (f Flags) String() string {
+	b := func(set bool) int {
+		if set {
+			return 1
+		}
+		return 0
+	}
+	return fmt.Sprintf("N=%d Z=%d V=%d C=%d", b(f.N), b(f.Z), b(f.V), b(f.C))
+}
+
+// Flags returns the condition codes set by the last instruction with the "cc"
+// suffix. They are stored in the processor status register, so stepping back
+// restores them as well.
+func (s *Simulator) Flags() Flags {
+	psr := s.registers["psr"]
+	return Flags{
+		N: psr&psrN != 0,
+		Z: psr&psrZ != 0,
+		V: psr&psrV != 0,
+		C: psr&psrC != 0,
+	}
+}
+
+// SetFlags sets the condition codes, which enables setting up the initial state
+// before executing a branch. The other bits of the processor status register
+// are kept. Like SetReg, the change isn't recorded in the step log.
+func (s *Simulator) SetFlags(f Flags) {
+	psr := s.registers["psr"] &^ psrCC
+	for _, flag := range []struct {
+		set bool
+		bit Register
+	}{{f.N, psrN}, {f.Z, psrZ}, {f.V, psrV}, {f.C, psrC}} {
+		if flag.set {
+			psr |= flag.bit
+		}
+	}
+	s.registers["psr"] = psr
+}
+
+// setConditionCodes updates the condition codes with the result of an
+// operation. The negative and zero bits are derived from the result, the
+// overflow and carry bits are passed in because they depend on the operation.
+func (s *Simulator) setConditionCodes(res int32, overflow, carry bool) {
+	psr := s.registers["psr"] &^ psrCC
+	if res < 0 {
+		psr |= psrN
+	}
+	if res == 0 {
+		psr |= psrZ
+	}
+	if overflow {
+		psr |= psrV
+	}
+	if carry {
+		psr |= psrC
+	}
+	s.setRegister("psr", psr)
+}
@@ -1,24 +1,124 @@
 package simulator
 
 import (
-	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/parser"
+	"github.com/lukasmalkmus/arc/token"
 )
 
+// DefaultIOAddress is the default memory address of the memory-mapped console
+// output (0xffff0000).
+const DefaultIOAddress int32 = -0x10000
+
+// DefaultStepLimit is the default maximum number of statements executed by a
+// single run.
+const DefaultStepLimit = 100000
+
+// DefaultNumRegisters is the default number of general purpose registers.
+const DefaultNumRegisters = 32
+
+// Options are configuration values for the Simulator.
+type Options struct {
+	// IOAddress is the memory address of the memory-mapped console output.
+	// Storing a word to this address writes its lowest byte as character to
+	// Output instead of memory. If unset, DefaultIOAddress is used.
+	IOAddress int32
+	// Output is where characters stored to the I/O address will be written
+	// to.
+	Output io.Writer
+	// StepLimit is the maximum number of statements executed by a single run.
+	// It protects against programs which never finish. If unset,
+	// DefaultStepLimit is used.
+	StepLimit int
+	// NumRegisters is the number of general purpose registers %r0 to %rN-1.
+	// References to registers beyond are rejected. If unset,
+	// DefaultNumRegisters is used.
+	NumRegisters int
+}
+
 // Simulator is simulating an ARC microprocessor. It executes one statement at a
 // time.
 type Simulator struct {
+	opts *Options
+
+	registers map[string]Register
+	memory    Memory
+
+	// program maps the addresses of the loaded program to the statements
+	// located there. symbols holds the addresses of the labels identifiers in
+	// expressions are resolved against, consts the values of the constants
+	// identifiers used as operands are resolved against.
+	program map[int32]ast.Statement
+	symbols ast.SymbolTable
+	consts  map[string]int32
+
+	// log records the changes of every executed statement which enables
+	// stepping backwards.
+	log []*step
+
+	// coverage counts how often the statements of the loaded program have
+	// been executed by Step, keyed by their position.
+	coverage map[token.Pos]int
+}
+
+// change is the inverse operation of a register or memory write. It holds the
+// value the register or memory word had before it was overwritten. Memory
+// changes have no register name.
+type change struct {
+	register string
+	old      Register
+
+	// address is the address of the overwritten memory word. If existed is
+	// false, the word hadn't been written before.
+	address int32
+	existed bool
+}
+
+// step is an entry of the step log. It holds the inverse operations needed to
+// reverse the execution of a statement. Operations which can't be reversed by
+// restoring previous values (e.g. I/O) store a snapshot of the complete state
+// instead.
+type step struct {
+	changes  []change
+	snapshot *snapshot
+}
+
+// snapshot is a copy of the complete state of the simulator.
+type snapshot struct {
 	registers map[string]Register
+	memory    Memory
 }
 
 // New creates a new ARC Simulator.
-func New() *Simulator {
+func New(options *Options) *Simulator {
 	s := &Simulator{
+		opts:      options,
 		registers: make(map[string]Register),
 	}
+
+	// Set defaults.
+	if s.opts == nil {
+		s.opts = &Options{}
+	}
+	if s.opts.IOAddress == 0 {
+		s.opts.IOAddress = DefaultIOAddress
+	}
+	if s.opts.Output == nil {
+		s.opts.Output = os.Stdout
+	}
+	if s.opts.StepLimit == 0 {
+		s.opts.StepLimit = DefaultStepLimit
+	}
+	if s.opts.NumRegisters == 0 {
+		s.opts.NumRegisters = DefaultNumRegisters
+	}
+
 	s.Reset()
 
 	return s
@@ -26,42 +126,264 @@ func New() *Simulator {
 
 // Exec will parse and run the statement on the simulator.
 func (s *Simulator) Exec(stmt ast.Statement) error {
-	var err error
+	// Start a new entry in the step log. Every change the statement makes is
+	// recorded there.
+	s.log = append(s.log, &step{})
+
+	// A statement which failed to execute must not leave any changes behind.
+	err := s.exec(stmt)
+	if err != nil {
+		s.StepBack()
+	}
+
+	return err
+}
+
+// exec dispatches the statement to its execution function.
+func (s *Simulator) exec(stmt ast.Statement) error {
 	switch stmt.(type) {
 	case *ast.LabelStatement:
-		err = s.execLabelStatement(stmt.(*ast.LabelStatement))
+		return s.execLabelStatement(stmt.(*ast.LabelStatement))
 	case *ast.LoadStatement:
-		err = s.execLoadStatement(stmt.(*ast.LoadStatement))
+		return s.execLoadStatement(stmt.(*ast.LoadStatement))
 	case *ast.StoreStatement:
-		err = s.execStoreStatement(stmt.(*ast.StoreStatement))
-	default:
-		return fmt.Errorf("not implemented")
+		return s.execStoreStatement(stmt.(*ast.StoreStatement))
+	case *ast.AddStatement:
+		st := stmt.(*ast.AddStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, add, nil)
+	case *ast.AddCCStatement:
+		st := stmt.(*ast.AddCCStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, add, addCC)
+	case *ast.SubStatement:
+		st := stmt.(*ast.SubStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, sub, nil)
+	case *ast.SubCCStatement:
+		st := stmt.(*ast.SubCCStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, sub, subCC)
+	case *ast.AndStatement:
+		st := stmt.(*ast.AndStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, and, nil)
+	case *ast.AndCCStatement:
+		st := stmt.(*ast.AndCCStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, and, logicCC)
+	case *ast.OrStatement:
+		st := stmt.(*ast.OrStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, or, nil)
+	case *ast.OrCCStatement:
+		st := stmt.(*ast.OrCCStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, or, logicCC)
+	case *ast.OrnStatement:
+		st := stmt.(*ast.OrnStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, orn, nil)
+	case *ast.OrnCCStatement:
+		st := stmt.(*ast.OrnCCStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, orn, logicCC)
+	case *ast.XorStatement:
+		st := stmt.(*ast.XorStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, xor, nil)
+	case *ast.XorCCStatement:
+		st := stmt.(*ast.XorCCStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, xor, logicCC)
+	case *ast.SLLStatement:
+		st := stmt.(*ast.SLLStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, sll, nil)
+	case *ast.SRAStatement:
+		st := stmt.(*ast.SRAStatement)
+		return s.execALU(stmt, st.Source, st.Operand, st.Destination, sra, nil)
+	case *ast.BEStatement:
+		return s.execBranch(stmt, stmt.(*ast.BEStatement).Target, s.Flags().Z)
+	case *ast.BNEStatement:
+		return s.execBranch(stmt, stmt.(*ast.BNEStatement).Target, !s.Flags().Z)
+	case *ast.BNEGStatement:
+		return s.execBranch(stmt, stmt.(*ast.BNEGStatement).Target, s.Flags().N)
+	case *ast.BPOSStatement:
+		return s.execBranch(stmt, stmt.(*ast.BPOSStatement).Target, !s.Flags().N)
+	case *ast.BAStatement:
+		return s.execBranch(stmt, stmt.(*ast.BAStatement).Target, true)
+	case *ast.CallStatement:
+		return s.execCallStatement(stmt.(*ast.CallStatement))
+	case *ast.JumpAndLinkStatement:
+		return s.execJumpAndLinkStatement(stmt.(*ast.JumpAndLinkStatement))
+	case *ast.NopStatement, *ast.CmpStatement, *ast.RetStatement, *ast.MovStatement, *ast.ClrStatement:
+		return s.exec(ast.LowerStatement(stmt))
 	}
+	return fmt.Errorf("not implemented")
+}
 
-	return err
+// StepBack reverses the last executed statement by applying the inverse
+// operations recorded in the step log. If the statement recorded a snapshot,
+// the snapshot is restored instead. An error is returned if there is no
+// statement left to step back.
+func (s *Simulator) StepBack() error {
+	if len(s.log) == 0 {
+		return fmt.Errorf("no executed statement to step back")
+	}
+	last := s.log[len(s.log)-1]
+	s.log = s.log[:len(s.log)-1]
+
+	// Restore the snapshot if the statement wasn't reversible.
+	if last.snapshot != nil {
+		s.registers = last.snapshot.registers
+		s.memory = last.snapshot.memory
+		return nil
+	}
+
+	// Apply inverse operations in reverse order.
+	for i := len(last.changes) - 1; i >= 0; i-- {
+		last.changes[i].revert(s.registers, s.memory)
+	}
+
+	return nil
 }
 
-// Reset resets the Simulator. This will clear all registers and memory
-// allocations.
+// Reset resets the Simulator. This will clear all registers, memory
+// allocations and the loaded program.
 func (s *Simulator) Reset() {
-	for i := 0; i < 32; i++ {
+	for i := 0; i < s.opts.NumRegisters; i++ {
 		r := "r" + strconv.Itoa(i)
 		s.registers[r] = NewRegister()
 	}
 	s.registers["pc"] = NewRegister()
+	s.registers["psr"] = NewRegister()
+	s.memory = make(Memory)
+	s.program = make(map[int32]ast.Statement)
+	s.symbols = make(ast.SymbolTable)
+	s.consts = make(map[string]int32)
+	s.log = nil
+	s.coverage = make(map[token.Pos]int)
 }
 
-// State returns a string representation of the Simulators state.
-func (s Simulator) State() string {
-	var buf bytes.Buffer
+// ResetTarget resets a part of the Simulator, as selected by the argument of
+// the reset command of the REPL. An empty target resets the whole Simulator
+// like Reset, "flags" clears the condition codes and a register name, like
+// "%r5", zeroes that register. An error is returned if the target is neither.
+func (s *Simulator) ResetTarget(target string) error {
+	switch target = strings.TrimSpace(target); target {
+	case "":
+		s.Reset()
+		return nil
+	case "flags":
+		s.SetFlags(Flags{})
+		return nil
+	}
+	if _, err := s.Reg(target); err != nil {
+		return err
+	}
+	if strings.TrimPrefix(target, "%") == "r0" {
+		// %r0 always reads as zero, so there is nothing to reset.
+		return nil
+	}
+	return s.SetReg(target, 0)
+}
 
-	for i := 0; i < 32; i++ {
-		r := "r" + strconv.Itoa(i)
-		fmt.Fprintf(&buf, "%s:\t%s\n", r, s.registers[r].Hex())
+// ReadWord returns the word stored at the given memory address. Memory which
+// hasn't been written yet reads as zero. An error is returned if the address
+// isn't aligned on a word boundary.
+func (s *Simulator) ReadWord(addr int32) (int32, error) {
+	if addr%4 != 0 {
+		return 0, fmt.Errorf("unaligned memory access at 0x%08x", uint32(addr))
+	}
+	return s.memory[addr], nil
+}
+
+// WriteWord stores a word at the given memory address and records the inverse
+// operation in the step log. If the address is the I/O address, the lowest byte
+// of the word is written to the output as character instead. An error is
+// returned if the address isn't aligned on a word boundary or writing the
+// output fails.
+func (s *Simulator) WriteWord(addr int32, val int32) error {
+	if addr%4 != 0 {
+		return fmt.Errorf("unaligned memory access at 0x%08x", uint32(addr))
+	}
+
+	if addr == s.opts.IOAddress {
+		// Output can't be taken back, so stepping back must restore a
+		// snapshot.
+		s.checkpoint()
+		_, err := s.opts.Output.Write([]byte{byte(val)})
+		return err
+	}
+
+	if len(s.log) > 0 {
+		last := s.log[len(s.log)-1]
+		if last.snapshot == nil {
+			old, existed := s.memory[addr]
+			last.changes = append(last.changes, change{old: Register(old), address: addr, existed: existed})
+		}
+	}
+	s.memory[addr] = val
+	return nil
+}
+
+// Reg returns the value of the named register. Valid names are r0 to r31, pc
+// and psr, optionally prefixed with "%". An error is returned if the register
+// doesn't exist.
+func (s *Simulator) Reg(name string) (int32, error) {
+	name = strings.TrimPrefix(name, "%")
+	val, ok := s.registers[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown register %q", name)
+	}
+	if name == "r0" {
+		return 0, nil
+	}
+	return int32(val), nil
+}
+
+// SetReg sets the value of the named register, which enables setting up the
+// initial state before executing statements. Valid names are the same as for
+// Reg. The change isn't recorded in the step log, so it can't be stepped back.
+// An error is returned if the register doesn't exist or is r0, which is
+// read-only and always reads as zero.
+func (s *Simulator) SetReg(name string, v int32) error {
+	name = strings.TrimPrefix(name, "%")
+	if _, ok := s.registers[name]; !ok {
+		return fmt.Errorf("unknown register %q", name)
+	}
+	if name == "r0" {
+		return fmt.Errorf("register %q is read-only", name)
+	}
+	s.registers[name] = Register(v)
+	return nil
+}
+
+// Eval evaluates an expression against the current state of the simulator. It
+// uses the expression grammar of the parser, so the base is either a register
+// or a label, optionally followed by an offset, like "%r1 + 4". A plain
+// expression evaluates to its value, for a label that is its address. An
+// expression in square brackets, like "[x]", evaluates to the word stored at
+// the address it refers to. An error is returned if the expression doesn't
+// parse, uses an undefined label or an unknown register, or the memory access
+// isn't aligned.
+func (s *Simulator) Eval(expr string) (int32, error) {
+	expr = strings.TrimSpace(expr)
+	exp, err := parser.ParseExpression(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := exp.Resolve(s.symbols)
+	if err != nil {
+		return 0, err
+	}
+	val := res.Offset
+	if !res.Absolute() {
+		reg, err := s.Reg(res.Register.Name)
+		if err != nil {
+			return 0, err
+		}
+		val += reg
 	}
-	fmt.Fprintf(&buf, "%s:\t%s\n", "pc", s.registers["pc"].Hex())
 
-	return buf.String()
+	if strings.HasPrefix(expr, "[") {
+		return s.ReadWord(val)
+	}
+	return val, nil
+}
+
+// State returns a string representation of the Simulators state.
+func (s Simulator) State() string {
+	return s.Snapshot().String()
 }
 
 // Usage returns a usage string.
@@ -71,22 +393,268 @@ func (s Simulator) Usage() string {
 
 // execLoadStatement executes a ld command on the simulator.
 func (s *Simulator) execLoadStatement(stmt *ast.LoadStatement) error {
+	addr, err := s.effectiveAddress(stmt, stmt.Source)
+	if err != nil {
+		return err
+	}
+	if err = s.writable(stmt, stmt.Destination); err != nil {
+		return err
+	}
+	val, err := s.ReadWord(addr)
+	if err != nil {
+		return &SimulatorError{err.Error(), stmt.Pos()}
+	}
+	s.setRegister(strings.TrimPrefix(stmt.Destination.Name, "%"), Register(val))
 	s.incPC()
 	return nil
 }
 
 // execStoreStatement executes a st command on the simulator.
 func (s *Simulator) execStoreStatement(stmt *ast.StoreStatement) error {
+	addr, err := s.effectiveAddress(stmt, stmt.Destination)
+	if err != nil {
+		return err
+	}
+	val, err := s.register(stmt, stmt.Source)
+	if err != nil {
+		return err
+	}
+	if err = s.WriteWord(addr, int32(val)); err != nil {
+		return &SimulatorError{err.Error(), stmt.Pos()}
+	}
 	s.incPC()
 	return nil
 }
 
+// execBranch executes a branch command on the simulator. If the branch is
+// taken, the program counter is set to the address of the target label,
+// otherwise it advances to the next statement. An error is returned if the
+// target label isn't defined, even if the branch isn't taken.
+func (s *Simulator) execBranch(stmt ast.Statement, target *ast.Identifier, taken bool) error {
+	addr, ok := s.symbols[target.Name]
+	if !ok {
+		return &SimulatorError{fmt.Sprintf("undefined label %q", target.Name), stmt.Pos()}
+	}
+	if !taken {
+		s.incPC()
+		return nil
+	}
+	s.setRegister("pc", Register(addr))
+	return nil
+}
+
+// execCallStatement executes a call command on the simulator. The address of
+// the call is stored in %r15 and the program counter is set to the address of
+// the called subroutine.
+func (s *Simulator) execCallStatement(stmt *ast.CallStatement) error {
+	addr, ok := s.symbols[stmt.Target.Name]
+	if !ok {
+		return &SimulatorError{fmt.Sprintf("undefined label %q", stmt.Target.Name), stmt.Pos()}
+	}
+	s.setRegister("r15", s.registers["pc"])
+	s.setRegister("pc", Register(addr))
+	return nil
+}
+
+// execJumpAndLinkStatement executes a jmpl command on the simulator. The
+// address of the jmpl is stored in the link register and the program counter
+// is set to the computed return address. The return address is computed
+// before the link register is written, so both may refer to the same register.
+func (s *Simulator) execJumpAndLinkStatement(stmt *ast.JumpAndLinkStatement) error {
+	addr, err := s.address(stmt, stmt.ReturnAddress)
+	if err != nil {
+		return err
+	}
+	if addr%4 != 0 {
+		return &SimulatorError{fmt.Sprintf("unaligned jump to 0x%08x", uint32(addr)), stmt.Pos()}
+	}
+	if err = s.writable(stmt, stmt.FromAddress); err != nil {
+		return err
+	}
+	s.setRegister(strings.TrimPrefix(stmt.FromAddress.Name, "%"), s.registers["pc"])
+	s.setRegister("pc", Register(addr))
+	return nil
+}
+
 // execLabelStatement executes a label command on the simulator.
+// A labeled statement is executed like the statement itself. Labeled data
+// can't be executed.
 func (s *Simulator) execLabelStatement(stmt *ast.LabelStatement) error {
+	if ref, valid := stmt.Reference.(ast.Statement); valid && data(stmt) == nil {
+		return s.exec(ref)
+	}
+	return &SimulatorError{fmt.Sprintf("can't execute data %q", stmt.Ident), stmt.Pos()}
+}
+
+// effectiveAddress computes the memory address a memory location of the given
+// statement refers to. Memory accesses must be aligned on a word boundary, so
+// an error is returned if the address isn't a multiple of four.
+func (s *Simulator) effectiveAddress(stmt ast.Statement, memLoc ast.MemoryLocation) (int32, error) {
+	var addr int32
+	switch loc := memLoc.(type) {
+	case *ast.Register:
+		val, err := s.register(stmt, loc)
+		if err != nil {
+			return 0, err
+		}
+		addr = int32(val)
+	case *ast.Expression:
+		var err error
+		if addr, err = s.address(stmt, loc); err != nil {
+			return 0, err
+		}
+	}
+
+	if addr%4 != 0 {
+		return 0, &SimulatorError{fmt.Sprintf("unaligned memory access at 0x%08x", uint32(addr)), stmt.Pos()}
+	}
+	return addr, nil
+}
+
+// address computes the address an expression of the given statement refers
+// to. Register-relative addresses are evaluated against the current content of
+// the base register.
+func (s *Simulator) address(stmt ast.Statement, exp *ast.Expression) (int32, error) {
+	res, err := exp.Resolve(s.symbols)
+	if err != nil {
+		return 0, &SimulatorError{err.Error(), stmt.Pos()}
+	}
+	if res.Absolute() {
+		return res.Offset, nil
+	}
+	val, err := s.register(stmt, res.Register)
+	if err != nil {
+		return 0, err
+	}
+	return res.Offset + int32(val), nil
+}
+
+// operands returns the values of the source register and the operand of an
+// arithmetic or logic statement. The operand is either a register or an
+// immediate integer.
+func (s *Simulator) operands(stmt ast.Statement, src *ast.Register, op ast.Operand) (int32, int32, error) {
+	a, err := s.register(stmt, src)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch op := op.(type) {
+	case *ast.Register:
+		b, err := s.register(stmt, op)
+		if err != nil {
+			return 0, 0, err
+		}
+		return int32(a), int32(b), nil
+	case *ast.Integer:
+		return int32(a), op.Value, nil
+	case *ast.Identifier:
+		b, ok := s.consts[op.Name]
+		if !ok {
+			return 0, 0, &SimulatorError{fmt.Sprintf("undefined constant %q", op.Name), stmt.Pos()}
+		}
+		return int32(a), b, nil
+	}
+	return 0, 0, &SimulatorError{fmt.Sprintf("invalid operand %q", op), stmt.Pos()}
+}
+
+// register returns the value of the register referenced by the given
+// statement. %r0 is hardwired to zero. An error is returned if the register
+// doesn't exist.
+func (s *Simulator) register(stmt ast.Statement, reg *ast.Register) (Register, error) {
+	name := strings.TrimPrefix(reg.Name, "%")
+	val, ok := s.registers[name]
+	if !ok {
+		return 0, &SimulatorError{fmt.Sprintf("unknown register %q", reg.Name), stmt.Pos()}
+	}
+	if name == "r0" {
+		return 0, nil
+	}
+	return val, nil
+}
+
+// writable returns an error if the register doesn't exist or can't be written
+// by a statement. Only general purpose registers can be written, the program
+// counter is changed by control transfers only.
+func (s *Simulator) writable(stmt ast.Statement, reg *ast.Register) error {
+	if _, err := s.register(stmt, reg); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(reg.Name, "%r") {
+		return &SimulatorError{fmt.Sprintf("register %q is read-only", reg.Name), stmt.Pos()}
+	}
 	return nil
 }
 
 // incPC increments the simulators program counter.
 func (s *Simulator) incPC() {
-	s.registers["pc"] += Register(4)
+	s.setRegister("pc", s.registers["pc"]+Register(4))
+}
+
+// setRegister sets the value of a register and records the inverse operation
+// in the step log. Writes to %r0 are discarded because it always reads as zero.
+func (s *Simulator) setRegister(name string, val Register) {
+	if name == "r0" {
+		return
+	}
+	if len(s.log) > 0 {
+		last := s.log[len(s.log)-1]
+		if last.snapshot == nil {
+			last.changes = append(last.changes, change{register: name, old: s.registers[name]})
+		}
+	}
+	s.registers[name] = val
+}
+
+// checkpoint stores a snapshot of the complete state in the step log. It must
+// be called by operations which can't be reversed by inverse operations, before
+// they modify the state.
+func (s *Simulator) checkpoint() {
+	if len(s.log) == 0 {
+		return
+	}
+	last := s.log[len(s.log)-1]
+	if last.snapshot != nil {
+		return
+	}
+
+	// Changes made before the checkpoint are part of the snapshot as well, so
+	// they must be reversed first.
+	snap := &snapshot{
+		registers: make(map[string]Register, len(s.registers)),
+		memory:    make(Memory, len(s.memory)),
+	}
+	for r, val := range s.registers {
+		snap.registers[r] = val
+	}
+	for addr, val := range s.memory {
+		snap.memory[addr] = val
+	}
+	for i := len(last.changes) - 1; i >= 0; i-- {
+		last.changes[i].revert(snap.registers, snap.memory)
+	}
+	last.snapshot = snap
+	last.changes = nil
+}
+
+// revert applies the inverse operation to the given registers and memory.
+func (c change) revert(registers map[string]Register, memory Memory) {
+	switch {
+	case c.register != "":
+		registers[c.register] = c.old
+	case c.existed:
+		memory[c.address] = int32(c.old)
+	default:
+		delete(memory, c.address)
+	}
+}
+
+// SimulatorError represents an error that occurred during execution.
+type SimulatorError struct {
+	Message string
+	Pos     token.Pos
+}
+
+// Error returns the string representation of the error. It implements the error
+// interface.
+func (e SimulatorError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
 }
@@ -0,0 +1,875 @@
+package simulator
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/parser"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// TestSimulator_StepBack verifies that stepping back restores the state before
+// the execution of a statement.
+func TestSimulator_StepBack(t *testing.T) {
+	s := New(nil)
+	stmts := []string{
+		"ld %r1, %r2",
+		"st %r2, %r3",
+		"ld %r3, %r4",
+	}
+	for _, str := range stmts {
+		ok(t, s.Exec(parseStatement(t, str)))
+	}
+	equals(t, Register(12), s.registers["pc"])
+
+	// Step back to the state after the first statement.
+	ok(t, s.StepBack())
+	ok(t, s.StepBack())
+	equals(t, Register(4), s.registers["pc"])
+
+	// Step forward again.
+	ok(t, s.Exec(parseStatement(t, "ld %r1, %r2")))
+	equals(t, Register(8), s.registers["pc"])
+
+	// Step back to the initial state.
+	ok(t, s.StepBack())
+	ok(t, s.StepBack())
+	equals(t, Register(0), s.registers["pc"])
+
+	// Nothing left to step back.
+	assert(t, s.StepBack() != nil, "expected error but got nil")
+}
+
+// TestSimulator_StepBackSnapshot verifies that irreversible operations are
+// reversed by restoring a snapshot.
+func TestSimulator_StepBackSnapshot(t *testing.T) {
+	s := New(nil)
+	s.registers["r1"] = 5
+
+	s.log = append(s.log, &step{})
+	s.setRegister("r1", 6)
+	s.checkpoint()
+	s.setRegister("r1", 7)
+	s.setRegister("r2", 8)
+
+	ok(t, s.StepBack())
+	equals(t, Register(5), s.registers["r1"])
+	equals(t, Register(0), s.registers["r2"])
+}
+
+// TestSimulator_ExecError verifies that a failed execution doesn't add an
+// entry to the step log.
+func TestSimulator_ExecError(t *testing.T) {
+	s := New(nil)
+	ok(t, s.Exec(parseStatement(t, "ld %r1, %r2")))
+	assert(t, s.Exec(parseStatement(t, "ba x")) != nil, "expected error but got nil")
+	equals(t, 1, len(s.log))
+}
+
+// TestSimulator_Add verifies the two's complement arithmetic of add and addcc
+// and the condition codes set by addcc.
+func TestSimulator_Add(t *testing.T) {
+	tests := []struct {
+		stmt   string
+		r1, r2 int32
+		r3     int32
+		psr    Register
+	}{
+		{stmt: "add %r1, %r2, %r3", r1: 1, r2: 2, r3: 3},
+		{stmt: "add %r1, 4095, %r3", r1: 1, r3: 4096},
+		{stmt: "add %r1, %r2, %r3", r1: 0x7fffffff, r2: 1, r3: -0x80000000},
+		{stmt: "addcc %r1, %r2, %r3", r1: 1, r2: 2, r3: 3},
+		{stmt: "addcc %r1, 1, %r3", r1: 0x7fffffff, r3: -0x80000000, psr: psrN | psrV},
+		{stmt: "addcc %r1, %r2, %r3", r1: -1, r2: 1, r3: 0, psr: psrZ | psrC},
+		{stmt: "addcc %r1, %r2, %r3", r1: -1, r2: -1, r3: -2, psr: psrN | psrC},
+		{stmt: "addcc %r1, %r2, %r3", r1: -0x80000000, r2: -1, r3: 0x7fffffff, psr: psrV | psrC},
+		{stmt: "addcc %r1, %r2, %r3", r1: -0x80000000, r2: -0x80000000, r3: 0, psr: psrZ | psrV | psrC},
+		{stmt: "addcc %r1, %r2, %r0", r1: 2, r2: -2, r3: 0, psr: psrZ | psrC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			s := New(nil)
+			s.registers["r1"] = Register(tt.r1)
+			s.registers["r2"] = Register(tt.r2)
+			ok(t, s.Exec(parseStatement(t, tt.stmt)))
+			equals(t, Register(tt.r3), s.registers["r3"])
+			equals(t, tt.psr, s.registers["psr"])
+			equals(t, Register(4), s.registers["pc"])
+
+			// Stepping back restores the condition codes.
+			ok(t, s.StepBack())
+			equals(t, Register(0), s.registers["psr"])
+		})
+	}
+
+	// addcc clears condition codes which don't apply anymore.
+	s := New(nil)
+	s.registers["r1"] = -1
+	ok(t, s.Exec(parseStatement(t, "addcc %r1, 1, %r1")))
+	equals(t, psrZ|psrC, s.registers["psr"])
+	ok(t, s.Exec(parseStatement(t, "addcc %r1, 1, %r1")))
+	equals(t, Register(0), s.registers["psr"])
+}
+
+// TestSimulator_ALU verifies the results and condition codes of the
+// arithmetic and logic statements.
+func TestSimulator_ALU(t *testing.T) {
+	tests := []struct {
+		stmt   string
+		r1, r2 int32
+		r3     int32
+		flags  Flags
+	}{
+		{stmt: "sub %r1, %r2, %r3", r1: 5, r2: 7, r3: -2},
+		{stmt: "subcc %r1, %r1, %r3", r1: 5, r3: 0, flags: Flags{Z: true}},
+		{stmt: "subcc %r1, %r2, %r3", r1: 5, r2: 7, r3: -2, flags: Flags{N: true, C: true}},
+		{stmt: "subcc %r1, 1, %r3", r1: -0x80000000, r3: 0x7fffffff, flags: Flags{V: true}},
+		{stmt: "subcc %r1, %r2, %r3", r1: 0x7fffffff, r2: -1, r3: -0x80000000, flags: Flags{N: true, V: true, C: true}},
+		{stmt: "and %r1, %r2, %r3", r1: 0xc, r2: 0xa, r3: 0x8},
+		{stmt: "andcc %r1, 3, %r3", r1: 0xc, r3: 0, flags: Flags{Z: true}},
+		{stmt: "or %r1, %r2, %r3", r1: 0xc, r2: 0xa, r3: 0xe},
+		{stmt: "orcc %r1, %r2, %r3", r1: -0x80000000, r2: 1, r3: -0x7fffffff, flags: Flags{N: true}},
+		{stmt: "orn %r1, %r2, %r3", r1: 0, r2: -1, r3: 0},
+		{stmt: "orncc %r1, %r2, %r3", r1: 0, r2: 0, r3: -1, flags: Flags{N: true}},
+		{stmt: "xor %r1, %r2, %r3", r1: 0xc, r2: 0xa, r3: 0x6},
+		{stmt: "xorcc %r1, %r1, %r3", r1: 0xc, r3: 0, flags: Flags{Z: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			s := New(nil)
+			s.registers["r1"] = Register(tt.r1)
+			s.registers["r2"] = Register(tt.r2)
+			ok(t, s.Exec(parseStatement(t, tt.stmt)))
+			equals(t, Register(tt.r3), s.registers["r3"])
+			equals(t, tt.flags, s.Flags())
+		})
+	}
+
+	// Logic statements clear overflow and carry.
+	s := New(nil)
+	s.registers["r1"] = -1
+	ok(t, s.Exec(parseStatement(t, "addcc %r1, %r1, %r2")))
+	equals(t, Flags{N: true, C: true}, s.Flags())
+	ok(t, s.Exec(parseStatement(t, "andcc %r1, 1, %r2")))
+	equals(t, Flags{}, s.Flags())
+}
+
+// TestSimulator_Shift verifies the shift statements and that the shift count
+// is taken modulo 32.
+func TestSimulator_Shift(t *testing.T) {
+	tests := []struct {
+		stmt   string
+		r1, r2 int32
+		r3     int32
+	}{
+		{stmt: "sll %r1, 4, %r3", r1: 3, r3: 48},
+		{stmt: "sll %r1, %r2, %r3", r1: 1, r2: 31, r3: -0x80000000},
+		{stmt: "sll %r1, %r2, %r3", r1: 3, r2: 33, r3: 6},
+		{stmt: "sll %r1, %r2, %r3", r1: 3, r2: -1, r3: -0x80000000},
+		{stmt: "sra %r1, 4, %r3", r1: 48, r3: 3},
+		{stmt: "sra %r1, 1, %r3", r1: -7, r3: -4},
+		{stmt: "sra %r1, %r2, %r3", r1: -0x80000000, r2: 31, r3: -1},
+		{stmt: "sra %r1, %r2, %r3", r1: -16, r2: 34, r3: -4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			s := New(nil)
+			s.registers["r1"] = Register(tt.r1)
+			s.registers["r2"] = Register(tt.r2)
+			ok(t, s.Exec(parseStatement(t, tt.stmt)))
+			equals(t, Register(tt.r3), s.registers["r3"])
+			equals(t, Flags{}, s.Flags())
+		})
+	}
+}
+
+// TestSimulator_Flags verifies that comparing a register with itself sets only
+// the zero flag.
+func TestSimulator_Flags(t *testing.T) {
+	s := New(nil)
+	s.registers["r1"] = 42
+	ok(t, s.Exec(parseStatement(t, "subcc %r1, %r1, %r2")))
+	equals(t, Flags{Z: true}, s.Flags())
+	equals(t, "N=0 Z=1 V=0 C=0", s.Flags().String())
+	assert(t, strings.Contains(s.State(), "flags:\tN=0 Z=1 V=0 C=0\n"), "flags missing in state")
+
+	s.Reset()
+	equals(t, Flags{}, s.Flags())
+}
+
+// TestSimulator_ResetTarget verifies that the targets of the reset command
+// reset only the selected part of the state.
+func TestSimulator_ResetTarget(t *testing.T) {
+	s := New(nil)
+	ok(t, s.WriteWord(2048, 7))
+	ok(t, s.SetReg("%r4", 4))
+	ok(t, s.SetReg("%r5", 5))
+	s.SetFlags(Flags{N: true, C: true})
+	equals(t, Flags{N: true, C: true}, s.Flags())
+
+	ok(t, s.ResetTarget("%r5"))
+	r4, err := s.Reg("%r4")
+	ok(t, err)
+	equals(t, int32(4), r4)
+	r5, err := s.Reg("%r5")
+	ok(t, err)
+	equals(t, int32(0), r5)
+	equals(t, Flags{N: true, C: true}, s.Flags())
+
+	ok(t, s.ResetTarget("flags"))
+	equals(t, Flags{}, s.Flags())
+	r4, err = s.Reg("%r4")
+	ok(t, err)
+	equals(t, int32(4), r4)
+
+	ok(t, s.ResetTarget("%r0"))
+	if err := s.ResetTarget("%r99"); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+
+	ok(t, s.ResetTarget(""))
+	r4, err = s.Reg("%r4")
+	ok(t, err)
+	equals(t, int32(0), r4)
+	word, err := s.ReadWord(2048)
+	ok(t, err)
+	equals(t, int32(0), word)
+}
+
+// TestSimulator_Branch verifies that branches are taken according to the
+// condition codes.
+func TestSimulator_Branch(t *testing.T) {
+	tests := []struct {
+		branch string
+		flags  Register
+		taken  bool
+	}{
+		{"ba x", 0, true},
+		{"be x", psrZ, true},
+		{"be x", 0, false},
+		{"bne x", 0, true},
+		{"bne x", psrZ, false},
+		{"bneg x", psrN, true},
+		{"bneg x", psrZ, false},
+		{"bpos x", psrZ, true},
+		{"bpos x", psrN, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			s := New(nil)
+			ok(t, s.Load(parseProgram(t, tt.branch+"\nld %r1, %r2\nx: ld %r2, %r3")))
+			s.registers["psr"] = tt.flags
+			reason, err := s.Step()
+			ok(t, err)
+			equals(t, Stepped, reason)
+			if tt.taken {
+				equals(t, Register(8), s.registers["pc"])
+			} else {
+				equals(t, Register(4), s.registers["pc"])
+			}
+		})
+	}
+
+	// Branching to an undefined label fails.
+	s := New(nil)
+	err := s.Exec(parseStatement(t, "be x"))
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, `1:1: undefined label "x"`, err.Error())
+}
+
+// TestSimulator_Loop verifies that a loop controlled by subcc and bne runs the
+// expected number of iterations.
+func TestSimulator_Loop(t *testing.T) {
+	src := `.begin
+.org 2048
+ld [n], %r1
+loop: add %r2, 2, %r2
+subcc %r1, 1, %r1
+bne loop
+done: st %r2, [n]
+.org 3000
+n: 5
+.end`
+
+	s := New(nil)
+	ok(t, s.Load(parseProgram(t, src)))
+	reason, err := s.RunUntilLabel("done")
+	ok(t, err)
+	equals(t, ReachedLabel, reason)
+	equals(t, Register(0), s.registers["r1"])
+	equals(t, Register(10), s.registers["r2"])
+	equals(t, Flags{Z: true}, s.Flags())
+
+	// Stepping back into the loop restores the branch.
+	ok(t, s.StepBack())
+	equals(t, Register(2060), s.registers["pc"])
+}
+
+// TestSimulator_Call verifies that control returns correctly through a
+// two-level chain of subroutine calls.
+func TestSimulator_Call(t *testing.T) {
+	src := `.begin
+.org 2048
+        call outer
+        add %r2, 1, %r2
+        ba done
+outer:  add %r15, %r0, %r16
+        call inner
+        add %r1, 10, %r1
+        add %r16, %r0, %r15
+        jmpl %r15+4, %r0
+inner:  add %r1, 1, %r1
+        jmpl %r15+4, %r0
+done:   add %r2, 1, %r2
+.end`
+
+	s := New(nil)
+	reason, err := s.Run(parseProgram(t, src))
+	ok(t, err)
+	equals(t, Finished, reason)
+	equals(t, Register(11), s.registers["r1"])
+	equals(t, Register(2), s.registers["r2"])
+	equals(t, Register(2048), s.registers["r15"])
+	equals(t, Register(2092), s.registers["pc"])
+}
+
+// TestSimulator_JumpAndLink verifies that jmpl stores its address in the link
+// register and jumps to the computed return address.
+func TestSimulator_JumpAndLink(t *testing.T) {
+	s := New(nil)
+	s.registers["pc"] = 2048
+	s.registers["r1"] = 4000
+
+	ok(t, s.Exec(parseStatement(t, "jmpl %r1+8, %r1")))
+	equals(t, Register(4008), s.registers["pc"])
+	equals(t, Register(2048), s.registers["r1"])
+
+	ok(t, s.StepBack())
+	equals(t, Register(2048), s.registers["pc"])
+	equals(t, Register(4000), s.registers["r1"])
+
+	err := s.Exec(parseStatement(t, "jmpl %r1+2, %r0"))
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, "1:1: unaligned jump to 0x00000fa2", err.Error())
+}
+
+// TestSimulator_Run verifies that a program is executed until it runs into its
+// data. The program is the arraySum sample program of the parser tests with the
+// subroutines inlined.
+func TestSimulator_Run(t *testing.T) {
+	src := `.begin
+        .org 2048
+        ld [length], %r1
+        ld [start], %r2
+        ld [zero], %r3
+
+loop:   ld %r2, %r4
+        addcc %r2, 4, %r2
+        addcc %r3, %r4, %r3
+        subcc %r1, 1, %r1
+        be done
+        ba loop
+
+done:   ld [zero], %r1
+        ld [zero], %r2
+        ld [zero], %r4
+
+start:  3000
+length: 4
+zero:   0
+
+        .org 3000
+a0:     10
+a1:     20
+a2:     -0xa
+a3:     0xa
+        .end`
+
+	s := New(nil)
+	reason, err := s.Run(parseProgram(t, src))
+	ok(t, err)
+	equals(t, Finished, reason)
+	equals(t, Register(30), s.registers["r3"])
+	equals(t, Register(0), s.registers["r1"])
+	equals(t, Register(0), s.registers["r2"])
+	equals(t, Register(0), s.registers["r4"])
+	equals(t, s.symbols["start"], int32(s.registers["pc"]))
+}
+
+// TestSimulator_RunCycleLimit verifies that an infinite loop is stopped by the
+// step limit.
+func TestSimulator_RunCycleLimit(t *testing.T) {
+	s := New(&Options{StepLimit: 100})
+	reason, err := s.Run(parseProgram(t, "loop: add %r1, 1, %r1\nba loop"))
+	ok(t, err)
+	equals(t, StepLimit, reason)
+	equals(t, Register(50), s.registers["r1"])
+}
+
+// TestSimulator_LoadOverlap verifies that programs whose statements overlap in
+// memory aren't loaded or run.
+func TestSimulator_LoadOverlap(t *testing.T) {
+	src := ".org 2048\nld [x], %r1\nadd %r1, 1, %r1\n.org 2052\nx: 25"
+	want := "5:1: address 0x00000804 already occupied by statement at 3:1"
+
+	s := New(nil)
+	err := s.Load(parseProgram(t, src))
+	assert(t, err != nil, "expected an error")
+	equals(t, want, err.Error())
+
+	reason, err := s.Run(parseProgram(t, src))
+	assert(t, err != nil, "expected an error")
+	equals(t, Failed, reason)
+	equals(t, want, err.Error())
+}
+
+// TestSimulator_R0 verifies that %r0 always reads as zero and discards writes.
+func TestSimulator_R0(t *testing.T) {
+	s := New(nil)
+	ok(t, s.Exec(parseStatement(t, "add %r0, 5, %r0")))
+	val, err := s.register(nil, &ast.Register{Name: "%r0"})
+	ok(t, err)
+	equals(t, Register(0), val)
+	ok(t, s.Exec(parseStatement(t, "add %r0, 7, %r1")))
+	equals(t, Register(7), s.registers["r1"])
+
+	// Even if %r0 got corrupted, it reads as zero.
+	s.registers["r0"] = 42
+	ok(t, s.Exec(parseStatement(t, "add %r0, %r0, %r2")))
+	equals(t, Register(0), s.registers["r2"])
+}
+
+// TestSimulator_Alignment verifies that unaligned memory accesses are rejected.
+func TestSimulator_Alignment(t *testing.T) {
+	tests := []struct {
+		stmt string
+		err  string
+	}{
+		{"ld [%r1+1], %r2", "1:1: unaligned memory access at 0x00000001"},
+		{"ld [%r1-2], %r2", "1:1: unaligned memory access at 0xfffffffe"},
+		{"st %r2, [%r1+3]", "1:1: unaligned memory access at 0x00000003"},
+		{"ld [%r1+4], %r2", ""},
+		{"st %r2, [%r1]", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			s := New(nil)
+			err := s.Exec(parseStatement(t, tt.stmt))
+			if tt.err == "" {
+				ok(t, err)
+				equals(t, Register(4), s.registers["pc"])
+				return
+			}
+			assert(t, err != nil, "expected error but got nil")
+			equals(t, tt.err, err.Error())
+			equals(t, Register(0), s.registers["pc"])
+		})
+	}
+}
+
+// TestSimulator_Memory verifies that words stored to memory can be loaded again
+// and that stepping back restores the previous memory content.
+func TestSimulator_Memory(t *testing.T) {
+	s := New(nil)
+	s.registers["r1"] = 42
+	s.registers["r2"] = 2048
+
+	ok(t, s.Exec(parseStatement(t, "st %r1, [%r2+4]")))
+	ok(t, s.Exec(parseStatement(t, "ld [%r2+4], %r3")))
+	equals(t, Register(42), s.registers["r3"])
+
+	ok(t, s.StepBack())
+	ok(t, s.StepBack())
+	word, err := s.ReadWord(2052)
+	ok(t, err)
+	equals(t, int32(0), word)
+	equals(t, 0, len(s.memory))
+}
+
+// TestSimulator_MemoryLabel verifies that a word stored at the address bound to
+// a label can be loaded again.
+func TestSimulator_MemoryLabel(t *testing.T) {
+	src := `.begin
+.org 2048
+st %r1, [x]
+ld [x], %r2
+st %r1, [x+2]
+.org 3000
+x: 25
+.end`
+
+	s := New(nil)
+	ok(t, s.Load(parseProgram(t, src)))
+	equals(t, int32(25), s.memory[3000])
+	s.registers["r1"] = -7
+
+	_, err := s.Step()
+	ok(t, err)
+	word, err := s.ReadWord(3000)
+	ok(t, err)
+	equals(t, int32(-7), word)
+
+	_, err = s.Step()
+	ok(t, err)
+	equals(t, Register(-7), s.registers["r2"])
+
+	reason, err := s.Step()
+	equals(t, Failed, reason)
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, "5:1: unaligned memory access at 0x00000bba", err.Error())
+}
+
+// TestSimulator_Reg verifies reading and setting registers by name.
+func TestSimulator_Reg(t *testing.T) {
+	s := New(nil)
+	ok(t, s.SetReg("r1", 5))
+	ok(t, s.SetReg("%r2", -3))
+	ok(t, s.SetReg("pc", 2048))
+	ok(t, s.Exec(parseStatement(t, "add %r1, %r2, %r3")))
+
+	v, err := s.Reg("%r3")
+	ok(t, err)
+	equals(t, int32(2), v)
+	v, err = s.Reg("pc")
+	ok(t, err)
+	equals(t, int32(2052), v)
+	v, err = s.Reg("r0")
+	ok(t, err)
+	equals(t, int32(0), v)
+
+	err = s.SetReg("r0", 1)
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, `register "r0" is read-only`, err.Error())
+	_, err = s.Reg("%r99")
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, `unknown register "r99"`, err.Error())
+	err = s.SetReg("%r99", 1)
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, `unknown register "r99"`, err.Error())
+}
+
+// TestSimulator_Equ verifies that constants used as operands are substituted by
+// their value.
+func TestSimulator_Equ(t *testing.T) {
+	s := New(nil)
+	_, err := s.Run(parseProgram(t, ".equ SIZE, 16\nadd %r0, 10, %r1\nadd %r1, SIZE, %r2"))
+	ok(t, err)
+	equals(t, Register(26), s.registers["r2"])
+}
+
+// TestSimulator_Word verifies that the values of word directives are loaded
+// into memory and can't be executed.
+func TestSimulator_Word(t *testing.T) {
+	s := New(nil)
+	_, err := s.Run(parseProgram(t, "ld [x+8], %r1\nld [y], %r2\nx: .word 1, 2, 3\ny: 4"))
+	ok(t, err)
+	equals(t, Register(3), s.registers["r1"])
+	equals(t, Register(4), s.registers["r2"])
+
+	err = s.Exec(parseStatement(t, "x: .word 1"))
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, `1:1: can't execute data "x"`, err.Error())
+}
+
+// TestSimulator_Eval verifies the evaluation of expressions against the
+// registers and memory.
+func TestSimulator_Eval(t *testing.T) {
+	s := New(nil)
+	ok(t, s.Load(parseProgram(t, "ld [x], %r2\nx: 25")))
+	ok(t, s.SetReg("r1", 10))
+
+	tests := []struct {
+		expr string
+		val  int32
+		err  string
+	}{
+		{expr: "%r1 + 4", val: 14},
+		{expr: "%r1-2", val: 8},
+		{expr: "[x]", val: 25},
+		{expr: "x", val: 4},
+		{expr: "[%r0 + 4]", val: 25},
+		{expr: "[%r1]", err: "unaligned memory access at 0x0000000a"},
+		{expr: "y + 4", err: `undefined identifier "y"`},
+		{expr: "%r1 + 4 4", err: `1:9: found INTEGER "4", expected EOF`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			val, err := s.Eval(tt.expr)
+			if tt.err != "" {
+				assert(t, err != nil, "expected error but got nil")
+				equals(t, tt.err, err.Error())
+				return
+			}
+			ok(t, err)
+			equals(t, tt.val, val)
+		})
+	}
+}
+
+// TestSimulator_Snapshot verifies the state returned after executing a few
+// statements.
+func TestSimulator_Snapshot(t *testing.T) {
+	s := New(nil)
+	ok(t, s.Load(parseProgram(t, "add %r0, 5, %r1\nsubcc %r1, 5, %r2\nsll %r1, 2, %r31")))
+	for i := 0; i < 3; i++ {
+		_, err := s.Step()
+		ok(t, err)
+	}
+
+	want := State{Registers: make([]int32, 32), PC: 12, PSR: int32(psrZ), Flags: Flags{Z: true}}
+	want.Registers[1] = 5
+	want.Registers[31] = 20
+	got := s.Snapshot()
+	equals(t, want, got)
+
+	// The snapshot is a copy which isn't affected by later changes.
+	ok(t, s.StepBack())
+	equals(t, int32(20), got.Registers[31])
+	equals(t, int32(0), s.Snapshot().Registers[31])
+	assert(t, strings.Contains(got.String(), "r31:\t0x00000014\npc:\t0x0000000C\n"), "registers missing in state")
+}
+
+// TestSimulator_PC verifies that reading %pc returns the address of the
+// executing statement and that it can't be written.
+func TestSimulator_PC(t *testing.T) {
+	s := New(nil)
+	ok(t, s.Load(parseProgram(t, ".org 2048\nadd %r0, %r0, %r0\nadd %pc, 0, %r1\nld [%pc+4], %r2\nx: 25")))
+	for i := 0; i < 3; i++ {
+		_, err := s.Step()
+		ok(t, err)
+	}
+	equals(t, Register(2052), s.registers["r1"])
+	equals(t, Register(25), s.registers["r2"])
+
+	err := s.Exec(parseStatement(t, "add %r0, 4, %pc"))
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, `1:1: register "%pc" is read-only`, err.Error())
+	equals(t, Register(2060), s.registers["pc"])
+}
+
+// TestSimulator_Pseudo verifies that pseudo instructions are executed as the
+// instructions they stand for.
+func TestSimulator_Pseudo(t *testing.T) {
+	s := New(nil)
+	ok(t, s.SetReg("r1", 5))
+	ok(t, s.SetReg("r15", 2048))
+
+	ok(t, s.Exec(parseStatement(t, "cmp %r1, 5")))
+	equals(t, Flags{Z: true}, s.Flags())
+	equals(t, Register(5), s.registers["r1"])
+	ok(t, s.Exec(parseStatement(t, "nop")))
+	equals(t, Register(8), s.registers["pc"])
+	ok(t, s.Exec(parseStatement(t, "ret")))
+	equals(t, Register(2052), s.registers["pc"])
+
+	ok(t, s.Exec(parseStatement(t, "mov %r1, %r3")))
+	equals(t, Register(5), s.registers["r3"])
+	ok(t, s.Exec(parseStatement(t, "clr %r3")))
+	equals(t, Register(0), s.registers["r3"])
+}
+
+// TestSimulator_NumRegisters verifies that references to registers beyond the
+// configured register file are rejected.
+func TestSimulator_NumRegisters(t *testing.T) {
+	s := New(&Options{NumRegisters: 16})
+	ok(t, s.Exec(parseStatement(t, "add %r0, 5, %r15")))
+
+	err := s.Exec(parseStatement(t, "add %r1, %r2, %r20"))
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, `1:1: unknown register "%r20"`, err.Error())
+	_, err = s.Reg("%r20")
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, `unknown register "r20"`, err.Error())
+	equals(t, 16, len(s.Snapshot().Registers))
+	equals(t, int32(5), s.Snapshot().Registers[15])
+}
+
+// TestSimulator_IO verifies that storing a word to the I/O address writes a
+// character to the output.
+func TestSimulator_IO(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(&Options{IOAddress: 0x100, Output: &buf})
+	s.registers["r1"] = 'H'
+	s.registers["r2"] = 0x100
+
+	ok(t, s.Exec(parseStatement(t, "st %r1, [%r2]")))
+	s.registers["r1"] = 'i'
+	ok(t, s.Exec(parseStatement(t, "st %r1, %r2")))
+	equals(t, "Hi", buf.String())
+	equals(t, 0, len(s.memory))
+
+	// Stepping back restores the snapshot taken before the output.
+	ok(t, s.StepBack())
+	equals(t, Register(4), s.registers["pc"])
+}
+
+// TestSimulator_RunUntilLabel verifies that a loaded program is executed until
+// the program counter reaches a label.
+func TestSimulator_RunUntilLabel(t *testing.T) {
+	src := `.begin
+.org 2048
+ld [x], %r1
+st %r1, [y]
+done: ld [y], %r2
+.org 3000
+x: 25
+y: 0
+.end`
+
+	s := New(nil)
+	ok(t, s.Load(parseProgram(t, src)))
+	equals(t, Register(2048), s.registers["pc"])
+
+	reason, err := s.RunUntilLabel("done")
+	ok(t, err)
+	equals(t, ReachedLabel, reason)
+	equals(t, Register(2056), s.registers["pc"])
+	equals(t, Register(25), s.registers["r1"])
+	equals(t, Register(0), s.registers["r2"])
+	equals(t, int32(25), s.memory[3004])
+
+	// Reaching the label again requires to pass it, which isn't possible
+	// without branches. The program finishes instead.
+	_, err = s.Step()
+	ok(t, err)
+	reason, err = s.RunUntilLabel("done")
+	ok(t, err)
+	equals(t, Finished, reason)
+	reason, err = s.RunUntilLabel("undefined")
+	assert(t, err != nil, "expected error but got nil")
+	equals(t, Failed, reason)
+}
+
+// TestSimulator_Coverage verifies that the execution counts of the array sum
+// program match its control flow. The loop runs once per array element and
+// leaves with be on the last one.
+func TestSimulator_Coverage(t *testing.T) {
+	prog, err := parser.ParseFile(filepath.Join("..", "testdata", "array_sum.arc"))
+	ok(t, err)
+	s := New(nil)
+	ok(t, s.Load(prog))
+	equals(t, map[token.Pos]int{}, s.Coverage())
+
+	reason, err := s.RunUntilLabel("done")
+	ok(t, err)
+	equals(t, ReachedLabel, reason)
+
+	pos := func(line, char int) token.Pos {
+		return token.Pos{Filename: prog.Filename.Filename, Line: line, Char: char}
+	}
+	cov := s.Coverage()
+	equals(t, 1, cov[pos(17, 9)])
+	equals(t, 1, cov[pos(20, 1)])
+	equals(t, 4, cov[pos(25, 1)])
+	equals(t, 4, cov[pos(28, 9)])
+	equals(t, 4, cov[pos(29, 9)])
+	equals(t, 3, cov[pos(30, 9)])
+	_, executed := cov[pos(32, 1)]
+	equals(t, false, executed)
+
+	// Loading the program again resets the counts.
+	ok(t, s.Load(prog))
+	equals(t, map[token.Pos]int{}, s.Coverage())
+}
+
+// TestSimulator_RunUntilLabelStepLimit verifies that the step limit stops a
+// run which doesn't reach the label.
+func TestSimulator_RunUntilLabelStepLimit(t *testing.T) {
+	s := New(&Options{StepLimit: 1})
+	ok(t, s.Load(parseProgram(t, "ld [x], %r1\nld [x], %r2\ndone: ld [x], %r3\nx: 1")))
+	reason, err := s.RunUntilLabel("done")
+	ok(t, err)
+	equals(t, StepLimit, reason)
+	equals(t, Register(1), s.registers["r1"])
+	equals(t, Register(0), s.registers["r2"])
+}
+
+// TestSimulator_StopReason verifies the reason returned for every way a run
+// can stop.
+func TestSimulator_StopReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		label  string
+		reason StopReason
+		err    string
+	}{
+		{"finished at data", "add %r1, 1, %r1\nx: 1", "", Finished, ""},
+		{"finished past end", "add %r1, 1, %r1", "", Finished, ""},
+		{"reached label", "add %r1, 1, %r1\nx: add %r1, 1, %r1", "x", ReachedLabel, ""},
+		{"step limit", "x: add %r1, 1, %r1\nba x", "", StepLimit, ""},
+		{"failed", "add %r0, 3, %r1\nld %r1, %r2", "", Failed, "2:1: unaligned memory access at 0x00000003"},
+		{"undefined label", "add %r1, 1, %r1", "x", Failed, `undefined label "x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(&Options{StepLimit: 10})
+			prog := parseProgram(t, tt.src)
+
+			var reason StopReason
+			var err error
+			if tt.label != "" {
+				ok(t, s.Load(prog))
+				reason, err = s.RunUntilLabel(tt.label)
+			} else {
+				reason, err = s.Run(prog)
+			}
+			if tt.err != "" {
+				assert(t, err != nil, "expected error but got nil")
+				equals(t, tt.err, err.Error())
+			} else {
+				ok(t, err)
+			}
+			equals(t, tt.reason, reason)
+		})
+	}
+}
+
+func parseProgram(tb testing.TB, src string) *ast.Program {
+	tb.Helper()
+	prog, err := parser.New(strings.NewReader(src)).Parse()
+	ok(tb, err)
+	return prog
+}
+
+func parseStatement(tb testing.TB, str string) ast.Statement {
+	tb.Helper()
+	stmt, err := parser.ParseStatement(str)
+	ok(tb, err)
+	return stmt
+}
+
+// assert fails the test if the condition is false.
+func assert(tb testing.TB, condition bool, msg string, v ...interface{}) {
+	tb.Helper()
+	if !condition {
+		tb.Fatalf("\033[31m "+msg+"\033[39m\n\n", v...)
+	}
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}
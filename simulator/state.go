@@ -0,0 +1,50 @@
+package simulator
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// State is a snapshot of the registers of the simulator. Unlike the string
+// returned by Simulator.State it can be inspected and compared programmatically.
+type State struct {
+	// Registers are the contents of the general purpose registers, starting
+	// with r0.
+	Registers []int32
+	// PC is the program counter.
+	PC int32
+	// PSR is the processor status register holding the condition codes.
+	PSR int32
+	// Flags are the condition codes stored in the processor status register.
+	Flags Flags
+}
+
+// Snapshot returns the current state of the simulator. Later changes to the
+// simulator don't affect the returned state.
+func (s *Simulator) Snapshot() State {
+	st := State{Registers: make([]int32, s.opts.NumRegisters)}
+	for i := range st.Registers {
+		st.Registers[i] = int32(s.registers["r"+strconv.Itoa(i)])
+	}
+	st.Registers[0] = 0
+	st.PC = int32(s.registers["pc"])
+	st.PSR = int32(s.registers["psr"])
+	st.Flags = s.Flags()
+	return st
+}
+
+// String returns the string representation of the state, one register per
+// line.
+func (st State) String() string {
+	var buf bytes.Buffer
+
+	for i, val := range st.Registers {
+		fmt.Fprintf(&buf, "r%d:\t%s\n", i, Register(val).Hex())
+	}
+	fmt.Fprintf(&buf, "%s:\t%s\n", "pc", Register(st.PC).Hex())
+	fmt.Fprintf(&buf, "%s:\t%s\n", "psr", Register(st.PSR).Hex())
+	fmt.Fprintf(&buf, "%s:\t%s\n", "flags", st.Flags)
+
+	return buf.String()
+}
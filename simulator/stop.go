@@ -0,0 +1,39 @@
+package simulator
+
+// StopReason is the reason the simulator stopped executing a program.
+type StopReason int
+
+// All reasons for the simulator to stop.
+const (
+	// Stepped is returned after a single statement was executed and the
+	// program counter points to the next instruction.
+	Stepped StopReason = iota + 1
+	// Finished is returned if the program counter left the code, that is it
+	// points to data or to an address without any statement.
+	Finished
+	// ReachedLabel is returned if the program counter reached the label a run
+	// was asked to stop at.
+	ReachedLabel
+	// StepLimit is returned if the maximum number of statements for a single
+	// run was executed before the program finished.
+	StepLimit
+	// Failed is returned if a statement couldn't be executed. The error
+	// returned alongside describes the failure.
+	Failed
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case Stepped:
+		return "stepped"
+	case Finished:
+		return "finished"
+	case ReachedLabel:
+		return "reached label"
+	case StepLimit:
+		return "step limit"
+	case Failed:
+		return "failed"
+	}
+	return "unknown"
+}
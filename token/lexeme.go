@@ -0,0 +1,18 @@
+package token
+
+// Lexeme is a significant token together with its literal value, its position
+// and the trivia preceding it in the source.
+type Lexeme struct {
+	Token   Token
+	Literal string
+	Pos     Pos
+
+	// LeadingTrivia holds the whitespace, newline and comment tokens which
+	// precede the token, in source order. Trivia lexemes never carry trivia
+	// themselves.
+	LeadingTrivia []Lexeme
+}
+
+// IsTrivia returns true for tokens which carry no meaning for the syntax of a
+// program: whitespace, newlines and comments. It returns false otherwise.
+func (t Token) IsTrivia() bool { return t == WS || t == NL || t == COMMENT }
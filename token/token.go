@@ -1,6 +1,9 @@
 package token
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // Token is a lexical token of the ARC assembly language.
 type Token int
@@ -29,6 +32,15 @@ const (
 	MINUS // -
 	operatorEnd
 
+	// Comparison operators
+	comparisonBeg
+	EQL // ==
+	LSS // <
+	LEQ // <=
+	GTR // >
+	GEQ // >=
+	comparisonEnd
+
 	// Misc characters
 	LBRACKET // [
 	RBRACKET // ]
@@ -39,10 +51,17 @@ const (
 	keywordBeg
 	LOAD  // ld
 	STORE // st
+
+	// Arithmetic instructions
+	arithBeg
 	ADD   // add
 	ADDCC // addcc
 	SUB   // sub
 	SUBCC // subcc
+	arithEnd
+
+	// Logical instructions
+	logicBeg
 	AND   // and
 	ANDCC // andcc
 	OR    // or
@@ -51,22 +70,47 @@ const (
 	ORNCC // orncc
 	XOR   // xor
 	XORCC // xorcc
-	SLL   // sll (shift left logical)
-	SRA   // sra (shift right arithmetic)
-	BE    // be (branch on equal to zero)
-	BNE   // bne (branch on not equal)
-	BNEG  // bneg (branch on negative)
-	BPOS  // bpos (branch on positive)
-	BA    // ba (branch always)
-	CALL  // call (subroutine call)
-	JMPL  // jmpl (jump and link)
+	logicEnd
+
+	// Shift instructions
+	shiftBeg
+	SLL // sll (shift left logical)
+	SRA // sra (shift right arithmetic)
+	shiftEnd
+
+	// Branch instructions
+	branchBeg
+	BE   // be (branch on equal to zero)
+	BNE  // bne (branch on not equal)
+	BNEG // bneg (branch on negative)
+	BPOS // bpos (branch on positive)
+	BA   // ba (branch always)
+	branchEnd
+
+	CALL // call (subroutine call)
+	JMPL // jmpl (jump and link)
+
+	// Pseudo instructions
+	pseudoBeg
+	NOP  // nop (no operation)
+	CMP  // cmp (compare)
+	RET  // ret (return from subroutine)
+	RETL // retl (return from leaf subroutine)
+	MOV  // mov (move)
+	CLR  // clr (clear)
+	pseudoEnd
 	keywordEnd
 
 	// Directives
 	directiveBeg
-	BEGIN // .begin
-	END   // .end
-	ORG   // .org
+	BEGIN  // .begin
+	END    // .end
+	ORG    // .org
+	GLOBAL // .global
+	EXTERN // .extern
+	WORD   // .word
+	EQU    // .equ
+	ASSERT // .assert
 	directiveEnd
 )
 
@@ -87,6 +131,13 @@ var tokens = [...]string{
 	PLUS:  "+",
 	MINUS: "-",
 
+	// Comparison operators
+	EQL: "==",
+	LSS: "<",
+	LEQ: "<=",
+	GTR: ">",
+	GEQ: ">=",
+
 	// Misc characters
 	LBRACKET: "[",
 	RBRACKET: "]",
@@ -117,18 +168,29 @@ var tokens = [...]string{
 	BA:    "ba",
 	CALL:  "call",
 	JMPL:  "jmpl",
+	NOP:   "nop",
+	CMP:   "cmp",
+	RET:   "ret",
+	RETL:  "retl",
+	MOV:   "mov",
+	CLR:   "clr",
 
 	// Directives
-	BEGIN: ".begin",
-	END:   ".end",
-	ORG:   ".org",
+	BEGIN:  ".begin",
+	END:    ".end",
+	ORG:    ".org",
+	GLOBAL: ".global",
+	EXTERN: ".extern",
+	WORD:   ".word",
+	EQU:    ".equ",
+	ASSERT: ".assert",
 }
 
 var reservedWords map[string]Token
 
 func init() {
 	reservedWords = make(map[string]Token)
-	for tok := keywordBeg + 1; tok < keywordEnd; tok++ {
+	for _, tok := range Keywords() {
 		reservedWords[strings.ToLower(tokens[tok])] = tok
 	}
 	for tok := directiveBeg + 1; tok < directiveEnd; tok++ {
@@ -141,6 +203,27 @@ func (t Token) String() string {
 	return tokens[t]
 }
 
+// MarshalText implements encoding.TextMarshaler. The token is encoded by its
+// string representation.
+func (t Token) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(tokens) || tokens[t] == "" {
+		return nil, fmt.Errorf("invalid token %d", int(t))
+	}
+	return []byte(tokens[t]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It is the inverse of
+// MarshalText.
+func (t *Token) UnmarshalText(text []byte) error {
+	for tok, str := range tokens {
+		if str != "" && str == string(text) {
+			*t = Token(tok)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown token %q", text)
+}
+
 // IsSpecial returns true for tokens corresponding to special tokens. It returns
 // false otherwise.
 func (t Token) IsSpecial() bool { return specialBeg < t && t < specialEnd }
@@ -157,10 +240,50 @@ func (t Token) IsOperator() bool { return operatorBeg < t && t < operatorEnd }
 // otherwise.
 func (t Token) IsKeyword() bool { return keywordBeg < t && t < keywordEnd }
 
+// IsArithmetic returns true for tokens corresponding to arithmetic
+// instructions. It returns false otherwise.
+func (t Token) IsArithmetic() bool { return arithBeg < t && t < arithEnd }
+
+// IsLogic returns true for tokens corresponding to logical instructions. It
+// returns false otherwise.
+func (t Token) IsLogic() bool { return logicBeg < t && t < logicEnd }
+
+// IsShift returns true for tokens corresponding to shift instructions. It
+// returns false otherwise.
+func (t Token) IsShift() bool { return shiftBeg < t && t < shiftEnd }
+
+// IsBranch returns true for tokens corresponding to branch instructions. It
+// returns false otherwise.
+func (t Token) IsBranch() bool { return branchBeg < t && t < branchEnd }
+
+// IsPseudo returns true for tokens corresponding to pseudo instructions, which
+// are lowered to real instructions before assembling. It returns false
+// otherwise.
+func (t Token) IsPseudo() bool { return pseudoBeg < t && t < pseudoEnd }
+
+// IsCC returns true for tokens corresponding to instructions which set the
+// condition codes. It returns false otherwise.
+func (t Token) IsCC() bool {
+	switch t {
+	case ADDCC, SUBCC, ANDCC, ORCC, ORNCC, XORCC, CMP:
+		return true
+	}
+	return false
+}
+
+// IsComparison returns true for tokens corresponding to comparison operators.
+// It returns false otherwise.
+func (t Token) IsComparison() bool { return comparisonBeg < t && t < comparisonEnd }
+
 // IsDirective returns true for tokens corresponding to directives. It returns
 // false otherwise.
 func (t Token) IsDirective() bool { return directiveBeg < t && t < directiveEnd }
 
+// EndsStatement returns true for tokens which may follow a complete statement
+// on the same line. These are newlines, the end of the file and comments, which
+// trail the statement. It returns false otherwise.
+func (t Token) EndsStatement() bool { return t == NL || t == EOF || t == COMMENT }
+
 // Directives returns all tokens corresponding to directives.
 func Directives() []Token {
 	var buf []Token
@@ -170,10 +293,14 @@ func Directives() []Token {
 	return buf
 }
 
-// Keywords returns all tokens corresponding to keywords.
+// Keywords returns all tokens corresponding to keywords. The sentinels of the
+// instruction groups inside the keyword range are omitted.
 func Keywords() []Token {
 	var buf []Token
 	for i := keywordBeg + 1; i < keywordEnd; i++ {
+		if tokens[i] == "" {
+			continue
+		}
 		buf = append(buf, Token(i))
 	}
 	return buf
@@ -68,6 +68,11 @@ func TestToken(t *testing.T) {
 		{".begin", token.BEGIN, false, false, false, false, true},
 		{".end", token.END, false, false, false, false, true},
 		{".org", token.ORG, false, false, false, false, true},
+		{".global", token.GLOBAL, false, false, false, false, true},
+		{".extern", token.EXTERN, false, false, false, false, true},
+		{".word", token.WORD, false, false, false, false, true},
+		{".equ", token.EQU, false, false, false, false, true},
+		{".assert", token.ASSERT, false, false, false, false, true},
 	}
 
 	for _, tt := range tests {
@@ -100,10 +105,109 @@ func TestDirectives(t *testing.T) {
 	}
 }
 
+// TestEndsStatement validates the tokens which may follow a statement.
+func TestEndsStatement(t *testing.T) {
+	for _, tok := range []token.Token{token.NL, token.EOF, token.COMMENT} {
+		assert(t, tok.EndsStatement(), "Token %s doesn't end a statement!", tok)
+	}
+	for _, tok := range []token.Token{token.IDENT, token.INT, token.COMMA, token.ORG, token.ADD} {
+		assert(t, !tok.EndsStatement(), "Token %s ends a statement!", tok)
+	}
+}
+
 func TestKeywords(t *testing.T) {
 	for _, tok := range token.Keywords() {
 		assert(t, tok.IsKeyword(), "Returned token isn't a keyword!", tok)
+		assert(t, tok.String() != "", "Returned token %d has no string representation!", tok)
+	}
+}
+
+// TestKeywordGroups makes sure that every keyword belongs to exactly the right
+// instruction group.
+func TestKeywordGroups(t *testing.T) {
+	tests := []struct {
+		tok   token.Token
+		group string
+		isCC  bool
+	}{
+		{token.LOAD, "", false},
+		{token.STORE, "", false},
+		{token.ADD, "arith", false},
+		{token.ADDCC, "arith", true},
+		{token.SUB, "arith", false},
+		{token.SUBCC, "arith", true},
+		{token.AND, "logic", false},
+		{token.ANDCC, "logic", true},
+		{token.OR, "logic", false},
+		{token.ORCC, "logic", true},
+		{token.ORN, "logic", false},
+		{token.ORNCC, "logic", true},
+		{token.XOR, "logic", false},
+		{token.XORCC, "logic", true},
+		{token.SLL, "shift", false},
+		{token.SRA, "shift", false},
+		{token.BE, "branch", false},
+		{token.BNE, "branch", false},
+		{token.BNEG, "branch", false},
+		{token.BPOS, "branch", false},
+		{token.BA, "branch", false},
+		{token.CALL, "", false},
+		{token.JMPL, "", false},
+		{token.NOP, "pseudo", false},
+		{token.CMP, "pseudo", true},
+		{token.RET, "pseudo", false},
+		{token.RETL, "pseudo", false},
+		{token.MOV, "pseudo", false},
+		{token.CLR, "pseudo", false},
+	}
+
+	// Every keyword must be covered by the table above.
+	equals(t, len(token.Keywords()), len(tests))
+
+	for _, tt := range tests {
+		t.Run(tt.tok.String(), func(t *testing.T) {
+			equals(t, tt.group == "arith", tt.tok.IsArithmetic())
+			equals(t, tt.group == "logic", tt.tok.IsLogic())
+			equals(t, tt.group == "shift", tt.tok.IsShift())
+			equals(t, tt.group == "branch", tt.tok.IsBranch())
+			equals(t, tt.group == "pseudo", tt.tok.IsPseudo())
+			equals(t, tt.isCC, tt.tok.IsCC())
+			equals(t, tt.tok, token.Lookup(tt.tok.String()))
+		})
+	}
+}
+
+// TestToken_MarshalText validates that tokens are encoded by their string
+// representation and decoded again.
+func TestToken_MarshalText(t *testing.T) {
+	toks := append([]token.Token{token.ILLEGAL, token.COMMENT, token.IDENT, token.REG, token.INT, token.PLUS}, token.Keywords()...)
+	for _, tok := range append(toks, token.Directives()...) {
+		text, err := tok.MarshalText()
+		ok(t, err)
+		equals(t, tok.String(), string(text))
+
+		var res token.Token
+		ok(t, res.UnmarshalText(text))
+		equals(t, tok, res)
+	}
+
+	var res token.Token
+	if err := res.UnmarshalText([]byte("foo")); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if _, err := token.Token(-1).MarshalText(); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+// TestIsComparison validates the comparison operators.
+func TestIsComparison(t *testing.T) {
+	for _, tok := range []token.Token{token.EQL, token.LSS, token.LEQ, token.GTR, token.GEQ} {
+		equals(t, true, tok.IsComparison())
+		equals(t, false, tok.IsOperator())
 	}
+	equals(t, false, token.PLUS.IsComparison())
+	equals(t, false, token.COLON.IsComparison())
 }
 
 // TestLookup makes sure that Lookup returns either the right keyword or IDENT
@@ -148,6 +252,9 @@ func TestLookup(t *testing.T) {
 		{".begin", false, true},
 		{".end", false, true},
 		{".org", false, true},
+		{".global", false, true},
+		{".extern", false, true},
+		{".assert", false, true},
 	}
 
 	for _, tt := range tests {
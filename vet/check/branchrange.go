@@ -0,0 +1,58 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Branchrange checks if the targets of branches are within the range of their
+// displacement. A branch encodes the distance to its target in words as 22 bit
+// signed integer, so targets farther than about 8 MiB can't be reached. Calls
+// encode a 30 bit displacement, which reaches every word of the 32 bit address
+// space, so they are always in range.
+type Branchrange struct {
+	name string
+}
+
+func init() {
+	Register(&Branchrange{"branchrange"})
+}
+
+// Desc returns a description of the Check.
+func (c Branchrange) Desc() string {
+	return "checks for branch targets beyond the range of the 22 bit displacement"
+}
+
+// Name returns the name of the Check.
+func (c Branchrange) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Branchrange) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	// Collect the addresses of all labels.
+	layout, _ := ast.Layout(prog)
+
+	// See if the displacement to the target of a branch fits into disp22.
+	// Undefined targets are reported by the parser.
+	for _, stmt := range prog.Statements {
+		target, valid := branchTarget(stmt)
+		if !valid || target == nil {
+			continue
+		}
+		dest, known := layout.Symbols[target.Name]
+		if !known {
+			continue
+		}
+		disp := (int64(dest) - int64(layout.Addrs[stmt])) / 4
+		if disp < -1<<21 || disp >= 1<<21 {
+			msg := buildMsg(c, stmt.Pos(), fmt.Sprintf("displacement of %d words to %q exceeds the range of disp22 [%d, %d]", disp, target.Name, -1<<21, 1<<21-1))
+			res = append(res, msg)
+		}
+	}
+
+	return res, nil
+}
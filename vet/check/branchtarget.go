@@ -0,0 +1,122 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Branchtarget checks for branches and calls to labels which aren't declared.
+// The parser reports these as unresolved identifiers, but the program parsed
+// so far is still checked. The message suggests the declared label closest to
+// the target, which is usually the one misspelled. Labels declared by .extern
+// count as declared.
+type Branchtarget struct {
+	name string
+}
+
+func init() {
+	Register(&Branchtarget{"branchtarget"})
+}
+
+// Desc returns a description of the Check.
+func (c Branchtarget) Desc() string {
+	return "searches branches and calls to undefined labels"
+}
+
+// Name returns the name of the Check.
+func (c Branchtarget) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Branchtarget) Run(prog *ast.Program) ([]string, error) {
+	var (
+		res    []string
+		jumps  []ast.Statement
+		labels []string
+	)
+	declared := make(map[string]bool)
+
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.LabelStatement:
+			declared[s.Ident.Name] = true
+			labels = append(labels, s.Ident.Name)
+			if _, valid := branchTarget(stmt); valid {
+				jumps = append(jumps, s.Reference.(ast.Statement))
+			}
+		case *ast.ExternStatement:
+			declared[s.Ident.Name] = true
+		case *ast.CallStatement:
+			jumps = append(jumps, stmt)
+		default:
+			if _, valid := branchTarget(stmt); valid {
+				jumps = append(jumps, stmt)
+			}
+		}
+	}
+	sort.Strings(labels)
+
+	for _, stmt := range jumps {
+		target, _ := branchTarget(stmt)
+		if call, valid := stmt.(*ast.CallStatement); valid {
+			target = call.Target
+		}
+		if target == nil || declared[target.Name] {
+			continue
+		}
+		msg := fmt.Sprintf("%s to undefined label %q", stmt.Tok(), target.Name)
+		if suggestion := closest(target.Name, labels); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		res = append(res, buildMsg(c, stmt.Pos(), msg))
+	}
+
+	return res, nil
+}
+
+// closest returns the candidate with the smallest edit distance to name. Only
+// candidates which differ in at most half of the characters of name are
+// considered, so an unrelated label isn't suggested. The empty string is
+// returned if there is no such candidate.
+func closest(name string, candidates []string) string {
+	best, bestDist := "", len(name)/2+1
+	for _, candidate := range candidates {
+		if dist := editDistance(name, candidate); dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance of a and b, the minimal number
+// of inserted, deleted or substituted characters to turn a into b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(min(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// min returns the smaller of x or y.
+func min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
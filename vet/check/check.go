@@ -24,6 +24,25 @@ type Check interface {
 	Run(*ast.Program) ([]string, error)
 }
 
+// Config are the options of checks which can be configured.
+type Config struct {
+	// ForbiddenPseudo are the mnemonics of the pseudo instructions reported
+	// by the nopseudo check, like "mov".
+	ForbiddenPseudo []string
+	// LabelPattern is the regular expression labels must match to pass the
+	// naming check. If unset, DefaultLabelPattern is used.
+	LabelPattern string
+}
+
+// Configurable is the interface implemented by checks which take options or
+// examine the source code instead of the AST. The registered checks are shared,
+// so Configure must return a new check instead of altering the receiver. The
+// source is nil if the program was parsed from a file. An error is returned if
+// the options are invalid.
+type Configurable interface {
+	Configure(cfg Config, src []byte) (Check, error)
+}
+
 var checks = make(map[string]Check)
 
 // Register makes a check available by the provided name. If Register is called
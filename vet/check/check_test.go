@@ -0,0 +1,616 @@
+package check
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/parser"
+)
+
+// TestUnuseddata validates the results of the unuseddata check.
+func TestUnuseddata(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Data which is loaded is fine.
+		{
+			name: "loaded data",
+			src:  "ld [x], %r1\nx: 10",
+			res:  nil,
+		},
+		// Data which is only stored to is never read.
+		{
+			name: "stored data",
+			src:  "ld [x], %r1\nst %r1, [z]\nx: 10\nz: 0",
+			res:  []string{`4:1: data label "z" defined but never read (unuseddata)`},
+		},
+		// Labels referencing instructions are not data.
+		{
+			name: "instruction labels",
+			src:  "ba y\ny: ld [x+4], %r1\nx: 10",
+			res:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "unuseddata", tt.src))
+		})
+	}
+}
+
+// TestDeadcode validates the results of the deadcode check.
+func TestDeadcode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// An instruction after an unconditional branch is never executed.
+		{
+			name: "after ba",
+			src:  "ba exit\nld [x], %r1\nadd %r1, 1, %r1\nexit: st %r1, [x]\nx: 10",
+			res:  []string{`2:1: unreachable code after "ba exit" (deadcode)`},
+		},
+		// The same applies to returns, comments don't matter.
+		{
+			name: "after returns",
+			src:  "ret\n! stray\nld [x], %r1\nretl\n.org 2048\nnop\nx: 10",
+			res: []string{
+				`3:1: unreachable code after "ret" (deadcode)`,
+				`6:1: unreachable code after "retl" (deadcode)`,
+			},
+		},
+		// Labels make code reachable, conditional branches continue.
+		{
+			name: "reachable by labels",
+			src:  "bne next\nadd %r1, 1, %r1\nba done\nnext: sub %r1, 1, %r1\njmpl %r15+4, %r0\ndone: 0",
+			res:  nil,
+		},
+		// Labeled branches end reachability as well.
+		{
+			name: "labeled branch",
+			src:  "loop: ba loop\nnop",
+			res:  []string{`2:1: unreachable code after "loop: ba loop" (deadcode)`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "deadcode", tt.src))
+		})
+	}
+}
+
+// TestBranchtarget validates the results of the branchtarget check. The
+// programs don't parse because of the undefined labels, but are checked
+// anyway.
+func TestBranchtarget(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// A misspelled target is reported along with the label meant.
+		{
+			name: "misspelled labels",
+			src:  "loop: subcc %r1, 1, %r1\nbne lop\nba dnoe\ncall exit\ndone: add %r1, %r2, %r3",
+			res: []string{
+				`2:1: bne to undefined label "lop", did you mean "loop"? (branchtarget)`,
+				`3:1: ba to undefined label "dnoe", did you mean "done"? (branchtarget)`,
+				`4:1: call to undefined label "exit" (branchtarget)`,
+			},
+		},
+		// Declared and extern labels are fine.
+		{
+			name: "declared and extern labels",
+			src:  ".extern sum\nloop: subcc %r1, 1, %r1\nbpos loop\ncall sum",
+			res:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, _ := parser.New(strings.NewReader(tt.src)).Parse()
+			c, err := Get("branchtarget")
+			ok(t, err)
+			res, err := c.Run(prog)
+			ok(t, err)
+			equals(t, tt.res, res)
+		})
+	}
+}
+
+// TestSimm13 validates the results of the simm13 check at the boundaries of
+// the 13 bit signed range.
+func TestSimm13(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// The boundaries themselves fit.
+		{
+			name: "boundaries",
+			src:  "add %r1, 4095, %r2\nsub %r1, -4096, %r2\nx: orcc %r1, 4095, %r2",
+			res:  nil,
+		},
+		// One beyond doesn't.
+		{
+			name: "beyond boundaries",
+			src:  "add %r1, 4096, %r2\nsub %r1, -4097, %r2\nx: orcc %r1, 9000, %r2",
+			res: []string{
+				`1:10: immediate 4096 of "add %r1, 4096, %r2" exceeds the simm13 range [-4096, 4095] (simm13)`,
+				`2:10: immediate -4097 of "sub %r1, -4097, %r2" exceeds the simm13 range [-4096, 4095] (simm13)`,
+				`3:14: immediate 9000 of "orcc %r1, 9000, %r2" exceeds the simm13 range [-4096, 4095] (simm13)`,
+			},
+		},
+		// Constants and pseudo instructions are checked as well, data isn't.
+		{
+			name: "constants and pseudo instructions",
+			src:  ".equ BIG, 5000\nadd %r1, BIG, %r2\nmov 4096, %r1\ncmp %r1, -4096\n.word 1, 5000, 2",
+			res: []string{
+				`2:10: immediate 5000 of "add %r1, BIG, %r2" exceeds the simm13 range [-4096, 4095] (simm13)`,
+				`3:5: immediate 4096 of "mov 4096, %r1" exceeds the simm13 range [-4096, 4095] (simm13)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "simm13", tt.src))
+		})
+	}
+}
+
+// TestMemaddr validates the results of the memaddr check.
+func TestMemaddr(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Labels within the immediate range and register bases are fine.
+		{
+			name: "labels in range",
+			src:  ".org 2048\nld [x], %r1\nst %r1, [%r2+4]\nx: 10",
+			res:  nil,
+		},
+		// Labels beyond the immediate range can't be encoded.
+		{
+			name: "labels out of range",
+			src:  ".org 2048\nld [x], %r1\nst %r1, [x-4]\n.org 4096\nx: 10",
+			res:  []string{`2:4: address 0x00001000 of "[x]" can't be encoded as 13 bit immediate, load it into a register first (memaddr)`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "memaddr", tt.src))
+		})
+	}
+}
+
+// TestBranchrange validates the results of the branchrange check. The far
+// target is moved beyond the range of disp22 by an .org directive.
+func TestBranchrange(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Targets at the boundaries of the displacement are fine.
+		{
+			name: "boundaries",
+			src:  "ba fwd\n.org 0x7ffffc\nfwd: nop\n.org 0x800000\nback: nop\n.org 0x1000000\nbe back",
+			res:  nil,
+		},
+		// Targets one word beyond aren't.
+		{
+			name: "beyond boundaries",
+			src:  "bpos far\ncall far\n.org 0x800000\nfar: add %r1, %r2, %r3\n.org 0x1000004\nba far",
+			res: []string{
+				`1:1: displacement of 2097152 words to "far" exceeds the range of disp22 [-2097152, 2097151] (branchrange)`,
+				`6:1: displacement of -2097153 words to "far" exceeds the range of disp22 [-2097152, 2097151] (branchrange)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "branchrange", tt.src))
+		})
+	}
+}
+
+// TestShiftrange validates the results of the shiftrange check at and beyond
+// the bounds of the shift count.
+func TestShiftrange(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Counts from 0 to 31 and registers are fine.
+		{
+			name: "counts in range",
+			src:  "sll %r1, 0, %r2\nsra %r1, 31, %r2\nx: sll %r1, %r3, %r2",
+			res:  nil,
+		},
+		// Larger counts are masked to their lowest five bits.
+		{
+			name: "counts out of range",
+			src:  ".equ N, 40\nsll %r1, 32, %r2\nsra %r1, N, %r2\nx: sll %r1, -1, %r2",
+			res: []string{
+				`2:10: shift count 32 of "sll %r1, 32, %r2" is outside of 0 to 31, it shifts by 0 (shiftrange)`,
+				`3:10: shift count 40 of "sra %r1, N, %r2" is outside of 0 to 31, it shifts by 8 (shiftrange)`,
+				`4:13: shift count -1 of "sll %r1, -1, %r2" is outside of 0 to 31, it shifts by 31 (shiftrange)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "shiftrange", tt.src))
+		})
+	}
+}
+
+// TestR0write validates the results of the r0write check.
+func TestR0write(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Reading %r0 and setting only the condition codes are fine.
+		{
+			name: "reading r0",
+			src:  "add %r0, %r1, %r2\nld [%r0+2048], %r1\nsubcc %r1, %r2, %r0\nst %r0, [x]\nx: 0",
+			res:  nil,
+		},
+		// Loads and arithmetic discarding their result.
+		{
+			name: "discarded results",
+			src:  "ld [x], %r0\nadd %r1, %r2, %r0\ny: sll %r1, 2, %r0\nx: 0",
+			res: []string{
+				`1:9: result of "ld [x], %r0" is discarded, %r0 is always zero (r0write)`,
+				`2:15: result of "add %r1, %r2, %r0" is discarded, %r0 is always zero (r0write)`,
+				`3:16: result of "sll %r1, 2, %r0" is discarded, %r0 is always zero (r0write)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "r0write", tt.src))
+		})
+	}
+}
+
+// TestSelfloop validates the results of the selfloop check.
+func TestSelfloop(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Loops setting the condition codes or branching elsewhere can be
+		// left.
+		{
+			name: "leaving loops",
+			src:  "loop: subcc %r1, 1, %r1\nbne loop\nx: add %r1, 1, %r1\nbe done\nba x\ndone: ba loop",
+			res:  nil,
+		},
+		// A tight loop branching to itself.
+		{
+			name: "branching to itself",
+			src:  "nop\nloop: ba loop",
+			res: []string{
+				`2:7: "ba loop" branches to itself forever (selfloop)`,
+			},
+		},
+		// A conditional branch without a condition code setting instruction
+		// in the loop.
+		{
+			name: "unchanged condition codes",
+			src:  "cmp %r1, 0\nloop: add %r2, 1, %r2\nsll %r2, 1, %r3\nbe loop\nwait: bne wait",
+			res: []string{
+				`4:1: "be loop" loops forever once taken, no instruction in the loop sets the condition codes (selfloop)`,
+				`5:7: "bne wait" loops forever once taken, no instruction in the loop sets the condition codes (selfloop)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "selfloop", tt.src))
+		})
+	}
+}
+
+// TestInnertabs validates the results of the innertabs check. Tabs indenting a
+// line aren't reported.
+func TestInnertabs(t *testing.T) {
+	c, err := Get("innertabs")
+	ok(t, err)
+
+	src := "\tld\t[x],\t%r1\n\t\t! only indented\nx:\t0\t! data\n\tst %r1, [x]"
+	prog, err := parser.New(strings.NewReader(src)).Parse()
+	ok(t, err)
+
+	// Without source, a program not parsed from a file isn't checked.
+	res, err := c.Run(prog)
+	ok(t, err)
+	equals(t, []string(nil), res)
+
+	res, err = NewInnertabs([]byte(src)).Run(prog)
+	ok(t, err)
+	equals(t, []string{
+		`1:4: tab inside of statement, use spaces instead (innertabs)`,
+		`1:9: tab inside of statement, use spaces instead (innertabs)`,
+		`3:3: tab inside of statement, use spaces instead (innertabs)`,
+		`3:5: tab inside of statement, use spaces instead (innertabs)`,
+	}, res)
+}
+
+// TestMissingreturn validates the results of the missingreturn check.
+func TestMissingreturn(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Subroutines returning directly, after a loop or by falling
+		// through into another label.
+		{
+			name: "returning subroutines",
+			src:  "call inc\ncall sum\ncall twice\nx: 0\ninc: add %r1, 1, %r1\nret\nsum: subcc %r1, 1, %r1\nbe done\nba sum\ndone: nop\nretl\ntwice: add %r1, %r1, %r1\nonce: add %r1, 1, %r1\njmpl %r15+4, %r0",
+			res:  nil,
+		},
+		// Subroutines running into data or off the end of the code, reported
+		// once at their label.
+		{
+			name: "subroutines not returning",
+			src:  "call inc\ncall inc\ncall last\ninc: add %r1, 1, %r1\nst %r1, [x]\nx: 0\nlast: subcc %r1, 1, %r1\nbne last",
+			res: []string{
+				`4:1: subroutine "inc" called at 1:1 never returns with jmpl (missingreturn)`,
+				`7:1: subroutine "last" called at 3:1 never returns with jmpl (missingreturn)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "missingreturn", tt.src))
+		})
+	}
+}
+
+// TestNaming validates the results of the naming check with the default and a
+// custom pattern.
+func TestNaming(t *testing.T) {
+	c, err := Get("naming")
+	ok(t, err)
+
+	prog, err := parser.New(strings.NewReader("loop: nop\ninit_r2: nop\nLoop2Go: nop\nR1: nop\npc: nop\nTmp: 0")).Parse()
+	ok(t, err)
+	res, err := c.Run(prog)
+	ok(t, err)
+	equals(t, []string{
+		`3:1: label "Loop2Go" doesn't match the naming convention "^[a-z][a-z0-9_]*$" (naming)`,
+		`4:1: label "R1" shadows the register "%r1" (naming)`,
+		`5:1: label "pc" shadows the register "%pc" (naming)`,
+		`6:1: label "Tmp" doesn't match the naming convention "^[a-z][a-z0-9_]*$" (naming)`,
+	}, res)
+
+	// Mixed case is allowed by a custom pattern, registers are still
+	// reported.
+	mixed, err := NewNaming("^[A-Za-z][A-Za-z0-9_]*$")
+	ok(t, err)
+	res, err = mixed.Run(prog)
+	ok(t, err)
+	equals(t, []string{
+		`4:1: label "R1" shadows the register "%r1" (naming)`,
+		`5:1: label "pc" shadows the register "%pc" (naming)`,
+	}, res)
+
+	_, err = NewNaming("[a-z")
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	equals(t, "invalid label pattern \"[a-z\": error parsing regexp: missing closing ]: `[a-z`", err.Error())
+}
+
+// TestIneffassign validates the results of the ineffassign check.
+func TestIneffassign(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Labels used by loads, calls and jumps are fine.
+		{
+			name: "used labels",
+			src:  "call fn\nld %r1, %r2\nfn: ld [x], %r1\njmpl [y], %r0\nx: 10\ny: 0",
+			res:  nil,
+		},
+		// A label only referencing itself isn't used.
+		{
+			name: "unused label",
+			src:  "ld [x], %r1\nz: st %r1, [x]\nx: 10",
+			res:  []string{`2:1: "z" declared but not used (ineffassign)`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "ineffassign", tt.src))
+		})
+	}
+}
+
+// TestNopseudo validates that only forbidden pseudo instructions are reported
+// along with the real instruction they stand for.
+func TestNopseudo(t *testing.T) {
+	c, err := Get("nopseudo")
+	ok(t, err)
+
+	prog, err := parser.New(strings.NewReader("mov %r1, %r2\ncmp %r1, 0\nx: mov 5, %r3\nclr %r4")).Parse()
+	ok(t, err)
+	res, err := c.Run(prog)
+	ok(t, err)
+	equals(t, []string(nil), res)
+
+	forbidding, err := NewNopseudo("mov", "clr")
+	ok(t, err)
+	res, err = forbidding.Run(prog)
+	ok(t, err)
+	equals(t, []string{
+		`1:1: pseudo instruction "mov %r1, %r2" is forbidden, use "or %r0, %r1, %r2" instead (nopseudo)`,
+		`3:4: pseudo instruction "mov 5, %r3" is forbidden, use "or %r0, 5, %r3" instead (nopseudo)`,
+		`4:1: pseudo instruction "clr %r4" is forbidden, use "or %r0, %r0, %r4" instead (nopseudo)`,
+	}, res)
+
+	_, err = NewNopseudo("add")
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	equals(t, `"add" is not a pseudo instruction`, err.Error())
+
+	// The registered check still forbids nothing.
+	res, err = c.Run(prog)
+	ok(t, err)
+	equals(t, []string(nil), res)
+}
+
+// TestLoopcounter validates the results of the loopcounter check.
+func TestLoopcounter(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		res  []string
+	}{
+		// Decrement and exit at the end of the loop is fine.
+		{
+			name: "exit on zero",
+			src:  "ld [n], %r1\nloop: add %r2, %r1, %r2\nsubcc %r1, 1, %r1\nbe done\nba loop\ndone: st %r2, [n]\nn: 4",
+			res:  nil,
+		},
+		{
+			name: "continue while not zero",
+			src:  "ld [n], %r1\nloop: add %r2, %r1, %r2\nsubcc %r1, 1, %r1\nbne loop\nst %r2, [n]\nn: 4",
+			res:  nil,
+		},
+		// Exiting on negative runs one iteration too many.
+		{
+			name: "exit on negative",
+			src:  "ld [n], %r1\nloop: add %r2, %r1, %r2\nsubcc %r1, 1, %r1\nbneg done\nba loop\ndone: st %r2, [n]\nn: 4",
+			res:  []string{`3:1: loop exits once %r1 is negative, which runs one iteration too many (loopcounter)`},
+		},
+		{
+			name: "continue while positive",
+			src:  "ld [n], %r1\nloop: add %r2, %r1, %r2\nsubcc %r1, 1, %r1\nbpos loop\nst %r2, [n]\nn: 4",
+			res:  []string{`3:1: loop continues while %r1 is positive or zero, which runs one iteration too many (loopcounter)`},
+		},
+		// Decrementing at the top of the loop runs one iteration too few.
+		{
+			name: "decrement at the top",
+			src:  "ld [n], %r1\nloop: subcc %r1, 1, %r1\nbe done\nadd %r2, %r1, %r2\nba loop\ndone: st %r2, [n]\nn: 4",
+			res:  []string{`2:7: loop counter %r1 is decremented before the loop body, which runs one iteration too few (loopcounter)`},
+		},
+		// A counter starting at zero never reaches zero again.
+		{
+			name: "counter starting at zero",
+			src:  "ld [n], %r1\nloop: add %r2, %r1, %r2\nsubcc %r1, 1, %r1\nbne loop\nst %r2, [n]\nn: 0",
+			res:  []string{`3:1: loop counter %r1 starts at 0 and never reaches zero again (loopcounter)`},
+		},
+		// Only the last write to the counter before the loop counts.
+		{
+			name: "counter overwritten",
+			src:  "add %r0, 0, %r1\nmov 5, %r1\nloop: addcc %r1, -1, %r1\nbne loop",
+			res:  nil,
+		},
+		{
+			name: "counter overwritten by unknown value",
+			src:  "ld [n], %r1\nsll %r2, 2, %r1\nloop: subcc %r1, 1, %r1\nbne loop\nn: 0",
+			res:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equals(t, tt.res, runCheck(t, "loopcounter", tt.src))
+		})
+	}
+}
+
+// TestDirectives_Included validates that the directives check reports .begin
+// and .end directives of an included file if the including file declares them
+// as well.
+func TestDirectives_Included(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arc")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+
+	main := filepath.Join(dir, "main.arc")
+	inc := filepath.Join(dir, "inc.arc")
+	ok(t, ioutil.WriteFile(main, []byte(".begin\n.org 2048\nld %r1, %r2\n.end\n"), 0644))
+	ok(t, ioutil.WriteFile(inc, []byte(".begin\nld %r2, %r3\n.end\n"), 0644))
+
+	mainProg, err := parser.ParseFile(main)
+	ok(t, err)
+	incProg, err := parser.ParseFile(inc)
+	ok(t, err)
+
+	// Splice the included file in after the load, like an include would.
+	prog := *mainProg
+	prog.Statements = append([]ast.Statement{}, mainProg.Statements[:3]...)
+	prog.Statements = append(prog.Statements, incProg.Statements...)
+	prog.Statements = append(prog.Statements, mainProg.Statements[3:]...)
+
+	c, err := Get("directives")
+	ok(t, err)
+	res, err := c.Run(&prog)
+	ok(t, err)
+	equals(t, []string{
+		inc + ":1:1: .begin in included file: already declared at " + main + ":1:1 (directives)",
+		inc + ":3:1: .end in included file: already declared at " + main + ":4:1 (directives)",
+	}, res)
+}
+
+// runCheck parses the source code and returns the results of running the
+// registered check with the given name on it.
+func runCheck(t *testing.T, name, src string) []string {
+	t.Helper()
+	prog, err := parser.New(strings.NewReader(src)).Parse()
+	ok(t, err)
+	c, err := Get(name)
+	ok(t, err)
+	res, err := c.Run(prog)
+	ok(t, err)
+	return res
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}
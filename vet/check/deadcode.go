@@ -0,0 +1,68 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Deadcode checks for instructions which can never be executed. These are the
+// instructions following a ba or jmpl, which never continue at the next
+// instruction. A label makes its instruction reachable again, as it can be the
+// target of a branch. Only the first instruction of every unreachable run is
+// reported. Comments, data and directives aren't executed and therefore
+// ignored.
+type Deadcode struct {
+	name string
+}
+
+func init() {
+	Register(&Deadcode{"deadcode"})
+}
+
+// Desc returns a description of the Check.
+func (c Deadcode) Desc() string {
+	return "searches unreachable instructions after unconditional branches"
+}
+
+// Name returns the name of the Check.
+func (c Deadcode) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Deadcode) Run(prog *ast.Program) ([]string, error) {
+	var (
+		res  []string
+		jump ast.Statement
+	)
+
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.LabelStatement:
+			jump = nil
+			if _, valid := s.Reference.(*ast.BAStatement); valid {
+				jump = stmt
+			}
+			continue
+		case *ast.BeginStatement:
+			jump = nil
+			continue
+		case *ast.CommentStatement, *ast.EndStatement, *ast.OrgStatement, *ast.GlobalStatement, *ast.ExternStatement, *ast.WordStatement, *ast.EquStatement, *ast.AssertStatement:
+			continue
+		}
+
+		if jump != nil {
+			msg := buildMsg(c, stmt.Pos(), fmt.Sprintf("unreachable code after %q", jump))
+			res = append(res, msg)
+			jump = nil
+			continue
+		}
+		switch ast.LowerStatement(stmt).(type) {
+		case *ast.BAStatement, *ast.JumpAndLinkStatement:
+			jump = stmt
+		}
+	}
+
+	return res, nil
+}
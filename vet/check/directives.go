@@ -7,7 +7,9 @@ import (
 )
 
 // Directives checks if there are any statements outside the .begin and .end
-// directives.
+// directives. Statements spliced in from included files are told apart by the
+// filename of their position, so a .begin or .end of an included file is
+// reported if the including file declares it as well.
 type Directives struct {
 	name string
 }
@@ -46,9 +48,25 @@ func (c *Directives) checkOrder(prog *ast.Program) []string {
 		orgStmts  []*ast.OrgStatement
 	)
 
+	// The .begin and .end declared by the including file take precedence over
+	// the ones of included files.
+	mainBegin, mainEnd := mainDirectives(prog)
+
 	for _, stmt := range prog.Statements {
+		// A label naming a section is checked like its .org directive.
+		if label, valid := stmt.(*ast.LabelStatement); valid {
+			if org, isOrg := label.Reference.(*ast.OrgStatement); isOrg {
+				stmt = org
+			}
+		}
+
 		switch stmt.(type) {
 		case *ast.BeginStatement:
+			if mainBegin != nil && stmt != mainBegin {
+				msg := buildMsg(c, stmt.Pos(), fmt.Sprintf(".begin in included file: already declared at %s", mainBegin.Pos()))
+				res = append(res, msg)
+				continue
+			}
 			if beginStmt != nil {
 				msg := buildMsg(c, stmt.Pos(), fmt.Sprintf("duplicate .begin: first one at %s", beginStmt.Pos().NoFile()))
 				res = append(res, msg)
@@ -56,6 +74,11 @@ func (c *Directives) checkOrder(prog *ast.Program) []string {
 			}
 			beginStmt = stmt.(*ast.BeginStatement)
 		case *ast.EndStatement:
+			if mainEnd != nil && stmt != mainEnd {
+				msg := buildMsg(c, stmt.Pos(), fmt.Sprintf(".end in included file: already declared at %s", mainEnd.Pos()))
+				res = append(res, msg)
+				continue
+			}
 			if endStmt != nil {
 				msg := buildMsg(c, stmt.Pos(), fmt.Sprintf("duplicate .end: first one at %s", endStmt.Pos().NoFile()))
 				res = append(res, msg)
@@ -121,3 +144,31 @@ func (c *Directives) checkOrder(prog *ast.Program) []string {
 
 	return res
 }
+
+// mainDirectives returns the first .begin and .end directives declared by the
+// file of the program itself, if statements of included files are part of the
+// program. Otherwise nil is returned for both.
+func mainDirectives(prog *ast.Program) (begin, end ast.Statement) {
+	filename := prog.Filename.Filename
+	included := false
+	for _, stmt := range prog.Statements {
+		if stmt.Pos().Filename != filename {
+			included = true
+			continue
+		}
+		switch stmt.(type) {
+		case *ast.BeginStatement:
+			if begin == nil {
+				begin = stmt
+			}
+		case *ast.EndStatement:
+			if end == nil {
+				end = stmt
+			}
+		}
+	}
+	if !included {
+		return nil, nil
+	}
+	return begin, end
+}
@@ -57,72 +57,29 @@ func (c *Ineffassign) Run(prog *ast.Program) ([]string, error) {
 	return res, nil
 }
 
+// extractIdentLabel returns the identifiers a statement uses and the labels it
+// declares. The identifier declared by a label isn't a use of the label.
 func extractIdentLabel(stmt ast.Statement) ([]*ast.Identifier, []*ast.LabelStatement) {
 	idents := []*ast.Identifier{}
 	labels := []*ast.LabelStatement{}
 
-	switch stmt.(type) {
-	case *ast.LabelStatement:
-		label := stmt.(*ast.LabelStatement)
-		labels = append(labels, label)
-		// Besides reading the label, we also need to examine the referenced
-		// statement.
-		if ref, valid := label.Reference.(ast.Statement); valid {
-			ids, lbs := extractIdentLabel(ref)
-			labels = append(labels, lbs...)
-			for _, ident := range ids {
-				if !has(idents, ident) {
-					idents = append(idents, ident)
-				}
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.LabelStatement:
+			// Besides declaring the label, we also need to examine the
+			// referenced statement.
+			labels = append(labels, node)
+			if node.Reference != nil {
+				ast.Inspect(node.Reference, visit)
 			}
+			return false
+		case *ast.Identifier:
+			idents = append(idents, node)
 		}
-	case *ast.LoadStatement:
-		if ident, valid := stmt.(*ast.LoadStatement).Source.(*ast.Expression).Base.(*ast.Identifier); valid {
-			if !has(idents, ident) {
-				idents = append(idents, ident)
-			}
-		}
-	case *ast.StoreStatement:
-		if ident, valid := stmt.(*ast.StoreStatement).Destination.(*ast.Expression).Base.(*ast.Identifier); valid {
-			if !has(idents, ident) {
-				idents = append(idents, ident)
-			}
-		}
-	case *ast.BEStatement:
-		ident := stmt.(*ast.BEStatement).Target
-		if !has(idents, ident) {
-			idents = append(idents, ident)
-		}
-	case *ast.BNEStatement:
-		ident := stmt.(*ast.BNEStatement).Target
-		if !has(idents, ident) {
-			idents = append(idents, ident)
-		}
-	case *ast.BNEGStatement:
-		ident := stmt.(*ast.BNEGStatement).Target
-		if !has(idents, ident) {
-			idents = append(idents, ident)
-		}
-	case *ast.BPOSStatement:
-		ident := stmt.(*ast.BPOSStatement).Target
-		if !has(idents, ident) {
-			idents = append(idents, ident)
-		}
-	case *ast.BAStatement:
-		ident := stmt.(*ast.BAStatement).Target
-		if !has(idents, ident) {
-			idents = append(idents, ident)
-		}
+		return true
 	}
+	ast.Inspect(stmt, visit)
 
 	return idents, labels
 }
-
-func has(idents []*ast.Identifier, ident *ast.Identifier) bool {
-	for _, val := range idents {
-		if ident == val {
-			return true
-		}
-	}
-	return false
-}
@@ -54,27 +54,14 @@ func (c *Ineffoffset) Run(prog *ast.Program) ([]string, error) {
 	return res, nil
 }
 
+// extractExpression returns the expressions used by a statement.
 func extractExpression(stmt ast.Statement) []*ast.Expression {
 	exps := []*ast.Expression{}
-
-	switch stmt.(type) {
-	case *ast.LabelStatement:
-		// We also need to examine the referenced statement.
-		label := stmt.(*ast.LabelStatement)
-		ref, valid := label.Reference.(ast.Statement)
-		if valid {
-			exp := extractExpression(ref)
-			exps = append(exps, exp...)
-		}
-	case *ast.LoadStatement:
-		if exp, valid := stmt.(*ast.LoadStatement).Source.(*ast.Expression); valid {
-			exps = append(exps, exp)
-		}
-	case *ast.StoreStatement:
-		if exp, valid := stmt.(*ast.StoreStatement).Destination.(*ast.Expression); valid {
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if exp, valid := n.(*ast.Expression); valid {
 			exps = append(exps, exp)
 		}
-	}
-
+		return true
+	})
 	return exps
 }
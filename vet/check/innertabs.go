@@ -0,0 +1,92 @@
+package check
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/scanner"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// Innertabs checks for tab characters after the first token of a line, like
+// between a mnemonic and its operands. Their width depends on the editor, which
+// breaks the alignment of the source in other tools. Tabs indenting a line are
+// left alone.
+//
+// As the AST doesn't preserve whitespace, the check scans the source code of
+// the program. A check scanning given source code is created by NewInnertabs.
+// The registered check reads the file the program was parsed from instead.
+// Programs which don't originate from a file aren't checked by it.
+type Innertabs struct {
+	name string
+	src  []byte
+}
+
+func init() {
+	Register(&Innertabs{name: "innertabs"})
+}
+
+// Desc returns a description of the Check.
+func (c Innertabs) Desc() string {
+	return "searches tab characters inside of statements"
+}
+
+// Name returns the name of the Check.
+func (c Innertabs) Name() string {
+	return c.name
+}
+
+// NewInnertabs returns a new innertabs check scanning the source code the
+// program is parsed from. If src is nil, the file of the program is read like
+// the registered check does.
+func NewInnertabs(src []byte) *Innertabs {
+	return &Innertabs{name: "innertabs", src: src}
+}
+
+// Configure returns a new innertabs check scanning the given source code. It
+// implements the Configurable interface.
+func (c *Innertabs) Configure(cfg Config, src []byte) (Check, error) {
+	return NewInnertabs(src), nil
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Innertabs) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	src := c.src
+	if src == nil {
+		if prog.Filename.Filename == "" {
+			return nil, nil
+		}
+		var err error
+		if src, err = ioutil.ReadFile(prog.Filename.Filename); err != nil {
+			return nil, err
+		}
+	}
+
+	// Whitespace is attached to the following token, so a tab is inside of a
+	// statement if a token preceded it on the same line.
+	s := scanner.NewNamed(bytes.NewReader(src), prog.Filename.Filename)
+	for started := false; ; started = true {
+		lex := s.ScanLexeme()
+		for _, tr := range lex.LeadingTrivia {
+			switch tr.Token {
+			case token.NL:
+				started = false
+			case token.WS:
+				if i := strings.IndexByte(tr.Literal, '\t'); i >= 0 && started {
+					pos := tr.Pos
+					pos.Char += i
+					res = append(res, buildMsg(c, pos, "tab inside of statement, use spaces instead"))
+				}
+			}
+		}
+		if lex.Token == token.EOF {
+			break
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,237 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// Loopcounter heuristically checks loops controlled by a counter which is
+// decremented right before a conditional branch, like
+//
+//	loop: ...
+//	      subcc %r1, 1, %r1
+//	      be done
+//	      ba loop
+//
+// It warns if the branch condition or the placement of the decrement makes the
+// loop run one iteration too many or too few, assuming the counter is
+// initialized with the number of iterations:
+//
+//   - exiting with bneg or looping with bpos only stops once the counter is
+//     negative, which runs one iteration too many
+//   - decrementing at the top of the loop and exiting with be runs one
+//     iteration too few
+//   - a counter statically known to start at zero never reaches zero again
+//
+// Being a heuristic, it might report loops which are correct on purpose.
+type Loopcounter struct {
+	name string
+}
+
+func init() {
+	Register(&Loopcounter{"loopcounter"})
+}
+
+// Desc returns a description of the Check.
+func (c Loopcounter) Desc() string {
+	return "searches loop counters which are off by one (heuristic)"
+}
+
+// Name returns the name of the Check.
+func (c Loopcounter) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Loopcounter) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	// Flatten the program into a list of statements without comments and
+	// directives and remember the index of every label.
+	var stmts []ast.Statement
+	labels := make(map[string]int)
+	data := make(map[string]int32)
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.CommentStatement, *ast.BeginStatement, *ast.EndStatement, *ast.OrgStatement, *ast.GlobalStatement, *ast.ExternStatement, *ast.EquStatement, *ast.AssertStatement:
+			continue
+		case *ast.LabelStatement:
+			labels[s.Ident.Name] = len(stmts)
+			if i, valid := s.Reference.(*ast.Integer); valid {
+				data[s.Ident.Name] = i.Value
+			}
+			if w, valid := s.Reference.(*ast.WordStatement); valid {
+				data[s.Ident.Name] = w.Values[0].Value
+			}
+			if ref, valid := s.Reference.(ast.Statement); valid {
+				stmt = ref
+			}
+		}
+		stmts = append(stmts, stmt)
+	}
+
+	for i := 0; i+1 < len(stmts); i++ {
+		reg, valid := decrement(stmts[i])
+		if !valid {
+			continue
+		}
+		cond, target, valid := branch(stmts[i+1])
+		if !valid {
+			continue
+		}
+		dst, known := labels[target.Name]
+		if !known {
+			continue
+		}
+		exits := dst > i+1
+		loops := dst <= i
+		start := loopStart(stmts, labels, i)
+
+		var msg string
+		switch {
+		case cond == token.BNEG && exits:
+			msg = fmt.Sprintf("loop exits once %s is negative, which runs one iteration too many", reg)
+		case cond == token.BPOS && loops:
+			msg = fmt.Sprintf("loop continues while %s is positive or zero, which runs one iteration too many", reg)
+		case cond == token.BE && exits && start == i:
+			msg = fmt.Sprintf("loop counter %s is decremented before the loop body, which runs one iteration too few", reg)
+		case (cond == token.BE && exits) || (cond == token.BNE && loops):
+			if v, known := initialValue(stmts, data, start, reg); known && v == 0 {
+				msg = fmt.Sprintf("loop counter %s starts at 0 and never reaches zero again", reg)
+			}
+		}
+		if msg != "" {
+			res = append(res, buildMsg(c, stmts[i].Pos(), msg))
+		}
+	}
+
+	return res, nil
+}
+
+// decrement returns the register a statement decrements by one while setting
+// the condition codes.
+func decrement(stmt ast.Statement) (*ast.Register, bool) {
+	var src, dst *ast.Register
+	var op ast.Operand
+	var step int32
+	switch s := stmt.(type) {
+	case *ast.SubCCStatement:
+		src, op, dst, step = s.Source, s.Operand, s.Destination, 1
+	case *ast.AddCCStatement:
+		src, op, dst, step = s.Source, s.Operand, s.Destination, -1
+	default:
+		return nil, false
+	}
+	i, valid := op.(*ast.Integer)
+	return dst, valid && i.Value == step && src.Name == dst.Name
+}
+
+// branch returns the condition and the target of a conditional branch.
+func branch(stmt ast.Statement) (token.Token, *ast.Identifier, bool) {
+	switch s := stmt.(type) {
+	case *ast.BEStatement:
+		return s.Token, s.Target, true
+	case *ast.BNEStatement:
+		return s.Token, s.Target, true
+	case *ast.BNEGStatement:
+		return s.Token, s.Target, true
+	case *ast.BPOSStatement:
+		return s.Token, s.Target, true
+	}
+	return token.ILLEGAL, nil, false
+}
+
+// loopStart returns the index of the first statement of the innermost loop
+// containing the statement at index i. A loop starts at a label which is the
+// target of a branch located after i. If there is no such loop, i is returned.
+func loopStart(stmts []ast.Statement, labels map[string]int, i int) int {
+	start := -1
+	for j := i + 1; j < len(stmts); j++ {
+		target, valid := branchTarget(stmts[j])
+		if !valid {
+			continue
+		}
+		if dst, known := labels[target.Name]; known && dst <= i && dst > start {
+			start = dst
+		}
+	}
+	if start < 0 {
+		return i
+	}
+	return start
+}
+
+// branchTarget returns the target of a conditional or unconditional branch,
+// labeled or not.
+func branchTarget(stmt ast.Statement) (*ast.Identifier, bool) {
+	if label, valid := stmt.(*ast.LabelStatement); valid {
+		if stmt, valid = label.Reference.(ast.Statement); !valid {
+			return nil, false
+		}
+	}
+	if s, valid := stmt.(*ast.BAStatement); valid {
+		return s.Target, true
+	}
+	_, target, valid := branch(stmt)
+	return target, valid
+}
+
+// initialValue returns the value of a register set by the last statement
+// before index start writing it, if it is statically known. Known are loads of
+// data labels and additions or disjunctions of %r0 and an integer, like mov.
+// The value written by any other statement is unknown.
+func initialValue(stmts []ast.Statement, data map[string]int32, start int, reg *ast.Register) (int32, bool) {
+	for j := start - 1; j >= 0; j-- {
+		stmt := ast.LowerStatement(stmts[j])
+		if dst, valid := destination(stmt); !valid || dst != reg.Name {
+			continue
+		}
+		switch s := stmt.(type) {
+		case *ast.LoadStatement:
+			if exp, valid := s.Source.(*ast.Expression); valid && exp.Operator == "" {
+				if ident, valid := exp.Base.(*ast.Identifier); valid {
+					v, known := data[ident.Name]
+					return v, known
+				}
+			}
+		case *ast.AddStatement:
+			return constant(s.Source, s.Operand)
+		case *ast.OrStatement:
+			return constant(s.Source, s.Operand)
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+// destination returns the name of the register an instruction writes. This is
+// its last operand if it is a register, except for stores which write memory.
+// A call writes the return address to %r15.
+func destination(stmt ast.Statement) (string, bool) {
+	switch stmt.(type) {
+	case *ast.StoreStatement:
+		return "", false
+	case *ast.CallStatement:
+		return "%r15", true
+	}
+	ops := ast.Operands(stmt)
+	if len(ops) == 0 {
+		return "", false
+	}
+	reg, valid := ops[len(ops)-1].(*ast.Register)
+	if !valid {
+		return "", false
+	}
+	return reg.Name, true
+}
+
+// constant returns the value of an operation combining %r0 with an integer.
+func constant(src *ast.Register, op ast.Operand) (int32, bool) {
+	i, valid := op.(*ast.Integer)
+	if !valid || src.Name != "%r0" {
+		return 0, false
+	}
+	return i.Value, true
+}
@@ -0,0 +1,57 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Memaddr checks if the addresses of memory expressions can be encoded. An
+// address given by a label ([x]) is encoded as 13 bit immediate relative to
+// %r0, so labels beyond 4095 can't be addressed directly.
+type Memaddr struct {
+	name string
+}
+
+func init() {
+	Register(&Memaddr{"memaddr"})
+}
+
+// Desc returns a description of the Check.
+func (c Memaddr) Desc() string {
+	return "checks for memory addresses which can't be encoded as 13 bit immediate"
+}
+
+// Name returns the name of the Check.
+func (c Memaddr) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Memaddr) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	// Collect the addresses of all labels.
+	layout, _ := ast.Layout(prog)
+
+	// See if the absolute addresses of expressions exceed the immediate.
+	// Register based expressions are resolved at runtime and undefined labels,
+	// like extern symbols, are resolved by the linker.
+	for _, stmt := range prog.Statements {
+		for _, exp := range extractExpression(stmt) {
+			if _, valid := exp.Base.(*ast.Identifier); !valid {
+				continue
+			}
+			addr, err := exp.Resolve(layout.Symbols)
+			if err != nil {
+				continue
+			}
+			if addr.Offset < -4096 || addr.Offset > 4095 {
+				msg := buildMsg(c, exp.Pos(), fmt.Sprintf("address 0x%08x of %q can't be encoded as 13 bit immediate, load it into a register first", uint32(addr.Offset), exp))
+				res = append(res, msg)
+			}
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,120 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Missingreturn checks for subroutines which never return to their caller. A
+// label called via call must eventually execute a jmpl, like ret or retl. The
+// instructions of a subroutine are followed from its label along the branches,
+// so a subroutine may fall through into the next label or branch to it, as long
+// as a jmpl is reached. Execution running into data or off the end of the code
+// doesn't return. Every subroutine is reported once, no matter how often it is
+// called.
+type Missingreturn struct {
+	name string
+}
+
+func init() {
+	Register(&Missingreturn{"missingreturn"})
+}
+
+// Desc returns a description of the Check.
+func (c Missingreturn) Desc() string {
+	return "searches subroutines which never return with jmpl"
+}
+
+// Name returns the name of the Check.
+func (c Missingreturn) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Missingreturn) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	// Flatten the program into a list of instructions and remember the index
+	// and position of every label. Data ends the code preceding it and is
+	// kept as nil.
+	var (
+		stmts []ast.Statement
+		calls []*ast.CallStatement
+	)
+	labels := make(map[string]int)
+	decls := make(map[string]*ast.LabelStatement)
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.CommentStatement, *ast.BeginStatement, *ast.EndStatement, *ast.OrgStatement, *ast.GlobalStatement, *ast.ExternStatement, *ast.EquStatement, *ast.AssertStatement:
+			continue
+		case *ast.WordStatement:
+			stmt = nil
+		case *ast.LabelStatement:
+			if _, isOrg := s.Reference.(*ast.OrgStatement); isOrg {
+				continue
+			}
+			labels[s.Ident.Name] = len(stmts)
+			decls[s.Ident.Name] = s
+			stmt = nil
+			if ref, valid := s.Reference.(ast.Statement); valid {
+				if _, isData := ref.(*ast.WordStatement); !isData {
+					stmt = ref
+				}
+			}
+		}
+		if call, valid := stmt.(*ast.CallStatement); valid {
+			calls = append(calls, call)
+		}
+		stmts = append(stmts, stmt)
+	}
+
+	reported := make(map[string]bool)
+	for _, call := range calls {
+		name := call.Target.Name
+		start, known := labels[name]
+		if !known || reported[name] || returns(stmts, labels, start) {
+			continue
+		}
+		reported[name] = true
+		msg := fmt.Sprintf("subroutine %q called at %s never returns with jmpl", name, call.Pos().NoFile())
+		res = append(res, buildMsg(c, decls[name].Pos(), msg))
+	}
+
+	return res, nil
+}
+
+// returns reports whether a jmpl is reachable from the statement at index
+// start. Branches continue at their target and, if conditional, at the next
+// statement. Calls continue at the next statement, the one the subroutine
+// returns to. Branches to unknown labels, like extern symbols, are assumed to
+// return.
+func returns(stmts []ast.Statement, labels map[string]int, start int) bool {
+	visited := make(map[int]bool)
+	queue := []int{start}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		if i >= len(stmts) || stmts[i] == nil || visited[i] {
+			continue
+		}
+		visited[i] = true
+
+		stmt := ast.LowerStatement(stmts[i])
+		if _, isJmpl := stmt.(*ast.JumpAndLinkStatement); isJmpl {
+			return true
+		}
+		if target, valid := branchTarget(stmt); valid {
+			dst, known := labels[target.Name]
+			if !known {
+				return true
+			}
+			queue = append(queue, dst)
+			if _, always := stmt.(*ast.BAStatement); always {
+				continue
+			}
+		}
+		queue = append(queue, i+1)
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package check
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// DefaultLabelPattern is the naming convention labels must follow by default:
+// lowercase letters, digits and underscores, starting with a letter.
+const DefaultLabelPattern = "^[a-z][a-z0-9_]*$"
+
+// registerName matches label names which read like a register, ignoring case.
+var registerName = regexp.MustCompile(`^(?i)(r[0-9]+|pc|psr)$`)
+
+// Naming checks that labels follow a naming convention, like the one of a
+// course. Labels must match a pattern, DefaultLabelPattern for the registered
+// check. A check with a different pattern is created by NewNaming. Labels
+// named like a register, like "r1" or "pc", are reported regardless of the
+// pattern, as they are easily confused with the register.
+type Naming struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+func init() {
+	Register(&Naming{name: "naming", pattern: regexp.MustCompile(DefaultLabelPattern)})
+}
+
+// Desc returns a description of the Check.
+func (c Naming) Desc() string {
+	return "searches labels violating the naming convention"
+}
+
+// Name returns the name of the Check.
+func (c Naming) Name() string {
+	return c.name
+}
+
+// NewNaming returns a new naming check requiring labels to match the given
+// regular expression, DefaultLabelPattern if it is empty. The registered check
+// isn't affected, so checks with different patterns can be used side by side.
+// An error is returned if the pattern isn't a valid regular expression.
+func NewNaming(expr string) (*Naming, error) {
+	if expr == "" {
+		expr = DefaultLabelPattern
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label pattern %q: %s", expr, err)
+	}
+	return &Naming{name: "naming", pattern: pattern}, nil
+}
+
+// Configure returns a new naming check requiring labels to match the pattern
+// of the config. It implements the Configurable interface.
+func (c *Naming) Configure(cfg Config, src []byte) (Check, error) {
+	configured, err := NewNaming(cfg.LabelPattern)
+	if err != nil {
+		return nil, err
+	}
+	return configured, nil
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Naming) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	for _, stmt := range prog.Statements {
+		label, valid := stmt.(*ast.LabelStatement)
+		if !valid {
+			continue
+		}
+		name := label.Ident.Name
+
+		var msg string
+		switch {
+		case registerName.MatchString(name):
+			msg = fmt.Sprintf("label %q shadows the register %q", name, "%"+strings.ToLower(name))
+		case !c.pattern.MatchString(name):
+			msg = fmt.Sprintf("label %q doesn't match the naming convention %q", name, c.pattern)
+		default:
+			continue
+		}
+		res = append(res, buildMsg(c, label.Ident.Pos(), msg))
+	}
+
+	return res, nil
+}
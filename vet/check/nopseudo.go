@@ -0,0 +1,76 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+	"github.com/lukasmalkmus/arc/token"
+)
+
+// Nopseudo checks for pseudo instructions forbidden by a policy, like the one
+// of a course requiring raw instructions only. Every use is reported along with
+// the real instruction it stands for. The registered check forbids nothing, a
+// check forbidding pseudo instructions is created by NewNopseudo.
+type Nopseudo struct {
+	name      string
+	forbidden map[token.Token]bool
+}
+
+func init() {
+	Register(&Nopseudo{name: "nopseudo"})
+}
+
+// Desc returns a description of the Check.
+func (c Nopseudo) Desc() string {
+	return "reports pseudo instructions forbidden by a policy"
+}
+
+// Name returns the name of the Check.
+func (c Nopseudo) Name() string {
+	return c.name
+}
+
+// NewNopseudo returns a new nopseudo check reporting the given pseudo
+// instructions, given by their mnemonic, like "mov". The registered check isn't
+// affected, so checks with different policies can be used side by side. An
+// error is returned if a mnemonic isn't the one of a pseudo instruction.
+func NewNopseudo(mnemonics ...string) (*Nopseudo, error) {
+	forbidden := make(map[token.Token]bool, len(mnemonics))
+	for _, m := range mnemonics {
+		tok := token.Lookup(m)
+		if !tok.IsPseudo() {
+			return nil, fmt.Errorf("%q is not a pseudo instruction", m)
+		}
+		forbidden[tok] = true
+	}
+	return &Nopseudo{name: "nopseudo", forbidden: forbidden}, nil
+}
+
+// Configure returns a new nopseudo check reporting the forbidden pseudo
+// instructions of the config. It implements the Configurable interface.
+func (c *Nopseudo) Configure(cfg Config, src []byte) (Check, error) {
+	configured, err := NewNopseudo(cfg.ForbiddenPseudo...)
+	if err != nil {
+		return nil, err
+	}
+	return configured, nil
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Nopseudo) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	ast.Walk(prog, func(n ast.Node) bool {
+		stmt, valid := n.(ast.Statement)
+		if !valid {
+			return false
+		}
+		if tok := stmt.Tok(); tok.IsPseudo() && c.forbidden[tok] {
+			msg := fmt.Sprintf("pseudo instruction %q is forbidden, use %q instead", stmt, ast.LowerStatement(stmt))
+			res = append(res, buildMsg(c, stmt.Pos(), msg))
+		}
+		return true
+	})
+
+	return res, nil
+}
@@ -0,0 +1,75 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// R0write checks for instructions writing their result to %r0. %r0 always
+// reads as zero, so the result is discarded and most likely the wrong
+// destination register was used. The instructions setting the condition codes
+// are left out, as writing them to %r0 is the way to only set the flags, like
+// cmp does.
+type R0write struct {
+	name string
+}
+
+func init() {
+	Register(&R0write{"r0write"})
+}
+
+// Desc returns a description of the Check.
+func (c R0write) Desc() string {
+	return "searches instructions discarding their result by writing to %r0"
+}
+
+// Name returns the name of the Check.
+func (c R0write) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *R0write) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	for _, stmt := range prog.Statements {
+		if label, valid := stmt.(*ast.LabelStatement); valid {
+			if stmt, valid = label.Reference.(ast.Statement); !valid {
+				continue
+			}
+		}
+
+		var dest *ast.Register
+		switch s := stmt.(type) {
+		case *ast.LoadStatement:
+			dest = s.Destination
+		case *ast.AddStatement:
+			dest = s.Destination
+		case *ast.SubStatement:
+			dest = s.Destination
+		case *ast.AndStatement:
+			dest = s.Destination
+		case *ast.OrStatement:
+			dest = s.Destination
+		case *ast.OrnStatement:
+			dest = s.Destination
+		case *ast.XorStatement:
+			dest = s.Destination
+		case *ast.SLLStatement:
+			dest = s.Destination
+		case *ast.SRAStatement:
+			dest = s.Destination
+		case *ast.MovStatement:
+			dest = s.Destination
+		case *ast.ClrStatement:
+			dest = s.Destination
+		}
+		if dest != nil && dest.Name == "%r0" {
+			msg := fmt.Sprintf("result of %q is discarded, %%r0 is always zero", stmt)
+			res = append(res, buildMsg(c, dest.Pos(), msg))
+		}
+	}
+
+	return res, nil
+}
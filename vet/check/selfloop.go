@@ -0,0 +1,94 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Selfloop heuristically checks for loops which can never be left, like
+//
+//	halt: ba halt
+//
+// These are a ba branching to its own label and a conditional branch back to a
+// label without an instruction setting the condition codes in between. Once
+// such a conditional branch is taken, the condition never changes and the
+// branch is taken forever. Loops containing another branch, a call or a jmpl
+// aren't reported, as these might leave the loop.
+type Selfloop struct {
+	name string
+}
+
+func init() {
+	Register(&Selfloop{"selfloop"})
+}
+
+// Desc returns a description of the Check.
+func (c Selfloop) Desc() string {
+	return "searches branches looping forever (heuristic)"
+}
+
+// Name returns the name of the Check.
+func (c Selfloop) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Selfloop) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+
+	// Flatten the program into a list of instructions and remember the index
+	// of every label.
+	var stmts []ast.Statement
+	labels := make(map[string]int)
+	for _, stmt := range prog.Statements {
+		switch s := stmt.(type) {
+		case *ast.CommentStatement, *ast.BeginStatement, *ast.EndStatement, *ast.OrgStatement, *ast.GlobalStatement, *ast.ExternStatement, *ast.WordStatement, *ast.EquStatement, *ast.AssertStatement:
+			continue
+		case *ast.LabelStatement:
+			switch s.Reference.(type) {
+			case *ast.Integer, *ast.WordStatement, *ast.OrgStatement:
+				continue
+			}
+			labels[s.Ident.Name] = len(stmts)
+			stmt = s.Reference.(ast.Statement)
+		}
+		stmts = append(stmts, stmt)
+	}
+
+	for i, stmt := range stmts {
+		if s, valid := stmt.(*ast.BAStatement); valid {
+			if dst, known := labels[s.Target.Name]; known && dst == i {
+				msg := fmt.Sprintf("%q branches to itself forever", stmt)
+				res = append(res, buildMsg(c, stmt.Pos(), msg))
+			}
+			continue
+		}
+		_, target, valid := branch(stmt)
+		if !valid {
+			continue
+		}
+		dst, known := labels[target.Name]
+		if !known || dst > i || !unchanging(stmts[dst:i]) {
+			continue
+		}
+		msg := fmt.Sprintf("%q loops forever once taken, no instruction in the loop sets the condition codes", stmt)
+		res = append(res, buildMsg(c, stmt.Pos(), msg))
+	}
+
+	return res, nil
+}
+
+// unchanging reports whether the instructions neither set the condition codes
+// nor transfer control.
+func unchanging(stmts []ast.Statement) bool {
+	for _, stmt := range stmts {
+		switch ast.LowerStatement(stmt).(type) {
+		case *ast.AddCCStatement, *ast.SubCCStatement, *ast.AndCCStatement, *ast.OrCCStatement, *ast.OrnCCStatement, *ast.XorCCStatement:
+			return false
+		case *ast.BEStatement, *ast.BNEStatement, *ast.BNEGStatement, *ast.BPOSStatement, *ast.BAStatement, *ast.CallStatement, *ast.JumpAndLinkStatement:
+			return false
+		}
+	}
+	return true
+}
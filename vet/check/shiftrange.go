@@ -0,0 +1,73 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Shiftrange checks for shift instructions shifting by an immediate outside of
+// 0 to 31. Only the lowest five bits of the shift count are used, so the
+// hardware shifts by a different amount than written. Constants used as shift
+// count are checked by their value.
+type Shiftrange struct {
+	name string
+}
+
+func init() {
+	Register(&Shiftrange{"shiftrange"})
+}
+
+// Desc returns a description of the Check.
+func (c Shiftrange) Desc() string {
+	return "searches shift counts outside of 0 to 31"
+}
+
+// Name returns the name of the Check.
+func (c Shiftrange) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Shiftrange) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+	consts := prog.Constants()
+
+	for _, stmt := range prog.Statements {
+		if label, valid := stmt.(*ast.LabelStatement); valid {
+			if stmt, valid = label.Reference.(ast.Statement); !valid {
+				continue
+			}
+		}
+
+		var op ast.Operand
+		switch s := stmt.(type) {
+		case *ast.SLLStatement:
+			op = s.Operand
+		case *ast.SRAStatement:
+			op = s.Operand
+		default:
+			continue
+		}
+
+		var count int32
+		switch o := op.(type) {
+		case *ast.Integer:
+			count = o.Value
+		case *ast.Identifier:
+			v, known := consts[o.Name]
+			if !known {
+				continue
+			}
+			count = v
+		default:
+			continue
+		}
+		if count < 0 || count > 31 {
+			msg := fmt.Sprintf("shift count %d of %q is outside of 0 to 31, it shifts by %d", count, stmt, count&31)
+			res = append(res, buildMsg(c, op.Pos(), msg))
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// SIMM13 bounds the immediate operands of arithmetic and logic instructions,
+// which are encoded as signed 13 bit integer.
+const (
+	simm13Min = -4096
+	simm13Max = 4095
+)
+
+// Simm13 checks for immediate operands of arithmetic and logic instructions
+// which don't fit into the signed 13 bit simm13 field and therefore can't be
+// assembled. Constants used as operand are checked by their value. Pseudo
+// instructions are checked as the instruction they stand for.
+type Simm13 struct {
+	name string
+}
+
+func init() {
+	Register(&Simm13{"simm13"})
+}
+
+// Desc returns a description of the Check.
+func (c Simm13) Desc() string {
+	return "searches immediate operands exceeding the 13 bit signed range"
+}
+
+// Name returns the name of the Check.
+func (c Simm13) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Simm13) Run(prog *ast.Program) ([]string, error) {
+	var res []string
+	consts := prog.Constants()
+
+	for _, stmt := range prog.Statements {
+		if label, valid := stmt.(*ast.LabelStatement); valid {
+			if stmt, valid = label.Reference.(ast.Statement); !valid {
+				continue
+			}
+		}
+		inst := ast.LowerStatement(stmt)
+		if f, valid := inst.(ast.InstructionFormat); !valid || f.InstructionFormat() != ast.Arithmetic {
+			continue
+		}
+		ops := ast.Operands(inst)
+		if len(ops) != 3 {
+			continue
+		}
+
+		var val int32
+		switch op := ops[1].(type) {
+		case *ast.Integer:
+			val = op.Value
+		case *ast.Identifier:
+			v, known := consts[op.Name]
+			if !known {
+				continue
+			}
+			val = v
+		default:
+			continue
+		}
+		if val < simm13Min || val > simm13Max {
+			msg := fmt.Sprintf("immediate %d of %q exceeds the simm13 range [%d, %d]", val, stmt, simm13Min, simm13Max)
+			res = append(res, buildMsg(c, ops[1].Pos(), msg))
+		}
+	}
+
+	return res, nil
+}
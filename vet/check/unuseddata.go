@@ -0,0 +1,67 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/lukasmalkmus/arc/ast"
+)
+
+// Unuseddata checks if there are any data labels which are never read. A data
+// label is a label referencing an integer instead of an instruction. It is read
+// if its identifier is the base of the memory location of a ld instruction.
+// Other uses, like storing to the label or branching to it, don't count as a
+// read. So data which is written but never loaded is reported as well.
+type Unuseddata struct {
+	name string
+}
+
+func init() {
+	Register(&Unuseddata{"unuseddata"})
+}
+
+// Desc returns a description of the Check.
+func (c Unuseddata) Desc() string {
+	return "searches data labels which are never read"
+}
+
+// Name returns the name of the Check.
+func (c Unuseddata) Name() string {
+	return c.name
+}
+
+// Run executes the Check. It implements the Check interface.
+func (c *Unuseddata) Run(prog *ast.Program) ([]string, error) {
+	var (
+		res  []string
+		data []*ast.LabelStatement
+	)
+	read := make(map[string]bool)
+
+	for _, stmt := range prog.Statements {
+		if label, valid := stmt.(*ast.LabelStatement); valid {
+			switch label.Reference.(type) {
+			case *ast.Integer, *ast.WordStatement:
+				data = append(data, label)
+				continue
+			}
+			stmt, _ = label.Reference.(ast.Statement)
+		}
+		if ld, valid := stmt.(*ast.LoadStatement); valid {
+			if exp, valid := ld.Source.(*ast.Expression); valid {
+				if ident, valid := exp.Base.(*ast.Identifier); valid {
+					read[ident.Name] = true
+				}
+			}
+		}
+	}
+
+	// See if data labels are defined but never read.
+	for _, label := range data {
+		if !read[label.Ident.Name] {
+			msg := buildMsg(c, label.Pos(), fmt.Sprintf("data label %q defined but never read", label.Ident))
+			res = append(res, msg)
+		}
+	}
+
+	return res, nil
+}
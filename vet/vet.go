@@ -9,8 +9,11 @@ themselves by calling vet.Register().
 package vet
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"sort"
 
 	"github.com/lukasmalkmus/arc/ast"
@@ -26,6 +29,12 @@ type Options struct {
 	Checks []string
 	// Sort enables sorting vet results.
 	Sort bool
+	// ForbiddenPseudo are the mnemonics of the pseudo instructions reported by
+	// the nopseudo check, like "mov".
+	ForbiddenPseudo []string
+	// LabelPattern is the regular expression labels must match to pass the
+	// naming check. If unset, check.DefaultLabelPattern is used.
+	LabelPattern string
 }
 
 // Vet examines ARC source code and reports suspicious language constructs. It
@@ -39,6 +48,12 @@ type Vet struct {
 // New returns a new ARC Vet. It takes the source code as io.Reader as first
 // parameter.
 func New(prog *ast.Program, options *Options) (*Vet, error) {
+	return newVet(prog, nil, options)
+}
+
+// newVet returns a new ARC Vet for a program parsed from the given source. The
+// source is nil if the program was parsed from a file.
+func newVet(prog *ast.Program, src []byte, options *Options) (*Vet, error) {
 	v := &Vet{
 		opts:   options,
 		prog:   prog,
@@ -64,6 +79,25 @@ func New(prog *ast.Program, options *Options) (*Vet, error) {
 		v.checks[name] = c
 	}
 
+	// Configure the checks taking options or the source code. Configured
+	// checks are created for every Vet, the registered ones are shared and
+	// therefore never altered.
+	cfg := check.Config{
+		ForbiddenPseudo: v.opts.ForbiddenPseudo,
+		LabelPattern:    v.opts.LabelPattern,
+	}
+	for name, c := range v.checks {
+		configurable, ok := c.(check.Configurable)
+		if !ok {
+			continue
+		}
+		configured, err := configurable.Configure(cfg, src)
+		if err != nil {
+			return nil, err
+		}
+		v.checks[name] = configured
+	}
+
 	return v, nil
 }
 
@@ -74,15 +108,21 @@ func New(prog *ast.Program, options *Options) (*Vet, error) {
 func Check(src io.Reader, options *Options) ([]string, error) {
 	errs := internal.MultiError{}
 
+	// Keep the source for checks examining it instead of the AST.
+	b, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse source. Abort if we don't have a program.
-	prog, err := parser.New(src).Parse()
+	prog, err := parser.New(bytes.NewReader(b)).Parse()
 	if prog == nil {
 		return nil, err
 	}
 	errs.Add(err)
 
 	// Create new vet instance.
-	v, err := New(prog, options)
+	v, err := newVet(prog, b, options)
 	if err != nil {
 		errs.Add(err)
 		return nil, errs
@@ -122,6 +162,71 @@ func CheckFile(filename string, options *Options) ([]string, error) {
 	return res, errs.Return()
 }
 
+// CheckProgram performs multiple checks on the given ARC AST. Results are
+// returned as a slice of strings. An error is returned if the New() function or
+// a check fails.
+func CheckProgram(prog *ast.Program, options *Options) ([]string, error) {
+	v, err := New(prog, options)
+	if err != nil {
+		return nil, err
+	}
+	return v.Check()
+}
+
+// Diagnose parses ARC source code and performs multiple checks on it. In
+// contrast to Check, parse errors are not returned as error but reported
+// together with the results of the checks. Results are sorted by their
+// position. An error is only returned if the New() function or a check fails.
+func Diagnose(src io.Reader, options *Options) ([]string, error) {
+	b, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := parser.New(bytes.NewReader(b)).Parse()
+	return diagnose(prog, b, err, options)
+}
+
+// DiagnoseFile is like Diagnose but takes a filename as parameter. An error is
+// also returned if the file can't be read.
+func DiagnoseFile(filename string, options *Options) ([]string, error) {
+	src, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	prog, err := parser.NewFileParser(src).Parse()
+	return diagnose(prog, nil, err, options)
+}
+
+// diagnose merges the parse errors with the results of the checks. The source
+// is nil if the program was parsed from a file.
+func diagnose(prog *ast.Program, src []byte, parseErr error, options *Options) ([]string, error) {
+	res := []string{}
+	if me, ok := parseErr.(internal.MultiError); ok {
+		for _, err := range me.Errors() {
+			res = append(res, fmt.Sprintf("%s (parse)", err))
+		}
+	} else if parseErr != nil {
+		res = append(res, fmt.Sprintf("%s (parse)", parseErr))
+	}
+
+	if prog != nil {
+		v, err := newVet(prog, src, options)
+		if err != nil {
+			return nil, err
+		}
+		r, err := v.Check()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r...)
+	}
+
+	internal.SortByPosition(res)
+	return res, nil
+}
+
 // Check performs multiple checks on the ARC AST. Results are returned as a
 // slice of strings. An error is returned if parsing of the source file or a
 // check fails.
@@ -0,0 +1,127 @@
+package vet
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lukasmalkmus/arc/internal"
+	"github.com/lukasmalkmus/arc/parser"
+)
+
+// TestDiagnose validates that parse errors and check results are reported
+// together, sorted by their position.
+func TestDiagnose(t *testing.T) {
+	src := ".begin\nld [%r1+0], %r2\nld %r1\nst %r2, [%r3+0]\n.end"
+	res, err := Diagnose(strings.NewReader(src), &Options{Checks: []string{"ineffoffset"}})
+	ok(t, err)
+	equals(t, []string{
+		`2:4: offset expression "[%r1+0]" can be shortened to "%r1" (ineffoffset)`,
+		`3:7: found NEWLINE, expected "," (parse)`,
+		`4:9: offset expression "[%r3+0]" can be shortened to "%r3" (ineffoffset)`,
+	}, res)
+}
+
+// TestDiagnose_Skeleton validates that the skeleton of a new program parses
+// and passes the checks.
+func TestDiagnose_Skeleton(t *testing.T) {
+	src := internal.Skeleton("hello.arc")
+	equals(t, true, strings.HasPrefix(string(src), "! hello.arc\n"))
+
+	res, err := Diagnose(bytes.NewReader(src), &Options{Checks: []string{"directives"}})
+	ok(t, err)
+	equals(t, []string{}, res)
+
+	res, err = Diagnose(bytes.NewReader(src), nil)
+	ok(t, err)
+	equals(t, []string{}, res)
+}
+
+// TestDiagnose_ForbiddenPseudo validates that the forbidden pseudo
+// instructions are passed to the nopseudo check.
+func TestDiagnose_ForbiddenPseudo(t *testing.T) {
+	src := "cmp %r1, 0\nmov %r1, %r2"
+	res, err := Diagnose(strings.NewReader(src), &Options{Checks: []string{"nopseudo"}, ForbiddenPseudo: []string{"cmp"}})
+	ok(t, err)
+	equals(t, []string{`1:1: pseudo instruction "cmp %r1, 0" is forbidden, use "subcc %r1, 0, %r0" instead (nopseudo)`}, res)
+
+	_, err = Diagnose(strings.NewReader(src), &Options{Checks: []string{"nopseudo"}, ForbiddenPseudo: []string{"jmpl"}})
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+
+	res, err = Diagnose(strings.NewReader(src), &Options{Checks: []string{"nopseudo"}})
+	ok(t, err)
+	equals(t, []string{}, res)
+}
+
+// TestNew_Independent validates that the options of one Vet don't affect the
+// checks of another one.
+func TestNew_Independent(t *testing.T) {
+	prog, err := parser.Parse("Loop: mov %r1, %r2")
+	ok(t, err)
+	a, err := New(prog, &Options{Checks: []string{"nopseudo", "naming"}, ForbiddenPseudo: []string{"mov"}, Sort: true})
+	ok(t, err)
+	_, err = New(prog, &Options{Checks: []string{"nopseudo", "naming"}, LabelPattern: "^[A-Z][a-z]*$"})
+	ok(t, err)
+
+	res, err := a.Check()
+	ok(t, err)
+	equals(t, []string{
+		`1:1: label "Loop" doesn't match the naming convention "^[a-z][a-z0-9_]*$" (naming)`,
+		`1:7: pseudo instruction "mov %r1, %r2" is forbidden, use "or %r0, %r1, %r2" instead (nopseudo)`,
+	}, res)
+}
+
+// TestDiagnose_LabelPattern validates that the label pattern is passed to the
+// naming check.
+func TestDiagnose_LabelPattern(t *testing.T) {
+	src := "Loop: nop"
+	res, err := Diagnose(strings.NewReader(src), &Options{Checks: []string{"naming"}})
+	ok(t, err)
+	equals(t, []string{`1:1: label "Loop" doesn't match the naming convention "^[a-z][a-z0-9_]*$" (naming)`}, res)
+
+	res, err = Diagnose(strings.NewReader(src), &Options{Checks: []string{"naming"}, LabelPattern: "^[A-Z][a-z]*$"})
+	ok(t, err)
+	equals(t, []string{}, res)
+
+	_, err = Diagnose(strings.NewReader(src), &Options{Checks: []string{"naming"}, LabelPattern: "("})
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+// TestDiagnose_Innertabs validates that the source is passed to the innertabs
+// check.
+func TestDiagnose_Innertabs(t *testing.T) {
+	src := "\tld\t[x], %r1\nx: 0"
+	res, err := Diagnose(strings.NewReader(src), &Options{Checks: []string{"innertabs"}})
+	ok(t, err)
+	equals(t, []string{`1:4: tab inside of statement, use spaces instead (innertabs)`}, res)
+
+	// The source of one Vet isn't scanned by another one.
+	prog, err := parser.Parse(src)
+	ok(t, err)
+	v, err := New(prog, &Options{Checks: []string{"innertabs"}})
+	ok(t, err)
+	res, err = v.Check()
+	ok(t, err)
+	equals(t, []string{}, res)
+}
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	tb.Helper()
+	if err != nil {
+		tb.Fatalf("\033[31m unexpected error: %s\033[39m\n\n", err.Error())
+	}
+}
+
+// equals fails the test if got is not equal to want.
+func equals(tb testing.TB, got, want interface{}) {
+	tb.Helper()
+	if !reflect.DeepEqual(got, want) {
+		tb.Fatalf("\033[31m\n\n\tgot: %#v\n\n\twant: %#v\033[39m\n\n", got, want)
+	}
+}